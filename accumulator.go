@@ -0,0 +1,150 @@
+// Streaming descriptive statistics
+
+package stat
+
+import (
+	"math"
+)
+
+// Accumulator summarizes a stream of observations in constant memory: their
+// count, total weight, mean, central moments up to the fourth, minimum and
+// maximum. The moments are updated by the pairwise formulas of Pébay
+// (2008), which reduce to Welford's for single observations and stay
+// accurate when the mean is large compared to the spread.
+//
+// Weights are frequency weights: AddWeighted(x, 2) is the same as adding x
+// twice. Accumulators filled separately, e.g. by different goroutines, can
+// be combined with Merge. The zero value is an empty accumulator; an
+// Accumulator must not be used from several goroutines at once.
+type Accumulator struct {
+	count      int64
+	w          float64 // total weight
+	mean       float64
+	m2, m3, m4 float64 // weighted sums of powers of deviations from the mean
+	min, max   float64
+}
+
+// Add adds the observation x with weight 1.
+func (a *Accumulator) Add(x float64) { a.AddWeighted(x, 1) }
+
+// AddWeighted adds the observation x with weight w; observations with a
+// weight of zero are ignored. It panics if w is negative or not finite.
+func (a *Accumulator) AddWeighted(x, w float64) {
+	if !(w >= 0) || math.IsInf(w, 0) {
+		panic(invalidParameter("Accumulator weight %v", w))
+	}
+	if w == 0 {
+		return
+	}
+	a.merge(1, w, x, 0, 0, 0, x, x)
+}
+
+// Merge adds the observations summarized by b to a.
+func (a *Accumulator) Merge(b *Accumulator) {
+	if b.w == 0 {
+		return
+	}
+	a.merge(b.count, b.w, b.mean, b.m2, b.m3, b.m4, b.min, b.max)
+}
+
+func (a *Accumulator) merge(count int64, wb, μb, m2b, m3b, m4b, min, max float64) {
+	if a.w == 0 {
+		*a = Accumulator{count, wb, μb, m2b, m3b, m4b, min, max}
+		return
+	}
+	wa := a.w
+	w := wa + wb
+	δ := μb - a.mean
+	δw := δ / w
+	m2 := a.m2 + m2b + δ*δw*wa*wb
+	m3 := a.m3 + m3b + δ*δw*δw*wa*wb*(wa-wb) + 3*δw*(wa*m2b-wb*a.m2)
+	m4 := a.m4 + m4b + δ*δw*δw*δw*wa*wb*(wa*wa-wa*wb+wb*wb) +
+		6*δw*δw*(wa*wa*m2b+wb*wb*a.m2) + 4*δw*(wa*m3b-wb*a.m3)
+	a.count += count
+	a.w = w
+	a.mean += δw * wb
+	a.m2, a.m3, a.m4 = m2, m3, m4
+	a.min = math.Min(a.min, min)
+	a.max = math.Max(a.max, max)
+}
+
+// Count returns the number of observations with a positive weight.
+func (a *Accumulator) Count() int64 { return a.count }
+
+// Weight returns the total weight of the observations.
+func (a *Accumulator) Weight() float64 { return a.w }
+
+// Mean returns the weighted mean, NaN if there are no observations.
+func (a *Accumulator) Mean() float64 {
+	if a.w == 0 {
+		return math.NaN()
+	}
+	return a.mean
+}
+
+// Variance returns the unbiased variance, dividing by the total weight
+// minus 1; it is NaN unless the total weight exceeds 1.
+func (a *Accumulator) Variance() float64 {
+	if !(a.w > 1) {
+		return math.NaN()
+	}
+	return a.m2 / (a.w - 1)
+}
+
+// Skewness returns the sample skewness g₁ = m₃ / m₂^(3/2), from the
+// moments about the mean divided by the total weight.
+func (a *Accumulator) Skewness() float64 {
+	return sqrt(a.w) * a.m3 / math.Pow(a.m2, 1.5)
+}
+
+// ExKurtosis returns the sample excess kurtosis g₂ = m₄ / m₂² - 3, from
+// the moments about the mean divided by the total weight.
+func (a *Accumulator) ExKurtosis() float64 {
+	return a.w*a.m4/(a.m2*a.m2) - 3
+}
+
+// Min returns the smallest observation, NaN if there are none.
+func (a *Accumulator) Min() float64 {
+	if a.w == 0 {
+		return math.NaN()
+	}
+	return a.min
+}
+
+// Max returns the largest observation, NaN if there are none.
+func (a *Accumulator) Max() float64 {
+	if a.w == 0 {
+		return math.NaN()
+	}
+	return a.max
+}
+
+// Summary is a snapshot of the descriptive statistics of a sample. N is the
+// total weight, which is the number of observations when they are
+// unweighted, and Variance is unbiased. The t-tests and z-tests accept a
+// Summary in place of the data, e.g. TTestSummary.
+type Summary struct {
+	N, Mean, Variance, Skewness, ExKurtosis, Min, Max float64
+}
+
+// Summary returns the current statistics of the accumulator.
+func (a *Accumulator) Summary() Summary {
+	return Summary{
+		N:          a.w,
+		Mean:       a.Mean(),
+		Variance:   a.Variance(),
+		Skewness:   a.Skewness(),
+		ExKurtosis: a.ExKurtosis(),
+		Min:        a.Min(),
+		Max:        a.Max(),
+	}
+}
+
+// Summarize returns the statistics of the observations in x.
+func Summarize(x []float64) Summary {
+	var a Accumulator
+	for _, xi := range x {
+		a.Add(xi)
+	}
+	return a.Summary()
+}
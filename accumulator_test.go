@@ -0,0 +1,133 @@
+package stat
+
+import (
+	"math"
+	"sync"
+	"testing"
+)
+
+func TestAccumulator(t *testing.T) {
+	x := []float64{2.5, -1, 4, 4, 0.5, 7.25, 3, -2.5, 1}
+	s := Summarize(x)
+	m, v := sampleMoments(x)
+	n := float64(len(x))
+	var m3, m4 float64
+	for _, xi := range x {
+		d := xi - m
+		m3 += d * d * d / n
+		m4 += d * d * d * d / n
+	}
+	check(t, "N", s.N, n, 0)
+	check(t, "Mean", s.Mean, m, 1e-15)
+	check(t, "Variance", s.Variance, v*n/(n-1), 1e-14)
+	check(t, "Skewness", s.Skewness, m3/math.Pow(v, 1.5), 1e-13)
+	check(t, "ExKurtosis", s.ExKurtosis, m4/(v*v)-3, 1e-13)
+	check(t, "Min", s.Min, -2.5, 0)
+	check(t, "Max", s.Max, 7.25, 0)
+
+	// a weight is a repeat count
+	var a, b Accumulator
+	for _, xi := range x {
+		a.Add(xi)
+	}
+	a.Add(4)
+	a.Add(4)
+	for _, xi := range x {
+		if xi == 4 {
+			continue
+		}
+		b.AddWeighted(xi, 1)
+	}
+	b.AddWeighted(4, 4)
+	b.AddWeighted(100, 0)
+	sa, sb := a.Summary(), b.Summary()
+	for _, c := range [][2]float64{
+		{sa.N, sb.N}, {sa.Mean, sb.Mean}, {sa.Variance, sb.Variance},
+		{sa.Skewness, sb.Skewness}, {sa.ExKurtosis, sb.ExKurtosis}, {sa.Max, sb.Max},
+	} {
+		check(t, "weighted", c[1], c[0], 1e-13)
+	}
+	if a.Count() != 11 || b.Count() != 8 {
+		t.Errorf("Count = %d and %d", a.Count(), b.Count())
+	}
+
+	// an empty accumulator has no statistics
+	var e Accumulator
+	if !math.IsNaN(e.Mean()) || !math.IsNaN(e.Min()) || !math.IsNaN(e.Variance()) {
+		t.Error("empty accumulator has statistics")
+	}
+	e.Merge(&a)
+	check(t, "merge into empty", e.Mean(), a.Mean(), 0)
+}
+
+func TestAccumulatorMerge(t *testing.T) {
+	// a large offset defeats the naive sums of powers
+	const n = 100000
+	x := make([]float64, n)
+	rng := NewRNG(8)
+	for i := range x {
+		x[i] = 1e9 + NextGammaWith(rng, 2, 1)
+	}
+	var whole Accumulator
+	for _, xi := range x {
+		whole.Add(xi)
+	}
+
+	// fill one accumulator per goroutine and merge them
+	parts := make([]Accumulator, 8)
+	var wg sync.WaitGroup
+	for p := range parts {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := p; i < n; i += len(parts) {
+				parts[p].Add(x[i])
+			}
+		}(p)
+	}
+	wg.Wait()
+	var merged Accumulator
+	for p := range parts {
+		merged.Merge(&parts[p])
+	}
+	// against two passes over the data, less the offset which is exact
+	y := make([]float64, n)
+	for i := range x {
+		y[i] = x[i] - 1e9
+	}
+	m, v := sampleMoments(y)
+	m += 1e9
+	sw, sm := whole.Summary(), merged.Summary()
+	for _, s := range []Summary{sw, sm} {
+		check(t, "Mean", s.Mean, m, 1e-15)
+		check(t, "Variance", s.Variance, v*n/(n-1), 1e-6)
+	}
+	check(t, "Skewness", sm.Skewness, sw.Skewness, 1e-5)
+	check(t, "ExKurtosis", sm.ExKurtosis, sw.ExKurtosis, 1e-5)
+	check(t, "Min", sm.Min, sw.Min, 0)
+
+	// Gamma(2, 1) has variance 2, skewness √2 and excess kurtosis 3
+	check(t, "Gamma variance", sw.Variance, 2, 0.05)
+	check(t, "Gamma skewness", sw.Skewness, math.Sqrt2, 0.1)
+	check(t, "Gamma kurtosis", sw.ExKurtosis, 3, 0.5)
+}
+
+func TestTTestSummary(t *testing.T) {
+	var a, b Accumulator
+	for i := range sleep1 {
+		a.Add(sleep1[i])
+		b.Add(sleep2[i])
+	}
+	r, err := WelchTTestSummary(a.Summary(), b.Summary(), 0, TwoSided, 0.95)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, _ := WelchTTest(sleep1, sleep2, 0, TwoSided, 0.95)
+	check(t, "Welch p", r.PValue, w.PValue, 1e-14)
+	r, _ = TTestSummary(a.Summary(), 0, Greater, 0.9)
+	w, _ = TTest(sleep1, 0, Greater, 0.9)
+	check(t, "t lower", r.Lower, w.Lower, 1e-14)
+	if _, err := TTestSummary(Summary{N: 1, Mean: 2, Variance: 1}, 0, TwoSided, 0.95); err == nil {
+		t.Error("one observation accepted")
+	}
+}
@@ -0,0 +1,237 @@
+// Analysis of variance
+
+package stat
+
+import (
+	"math"
+)
+
+// AnovaRow is one source of variation in an analysis of variance table:
+// its degrees of freedom, sum of squares, mean square, and F statistic
+// with its p-value. F and PValue are NaN for the residual and total rows.
+type AnovaRow struct {
+	Source                string
+	DF, SS, MS, F, PValue float64
+}
+
+// AnovaTable lists the sources of variation, ending with the residuals and
+// the total.
+type AnovaTable []AnovaRow
+
+// finish fills in the mean squares of the table and tests each effect
+// against the residual row, which is second to last.
+func (t AnovaTable) finish() AnovaTable {
+	res := &t[len(t)-2]
+	res.MS = res.SS / res.DF
+	for i := range t {
+		r := &t[i]
+		if i < len(t)-2 {
+			r.MS = r.SS / r.DF
+			r.F = r.MS / res.MS
+			r.PValue = FDist{r.DF, res.DF}.Survival(r.F)
+		} else {
+			r.F, r.PValue = math.NaN(), math.NaN()
+		}
+	}
+	t[len(t)-1].MS = math.NaN()
+	return t
+}
+
+// checkGroups verifies that there are at least two groups, each with at
+// least min observations.
+func checkGroups(name string, groups [][]float64, min int) error {
+	if len(groups) < 2 {
+		return invalidParameter("%s needs at least 2 groups, got %d", name, len(groups))
+	}
+	for _, g := range groups {
+		if err := checkSample(name, g, min); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// groupStats returns the means, the total count and the residual sum of
+// squares within the groups.
+func groupStats(groups [][]float64) (means []float64, n int, ssw float64) {
+	means = make([]float64, len(groups))
+	for i, g := range groups {
+		m, v := sampleMoments(g)
+		means[i] = m
+		ssw += v * float64(len(g))
+		n += len(g)
+	}
+	return
+}
+
+// OneWayAnova tests that the groups have equal means, assuming Normal data
+// with equal variances. The table has the rows "Groups", "Residuals" and
+// "Total".
+func OneWayAnova(groups [][]float64) (AnovaTable, error) {
+	if err := checkGroups("OneWayAnova", groups, 1); err != nil {
+		return nil, err
+	}
+	means, n, ssw := groupStats(groups)
+	if n <= len(groups) {
+		return nil, invalidParameter("OneWayAnova with %d observations in %d groups", n, len(groups))
+	}
+	var grand float64
+	for i, g := range groups {
+		grand += means[i] * float64(len(g))
+	}
+	grand /= float64(n)
+	var ssb float64
+	for i, g := range groups {
+		ssb += float64(len(g)) * (means[i] - grand) * (means[i] - grand)
+	}
+	k := float64(len(groups))
+	return AnovaTable{
+		{Source: "Groups", DF: k - 1, SS: ssb},
+		{Source: "Residuals", DF: float64(n) - k, SS: ssw},
+		{Source: "Total", DF: float64(n) - 1, SS: ssb + ssw},
+	}.finish(), nil
+}
+
+// TwoWayAnova is the two-way analysis of variance with interaction for a
+// balanced design: cells[i][j] holds the replicates at level i of factor A
+// and level j of factor B, and every cell must have the same number of
+// them, at least 2. The table has the rows "A", "B", "A:B", "Residuals"
+// and "Total".
+func TwoWayAnova(cells [][][]float64) (AnovaTable, error) {
+	a := len(cells)
+	if a < 2 || len(cells[0]) < 2 {
+		return nil, invalidParameter("TwoWayAnova needs at least 2 levels of each factor")
+	}
+	b := len(cells[0])
+	r := len(cells[0][0])
+	if r < 2 {
+		return nil, invalidParameter("TwoWayAnova needs at least 2 replicates per cell, got %d", r)
+	}
+	for i := range cells {
+		if len(cells[i]) != b {
+			return nil, dimensionMismatch("TwoWayAnova row %d has %d cells, want %d", i, len(cells[i]), b)
+		}
+		for j := range cells[i] {
+			if len(cells[i][j]) != r {
+				return nil, dimensionMismatch("TwoWayAnova cell (%d, %d) has %d replicates, want %d", i, j, len(cells[i][j]), r)
+			}
+			if err := checkSample("TwoWayAnova", cells[i][j], r); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	cell := make([][]float64, a)
+	rowMean := make([]float64, a)
+	colMean := make([]float64, b)
+	var grand, sse float64
+	for i := range cells {
+		cell[i] = make([]float64, b)
+		for j := range cells[i] {
+			m, v := sampleMoments(cells[i][j])
+			cell[i][j] = m
+			sse += v * float64(r)
+			rowMean[i] += m / float64(b)
+			colMean[j] += m / float64(a)
+			grand += m / float64(a*b)
+		}
+	}
+	var ssa, ssb, ssab float64
+	for i := range rowMean {
+		ssa += (rowMean[i] - grand) * (rowMean[i] - grand)
+	}
+	for j := range colMean {
+		ssb += (colMean[j] - grand) * (colMean[j] - grand)
+	}
+	for i := range cell {
+		for j := range cell[i] {
+			e := cell[i][j] - rowMean[i] - colMean[j] + grand
+			ssab += e * e
+		}
+	}
+	af, bf, rf := float64(a), float64(b), float64(r)
+	ssa *= bf * rf
+	ssb *= af * rf
+	ssab *= rf
+	return AnovaTable{
+		{Source: "A", DF: af - 1, SS: ssa},
+		{Source: "B", DF: bf - 1, SS: ssb},
+		{Source: "A:B", DF: (af - 1) * (bf - 1), SS: ssab},
+		{Source: "Residuals", DF: af * bf * (rf - 1), SS: sse},
+		{Source: "Total", DF: af*bf*rf - 1, SS: ssa + ssb + ssab + sse},
+	}.finish(), nil
+}
+
+// WelchAnova is Welch's (1951) test that the groups have equal means,
+// which does not assume equal variances. Each group needs at least two
+// observations and a positive variance.
+func WelchAnova(groups [][]float64) (FTestResult, error) {
+	if err := checkGroups("WelchAnova", groups, 2); err != nil {
+		return FTestResult{}, err
+	}
+	k := float64(len(groups))
+	means := make([]float64, len(groups))
+	w := make([]float64, len(groups))
+	var W, mw float64
+	for i, g := range groups {
+		m, v := meanVar(g)
+		if !(v > 0) {
+			return FTestResult{}, invalidParameter("WelchAnova group %d is constant", i)
+		}
+		means[i] = m
+		w[i] = float64(len(g)) / v
+		W += w[i]
+		mw += w[i] * m
+	}
+	mw /= W
+	var A, Λ float64
+	for i, g := range groups {
+		A += w[i] * (means[i] - mw) * (means[i] - mw)
+		u := 1 - w[i]/W
+		Λ += u * u / float64(len(g)-1)
+	}
+	A /= k - 1
+	f := A / (1 + 2*(k-2)*Λ/(k*k-1))
+	df2 := (k*k - 1) / (3 * Λ)
+	return FTestResult{Statistic: f, PValue: FDist{k - 1, df2}.Survival(f), DF1: k - 1, DF2: df2}, nil
+}
+
+// TukeyComparison compares groups I < J: Diff is mean J minus mean I, with
+// simultaneous confidence interval [Lower, Upper] and adjusted p-value.
+type TukeyComparison struct {
+	I, J                       int
+	Diff, Lower, Upper, PValue float64
+}
+
+// TukeyHSD makes all pairwise comparisons of the group means by Tukey's
+// honestly significant difference, with the Tukey-Kramer adjustment for
+// unequal group sizes, at confidence level conf. The p-values and
+// intervals come from the studentized range distribution with the residual
+// degrees of freedom of the one-way analysis of variance.
+func TukeyHSD(groups [][]float64, conf float64) ([]TukeyComparison, error) {
+	if !(conf > 0 && conf < 1) {
+		return nil, invalidParameter("TukeyHSD confidence level %v", conf)
+	}
+	t, err := OneWayAnova(groups)
+	if err != nil {
+		return nil, err
+	}
+	mse, df := t[1].MS, t[1].DF
+	means, _, _ := groupStats(groups)
+	d := StudentizedRangeDist{int64(len(groups)), df}
+	q := d.Quantile(conf)
+	var c []TukeyComparison
+	for i := range groups {
+		for j := i + 1; j < len(groups); j++ {
+			se := sqrt(mse / 2 * (1/float64(len(groups[i])) + 1/float64(len(groups[j]))))
+			diff := means[j] - means[i]
+			c = append(c, TukeyComparison{
+				I: i, J: j, Diff: diff,
+				Lower:  diff - q*se,
+				Upper:  diff + q*se,
+				PValue: d.Survival(abs(diff) / se),
+			})
+		}
+	}
+	return c, nil
+}
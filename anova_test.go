@@ -0,0 +1,158 @@
+package stat
+
+import (
+	"math"
+	"testing"
+)
+
+// R's PlantGrowth data
+var plantGrowth = [][]float64{
+	{4.17, 5.58, 5.18, 6.11, 4.50, 4.61, 5.17, 4.53, 5.33, 5.14},
+	{4.81, 4.17, 4.41, 3.59, 5.87, 3.83, 6.03, 4.89, 4.32, 4.69},
+	{6.31, 5.12, 5.54, 5.50, 5.37, 5.29, 4.92, 6.15, 5.80, 5.26},
+}
+
+func TestStudentizedRangeDist(t *testing.T) {
+	// for k = 2 the studentized range is √2 |T| with T ~ t(ν)
+	for _, ν := range []float64{1, 5, 40, 1e6, math.Inf(1)} {
+		d := StudentizedRangeDist{2, ν}
+		for _, q := range []float64{0.5, 2, 6} {
+			var want float64
+			if math.IsInf(ν, 1) {
+				want = 2 * Z_Survival_At(q/math.Sqrt2)
+			} else {
+				want = 2 * StudentsT_Survival_At(ν, q/math.Sqrt2)
+			}
+			check(t, "Survival", d.Survival(q), want, 1e-9)
+			check(t, "CDF", d.CDF(q), 1-want, 1e-9)
+		}
+	}
+	// upper 5% points, from published tables
+	check(t, "q(3, 10)", StudentizedRange_InvCDF_For(3, 10, 0.95), 3.877, 1e-3)
+	check(t, "q(5, 20)", StudentizedRange_InvCDF_For(5, 20, 0.95), 4.232, 1e-3)
+	check(t, "q(10, ∞)", StudentizedRange_InvCDF_For(10, math.Inf(1), 0.95), 4.474, 1e-3)
+
+	d := StudentizedRangeDist{4, 12}
+	for _, x := range []float64{1, 3.5} {
+		const h = 1e-4
+		check(t, "PDF", d.PDF(x), (d.CDF(x+h)-d.CDF(x-h))/(2*h), 1e-7)
+	}
+	check(t, "Survival", d.Survival(1e-3)+d.CDF(1e-3), 1, 1e-12)
+	// for large ν the distribution tends to that for ν = ∞
+	inf := StudentizedRangeDist{4, math.Inf(1)}
+	for _, ν := range []float64{1e6, 1e12, 1e300} {
+		d := StudentizedRangeDist{4, ν}
+		check(t, "CDF + Survival", d.CDF(3.5)+d.Survival(3.5), 1, 1e-10)
+		check(t, "CDF", d.CDF(3.5), inf.CDF(3.5), 1e-5)
+		check(t, "PDF", d.PDF(3.5), inf.PDF(3.5), 1e-5)
+	}
+	// the expected range of 2 standard Normal variables is 2/√π
+	check(t, "Mean", StudentizedRangeDist{2, math.Inf(1)}.Mean(), 2/math.Sqrt(π), 1e-9)
+	if _, err := NewStudentizedRangeDist(1, 10); err == nil {
+		t.Error("k = 1 accepted")
+	}
+}
+
+func TestOneWayAnova(t *testing.T) {
+	// anova(lm(weight ~ group, PlantGrowth)) in R
+	a, err := OneWayAnova(plantGrowth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, "df", a[0].DF, 2, 0)
+	check(t, "residual df", a[1].DF, 27, 0)
+	check(t, "SS", a[0].SS, 3.76634, 1e-5)
+	check(t, "residual SS", a[1].SS, 10.49209, 1e-5)
+	check(t, "F", a[0].F, 4.846088, 1e-6)
+	check(t, "p", a[0].PValue, 0.01590996, 1e-7)
+	check(t, "total", a[2].SS, a[0].SS+a[1].SS, 1e-14)
+
+	// with two groups F is the square of the pooled t statistic
+	a2, _ := OneWayAnova(plantGrowth[:2])
+	r, _ := TTest2(plantGrowth[0], plantGrowth[1], 0, TwoSided, 0.95)
+	check(t, "F = t²", a2[0].F, r.Statistic*r.Statistic, 1e-12)
+	check(t, "p", a2[0].PValue, r.PValue, 1e-10)
+
+	if _, err := OneWayAnova(plantGrowth[:1]); err == nil {
+		t.Error("one group accepted")
+	}
+}
+
+func TestTwoWayAnova(t *testing.T) {
+	cells := [][][]float64{
+		{{4.1, 5.2, 4.8}, {6.3, 5.9, 7.1}, {5.0, 4.4, 5.6}},
+		{{5.5, 6.1, 5.2}, {6.0, 6.8, 6.4}, {7.9, 7.2, 8.3}},
+	}
+	a, err := TwoWayAnova(cells)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the effects add up to the between-cell sum of squares of a one-way
+	// analysis, and in a balanced design A matches a one-way analysis of A
+	var flat, byA [][]float64
+	for i := range cells {
+		var row []float64
+		for j := range cells[i] {
+			flat = append(flat, cells[i][j])
+			row = append(row, cells[i][j]...)
+		}
+		byA = append(byA, row)
+	}
+	o, _ := OneWayAnova(flat)
+	oa, _ := OneWayAnova(byA)
+	check(t, "effects", a[0].SS+a[1].SS+a[2].SS, o[0].SS, 1e-12)
+	check(t, "residuals", a[3].SS, o[1].SS, 1e-12)
+	check(t, "A", a[0].SS, oa[0].SS, 1e-12)
+	check(t, "total", a[4].SS, o[2].SS, 1e-12)
+	for i, df := range []float64{1, 2, 2, 12, 17} {
+		check(t, a[i].Source+" df", a[i].DF, df, 0)
+	}
+	check(t, "F", a[2].F, a[2].SS/2/(a[3].SS/12), 1e-12)
+	check(t, "p", a[2].PValue, 1-F_CDF_At(2, 12, a[2].F), 1e-12)
+
+	cells[1][2] = cells[1][2][:2]
+	if _, err := TwoWayAnova(cells); err == nil {
+		t.Error("unbalanced design accepted")
+	}
+}
+
+func TestWelchAnova(t *testing.T) {
+	// oneway.test(weight ~ group, PlantGrowth) in R
+	r, err := WelchAnova(plantGrowth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, "F", r.Statistic, 5.181, 1e-3)
+	check(t, "DF1", r.DF1, 2, 0)
+	check(t, "DF2", r.DF2, 17.128, 1e-3)
+	check(t, "p", r.PValue, 0.01739, 1e-5)
+
+	// with two groups F is the square of Welch's t statistic
+	r2, _ := WelchAnova(plantGrowth[1:])
+	w, _ := WelchTTest(plantGrowth[1], plantGrowth[2], 0, TwoSided, 0.95)
+	check(t, "F = t²", r2.Statistic, w.Statistic*w.Statistic, 1e-12)
+	check(t, "DF2", r2.DF2, w.DF, 1e-12)
+}
+
+func TestTukeyHSD(t *testing.T) {
+	// TukeyHSD(aov(weight ~ group, PlantGrowth)) in R
+	c, err := TukeyHSD(plantGrowth, 0.95)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []TukeyComparison{
+		{0, 1, -0.371, -1.0622161, 0.3202161, 0.3908711},
+		{0, 2, 0.494, -0.1972161, 1.1852161, 0.1979960},
+		{1, 2, 0.865, 0.1737839, 1.5562161, 0.0120064},
+	}
+	for i, w := range want {
+		g := c[i]
+		if g.I != w.I || g.J != w.J {
+			t.Fatalf("comparison %d is %d-%d, want %d-%d", i, g.J, g.I, w.J, w.I)
+		}
+		check(t, "diff", g.Diff, w.Diff, 1e-12)
+		check(t, "lower", g.Lower, w.Lower, 1e-6)
+		check(t, "upper", g.Upper, w.Upper, 1e-6)
+		check(t, "p", g.PValue, w.PValue, 1e-6)
+	}
+}
@@ -0,0 +1,135 @@
+// Batch evaluation and sampling into caller-provided slices
+
+package stat
+
+import (
+	"math"
+)
+
+// Families whose log density has a normalizing constant worth computing once
+// per batch implement these.
+type logPDFBatcher interface {
+	logPDFs(dst, x []float64)
+}
+
+type logPMFBatcher interface {
+	logPMFs(dst []float64, k []int64)
+}
+
+var (
+	_ logPDFBatcher = NormalDist{}
+	_ logPDFBatcher = GammaDist{}
+	_ logPDFBatcher = InvGammaDist{}
+	_ logPDFBatcher = BetaDist{}
+	_ logPDFBatcher = XsquareDist{}
+	_ logPDFBatcher = FDist{}
+	_ logPDFBatcher = StudentsTDist{}
+
+	_ logPMFBatcher = PoissonDist{}
+	_ logPMFBatcher = BinomialDist{}
+	_ logPMFBatcher = NegativeBinomialDist{}
+)
+
+func checkBatch(name string, ndst, nx int) {
+	if ndst != nx {
+		panic(dimensionMismatch("%s: len(dst) = %d, len(x) = %d", name, ndst, nx))
+	}
+}
+
+// PDFs sets dst[i] = d.PDF(x[i]). It panics if dst and x differ in length.
+func PDFs(d ContinuousDistribution, dst, x []float64) {
+	checkBatch("PDFs", len(dst), len(x))
+	if b, ok := d.(logPDFBatcher); ok {
+		b.logPDFs(dst, x)
+		for i, l := range dst {
+			dst[i] = math.Exp(l)
+		}
+		return
+	}
+	for i, xi := range x {
+		dst[i] = d.PDF(xi)
+	}
+}
+
+// LogPDFs sets dst[i] = d.LogPDF(x[i]). It panics if dst and x differ in length.
+func LogPDFs(d ContinuousDistribution, dst, x []float64) {
+	checkBatch("LogPDFs", len(dst), len(x))
+	if b, ok := d.(logPDFBatcher); ok {
+		b.logPDFs(dst, x)
+		return
+	}
+	for i, xi := range x {
+		dst[i] = d.LogPDF(xi)
+	}
+}
+
+// CDFs sets dst[i] = d.CDF(x[i]). It panics if dst and x differ in length.
+func CDFs(d ContinuousDistribution, dst, x []float64) {
+	checkBatch("CDFs", len(dst), len(x))
+	for i, xi := range x {
+		dst[i] = d.CDF(xi)
+	}
+}
+
+// Quantiles sets dst[i] = d.Quantile(p[i]). It panics if dst and p differ in length.
+func Quantiles(d ContinuousDistribution, dst, p []float64) {
+	checkBatch("Quantiles", len(dst), len(p))
+	for i, pi := range p {
+		dst[i] = d.Quantile(pi)
+	}
+}
+
+// Sample fills dst with independent draws from d.
+func Sample(d ContinuousDistribution, dst []float64) { SampleWith(d, DefaultRNG, dst) }
+
+// SampleWith fills dst with independent draws from d using rng.
+func SampleWith(d ContinuousDistribution, rng RNG, dst []float64) {
+	for i := range dst {
+		dst[i] = d.RandWith(rng)
+	}
+}
+
+// PMFs sets dst[i] = d.PMF(k[i]). It panics if dst and k differ in length.
+func PMFs(d DiscreteDistribution, dst []float64, k []int64) {
+	checkBatch("PMFs", len(dst), len(k))
+	if b, ok := d.(logPMFBatcher); ok {
+		b.logPMFs(dst, k)
+		for i, l := range dst {
+			dst[i] = math.Exp(l)
+		}
+		return
+	}
+	for i, ki := range k {
+		dst[i] = d.PMF(ki)
+	}
+}
+
+// LogPMFs sets dst[i] = d.LogPMF(k[i]). It panics if dst and k differ in length.
+func LogPMFs(d DiscreteDistribution, dst []float64, k []int64) {
+	checkBatch("LogPMFs", len(dst), len(k))
+	if b, ok := d.(logPMFBatcher); ok {
+		b.logPMFs(dst, k)
+		return
+	}
+	for i, ki := range k {
+		dst[i] = d.LogPMF(ki)
+	}
+}
+
+// DiscreteCDFs sets dst[i] = d.CDF(k[i]). It panics if dst and k differ in length.
+func DiscreteCDFs(d DiscreteDistribution, dst []float64, k []int64) {
+	checkBatch("DiscreteCDFs", len(dst), len(k))
+	for i, ki := range k {
+		dst[i] = d.CDF(ki)
+	}
+}
+
+// SampleDiscrete fills dst with independent draws from d.
+func SampleDiscrete(d DiscreteDistribution, dst []int64) { SampleDiscreteWith(d, DefaultRNG, dst) }
+
+// SampleDiscreteWith fills dst with independent draws from d using rng.
+func SampleDiscreteWith(d DiscreteDistribution, rng RNG, dst []int64) {
+	for i := range dst {
+		dst[i] = d.RandWith(rng)
+	}
+}
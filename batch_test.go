@@ -0,0 +1,160 @@
+package stat
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBatchMatchesScalar(t *testing.T) {
+	x := []float64{-2, -0.5, 0, 0.1, 0.5, 0.9, 1, 2.5, 7}
+	dst := make([]float64, len(x))
+	for _, d := range continuousDists {
+		LogPDFs(d, dst, x)
+		for i, xi := range x {
+			if want := d.LogPDF(xi); dst[i] != want && math.Abs(dst[i]-want) > 1e-13*math.Abs(want) {
+				t.Errorf("%#v: LogPDFs at %v = %v, expected %v", d, xi, dst[i], want)
+			}
+		}
+		PDFs(d, dst, x)
+		for i, xi := range x {
+			if want := d.PDF(xi); math.Abs(dst[i]-want) > 1e-13*want {
+				t.Errorf("%#v: PDFs at %v = %v, expected %v", d, xi, dst[i], want)
+			}
+		}
+		CDFs(d, dst, x)
+		for i, xi := range x {
+			if dst[i] != d.CDF(xi) {
+				t.Errorf("%#v: CDFs at %v = %v, expected %v", d, xi, dst[i], d.CDF(xi))
+			}
+		}
+	}
+	k := []int64{-1, 0, 1, 2, 3, 5, 8, 20, 21}
+	for _, d := range discreteDists {
+		LogPMFs(d, dst, k)
+		for i, ki := range k {
+			if want := d.LogPMF(ki); dst[i] != want && math.Abs(dst[i]-want) > 1e-13*math.Abs(want) {
+				t.Errorf("%#v: LogPMFs at %d = %v, expected %v", d, ki, dst[i], want)
+			}
+		}
+		PMFs(d, dst, k)
+		for i, ki := range k {
+			if want := d.PMF(ki); math.Abs(dst[i]-want) > 1e-13*want {
+				t.Errorf("%#v: PMFs at %d = %v, expected %v", d, ki, dst[i], want)
+			}
+		}
+	}
+
+	rng1, rng2 := NewRNG(1), NewRNG(1)
+	SampleWith(GammaDist{2, 3}, rng1, dst)
+	for i := range dst {
+		if r := (GammaDist{2, 3}).RandWith(rng2); dst[i] != r {
+			t.Errorf("SampleWith draw %d = %v, expected %v", i, dst[i], r)
+		}
+	}
+}
+
+func TestBatchLengthMismatch(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("PDFs with mismatched lengths did not panic")
+		}
+	}()
+	PDFs(NormalDist{0, 1}, make([]float64, 2), make([]float64, 3))
+}
+
+const benchN = 1024
+
+func benchInputs() []float64 {
+	x := make([]float64, benchN)
+	for i := range x {
+		x[i] = 0.01 + 10*float64(i)/benchN
+	}
+	return x
+}
+
+func BenchmarkNormalPDFClosure(b *testing.B) {
+	x, dst := benchInputs(), make([]float64, benchN)
+	for n := 0; n < b.N; n++ {
+		for i, xi := range x {
+			dst[i] = Normal_PDF(1, 2)(xi)
+		}
+	}
+}
+
+func BenchmarkNormalPDFBatch(b *testing.B) {
+	x, dst := benchInputs(), make([]float64, benchN)
+	for n := 0; n < b.N; n++ {
+		PDFs(NormalDist{1, 2}, dst, x)
+	}
+}
+
+func BenchmarkGammaLnPDFClosure(b *testing.B) {
+	x, dst := benchInputs(), make([]float64, benchN)
+	for n := 0; n < b.N; n++ {
+		for i, xi := range x {
+			dst[i] = Gamma_LnPDF(2.5, 0.5)(xi)
+		}
+	}
+}
+
+func BenchmarkGammaLogPDFBatch(b *testing.B) {
+	x, dst := benchInputs(), make([]float64, benchN)
+	for n := 0; n < b.N; n++ {
+		LogPDFs(GammaDist{2.5, 2}, dst, x)
+	}
+}
+
+func BenchmarkStudentsTPDFClosure(b *testing.B) {
+	x, dst := benchInputs(), make([]float64, benchN)
+	for n := 0; n < b.N; n++ {
+		for i, xi := range x {
+			dst[i] = StudentsT_PDF(4.5)(xi)
+		}
+	}
+}
+
+func BenchmarkStudentsTPDFBatch(b *testing.B) {
+	x, dst := benchInputs(), make([]float64, benchN)
+	for n := 0; n < b.N; n++ {
+		PDFs(StudentsTDist{4.5}, dst, x)
+	}
+}
+
+func BenchmarkPoissonPMFClosure(b *testing.B) {
+	k, dst := make([]int64, benchN), make([]float64, benchN)
+	for i := range k {
+		k[i] = int64(i % 40)
+	}
+	for n := 0; n < b.N; n++ {
+		for i, ki := range k {
+			dst[i] = Poisson_PMF(12.5)(ki)
+		}
+	}
+}
+
+func BenchmarkPoissonPMFBatch(b *testing.B) {
+	k, dst := make([]int64, benchN), make([]float64, benchN)
+	for i := range k {
+		k[i] = int64(i % 40)
+	}
+	for n := 0; n < b.N; n++ {
+		PMFs(PoissonDist{12.5}, dst, k)
+	}
+}
+
+func BenchmarkNormalSampleClosure(b *testing.B) {
+	dst := make([]float64, benchN)
+	for n := 0; n < b.N; n++ {
+		for i := range dst {
+			dst[i] = Normal(1, 2)()
+		}
+	}
+}
+
+func BenchmarkNormalSampleBatch(b *testing.B) {
+	dst := make([]float64, benchN)
+	rng := NewRNG(1)
+	for n := 0; n < b.N; n++ {
+		SampleWith(NormalDist{1, 2}, rng, dst)
+	}
+}
@@ -10,42 +10,32 @@ import (
 	s "github.com/ematvey/gostat"
 )
 
-// Quantile, Flat prior
-func BinomFlatPriQtl(k, n int64, p float64) float64 {
-	var α, β float64
-	if k > n {
-		panic(fmt.Sprintf("The number of observed successes (k) must be <= number of trials (n)"))
+// Beta posterior of the binomial proportion after k successes in n trials,
+// under a Beta(α, β) prior. Flat prior is α = β = 1, Jeffrey's is α = β = 0.5.
+func BinomPosterior(k, n int64, α, β float64) (s.BetaDist, error) {
+	if k < 0 || k > n {
+		return s.BetaDist{}, fmt.Errorf("%w: the number of observed successes (k = %d) must be <= number of trials (n = %d)", s.ErrInvalidParameter, k, n)
 	}
+	return s.NewBetaDist(α+float64(k), β+float64(n-k))
+}
 
-	α = float64(k + 1)
-	β = float64(n - k + 1)
-
-	if α <= 0 || β <= 0 {
-		panic(fmt.Sprintf("The parameters of the prior must be greater than zero"))
-	}
-	return s.BetaInv_CDF_For(α, β, p)
+// Quantile, Flat prior
+func BinomFlatPriQtl(k, n int64, p float64) float64 {
+	return BinomBetaPriQtl(k, n, 1, 1, p)
 }
 
 // Quantile, Beta prior
 func BinomBetaPriQtl(k, n int64, α, β, p float64) float64 {
-	if k > n {
-		panic(fmt.Sprintf("The number of observed successes (k) must be <= number of trials (n)"))
+	post, err := BinomPosterior(k, n, α, β)
+	if err != nil {
+		panic(err)
 	}
-	if α <= 0 || β <= 0 {
-		panic(fmt.Sprintf("The parameters of the prior must be greater than zero"))
-	}
-	return s.BetaInv_CDF_For(α+float64(k), β+float64(n-k), p)
+	return s.BetaInv_CDF_For(post.Alpha, post.Beta, p)
 }
 
 // Quantile, Jeffrey's prior
 func BinomJeffPriQtl(k, n int64, p float64) float64 {
-	var α, β float64
-	α = 0.5
-	β = 0.5
-	if k > n {
-		panic(fmt.Sprintf("The number of observed successes (k) must be <= number of trials (n)"))
-	}
-	return s.BetaInv_CDF_For(α+float64(k), β+float64(n-k), p)
+	return BinomBetaPriQtl(k, n, 0.5, 0.5, p)
 }
 
 // Equivalent sample size of the prior
@@ -71,7 +61,7 @@ func BinomPostMean(α, β float64, n, k int64) float64 {
 
 //  Posterior median
 func BinomPostMedian(α, β float64, n, k int64) float64 {
-	return 0 // to be implemented
+	return s.BetaDist{Alpha: α + float64(k), Beta: β + float64(n-k)}.Quantile(0.5)
 }
 
 // Posterior variance
@@ -1,14 +1,99 @@
 package stat
 
+// Bernoulli distribution with success probability Rho
+type BernoulliDist struct {
+	Rho float64
+}
+
+// NewBernoulliDist returns a Bernoulli distribution, checking that 0 <= ρ <= 1.
+func NewBernoulliDist(ρ float64) (BernoulliDist, error) {
+	if !isProbability(ρ) {
+		return BernoulliDist{}, invalidParameter("Bernoulli ρ = %v", ρ)
+	}
+	return BernoulliDist{ρ}, nil
+}
+
+func (d BernoulliDist) PMF(k int64) float64 {
+	switch k {
+	case 0:
+		return 1 - d.Rho
+	case 1:
+		return d.Rho
+	}
+	return 0
+}
+
+func (d BernoulliDist) LogPMF(k int64) float64 {
+	switch k {
+	case 0:
+		return log(1 - d.Rho)
+	case 1:
+		return log(d.Rho)
+	}
+	return negInf
+}
+
+func (d BernoulliDist) CDF(k int64) float64 {
+	switch {
+	case k < 0:
+		return 0
+	case k == 0:
+		return 1 - d.Rho
+	}
+	return 1
+}
+
+func (d BernoulliDist) Survival(k int64) float64 {
+	switch {
+	case k < 0:
+		return 1
+	case k == 0:
+		return d.Rho
+	}
+	return 0
+}
+
+func (d BernoulliDist) LogCDF(k int64) float64      { return log(d.CDF(k)) }
+func (d BernoulliDist) LogSurvival(k int64) float64 { return log(d.Survival(k)) }
+
+func (d BernoulliDist) Quantile(p float64) int64 {
+	if p <= 1-d.Rho {
+		return 0
+	}
+	return 1
+}
+
+func (d BernoulliDist) Rand() int64            { return d.RandWith(DefaultRNG) }
+func (d BernoulliDist) RandWith(rng RNG) int64 { return NextBernoulliWith(rng, d.Rho) }
+func (d BernoulliDist) Mean() float64          { return d.Rho }
+func (d BernoulliDist) Variance() float64      { return d.Rho * (1 - d.Rho) }
+
+func (d BernoulliDist) Mode() int64 {
+	if d.Rho <= 0.5 {
+		return 0
+	}
+	return 1
+}
+
+func (d BernoulliDist) Skewness() float64 {
+	return (1 - 2*d.Rho) / sqrt(d.Rho*(1-d.Rho))
+}
+
+func (d BernoulliDist) ExKurtosis() float64 {
+	v := d.Rho * (1 - d.Rho)
+	return (1 - 6*v) / v
+}
+
+func (d BernoulliDist) Entropy() float64 {
+	return -xlogy(d.Rho, d.Rho) - xlogy(1-d.Rho, 1-d.Rho)
+}
+
 func Bernoulli_PMF(ρ float64) func(k int64) float64 {
 	return func(k int64) float64 {
 		if k < 0 || k > 1 {
 			panic("k is not 0 or 1")
 		}
-		if k == 1 {
-			return ρ
-		}
-		return 1 - ρ
+		return BernoulliDist{ρ}.PMF(k)
 	}
 }
 
@@ -18,31 +103,32 @@ func Bernoulli_PMF_At(ρ float64, k int64) float64 {
 }
 
 func Bernoulli_LnPMF(ρ float64) func(k int64) float64 {
-	return func(k int64) float64 {
-		if k == 1 {
-			return log(ρ)
-		}
-		return log(1 - ρ)
-	}
+	return BernoulliDist{ρ}.LogPMF
 }
 
-func NextBernoulli(ρ float64) int64 {
-	if NextUniform() < ρ {
+func NextBernoulli(ρ float64) int64 { return NextBernoulliWith(DefaultRNG, ρ) }
+func NextBernoulliWith(rng RNG, ρ float64) int64 {
+	if rng.Float64() < ρ {
 		return 1
 	}
 	return 0
 }
 
-func Bernoulli(ρ float64) func() int64 { return func() int64 { return NextBernoulli(ρ) } }
+func Bernoulli(ρ float64) func() int64 { return BernoulliDist{ρ}.Rand }
 
 func Bernoulli_CDF(ρ float64) func(k int64) float64 {
 	return func(k int64) float64 {
 		if k < 0 || k > 1 {
 			panic("k is not 0 or 1")
 		}
-		if k == 1 {
-			return 1
-		}
-		return 1 - ρ
+		return BernoulliDist{ρ}.CDF(k)
 	}
 }
+
+func Bernoulli_InvCDF(ρ float64) func(p float64) int64 {
+	return BernoulliDist{ρ}.Quantile
+}
+
+func Bernoulli_InvCDF_For(ρ, p float64) int64 {
+	return BernoulliDist{ρ}.Quantile(p)
+}
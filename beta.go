@@ -35,9 +35,9 @@ func betaContinuedFraction(α, β, x float64) float64 {
 	var aa, del, res, qab, qap, qam, c, d, m2, m, acc float64
 	var i int64
 	const eps = 2.2204460492503131e-16
-	const maxIter = 1000000000
+	const maxIter = 10000000
 
-	acc = 1e-16
+	acc = 4.5e-16
 	qab = α + β
 	qap = α + 1.0
 	qam = α - 1.0
@@ -81,38 +81,119 @@ func betaContinuedFraction(α, β, x float64) float64 {
 		}
 	}
 
-	panic(fmt.Sprintf("betaContinuedFraction(): α or β too big, or maxIter too small"))
-	return -1.00
+	// α or β too big
+	return math.NaN()
 }
 
-func Beta_PDF(α float64, β float64) func(x float64) float64 {
-	dα := []float64{α, β}
-	dirPDF := Dirichlet_PDF(dα)
-	return func(x float64) float64 {
-		if 0 > x || x > 1 {
-			return 0
-		}
-		dx := []float64{x, 1 - x}
-		return dirPDF(dx)
+// Beta distribution with shape parameters Alpha and Beta
+type BetaDist struct {
+	Alpha, Beta float64
+}
+
+// NewBetaDist returns a Beta distribution, checking that α > 0 and β > 0.
+func NewBetaDist(α, β float64) (BetaDist, error) {
+	if !(α > 0) || !(β > 0) || math.IsInf(α, 0) || math.IsInf(β, 0) {
+		return BetaDist{}, invalidParameter("Beta α = %v, β = %v", α, β)
 	}
+	return BetaDist{α, β}, nil
 }
-func Beta_LnPDF(α float64, β float64) func(x float64) float64 {
-	dα := []float64{α, β}
-	dirLnPDF := Dirichlet_LnPDF(dα)
-	return func(x float64) float64 {
-		if 0 > x || x > 1 {
-			return negInf
+
+func (d BetaDist) PDF(x float64) float64 {
+	if 0 > x || x > 1 {
+		return 0
+	}
+	return exp(d.LogPDF(x))
+}
+
+func (d BetaDist) LogPDF(x float64) float64 {
+	if 0 > x || x > 1 {
+		return negInf
+	}
+	return xlogy(d.Alpha-1, x) + xlogy(d.Beta-1, 1-x) - LnB(d.Alpha, d.Beta)
+}
+
+func (d BetaDist) logPDFs(dst, x []float64) {
+	lnNorm := -LnB(d.Alpha, d.Beta)
+	for i, xi := range x {
+		if 0 > xi || xi > 1 {
+			dst[i] = negInf
+			continue
 		}
-		dx := []float64{x, 1 - x}
-		return dirLnPDF(dx)
+		dst[i] = xlogy(d.Alpha-1, xi) + xlogy(d.Beta-1, 1-xi) + lnNorm
 	}
 }
-func NextBeta(α float64, β float64) float64 {
+
+func (d BetaDist) CDF(x float64) float64      { return exp(d.LogCDF(x)) }
+func (d BetaDist) Survival(x float64) float64 { return exp(d.LogSurvival(x)) }
+
+func (d BetaDist) LogCDF(x float64) float64 {
+	lnI, _ := lnBetaIncReg(d.Alpha, d.Beta, x, 1-x)
+	return lnI
+}
+
+func (d BetaDist) LogSurvival(x float64) float64 {
+	_, lnIc := lnBetaIncReg(d.Alpha, d.Beta, x, 1-x)
+	return lnIc
+}
+
+func (d BetaDist) Quantile(p float64) float64 {
+	return quantile(d, p, d.Mean(), true)
+}
+
+func (d BetaDist) Rand() float64            { return d.RandWith(DefaultRNG) }
+func (d BetaDist) RandWith(rng RNG) float64 { return NextBetaWith(rng, d.Alpha, d.Beta) }
+func (d BetaDist) Mean() float64            { return d.Alpha / (d.Alpha + d.Beta) }
+
+func (d BetaDist) Variance() float64 {
+	s := d.Alpha + d.Beta
+	return d.Alpha * d.Beta / (s * s * (s + 1))
+}
+
+// The mode is NaN when both shapes are below one and the density is bimodal.
+func (d BetaDist) Mode() float64 {
+	α, β := d.Alpha, d.Beta
+	switch {
+	case α > 1 && β > 1:
+		return (α - 1) / (α + β - 2)
+	case α == 1 && β == 1:
+		return 0.5
+	case α <= 1 && β >= 1:
+		return 0
+	case α >= 1 && β <= 1:
+		return 1
+	}
+	return math.NaN()
+}
+
+func (d BetaDist) Skewness() float64 {
+	α, β := d.Alpha, d.Beta
+	return 2 * (β - α) * sqrt(α+β+1) / ((α + β + 2) * sqrt(α*β))
+}
+
+func (d BetaDist) ExKurtosis() float64 {
+	α, β := d.Alpha, d.Beta
+	s := α + β
+	return 6 * ((α-β)*(α-β)*(s+1) - α*β*(s+2)) / (α * β * (s + 2) * (s + 3))
+}
+
+func (d BetaDist) Entropy() float64 {
+	α, β := d.Alpha, d.Beta
+	return LnB(α, β) - (α-1)*digamma(α) - (β-1)*digamma(β) + (α+β-2)*digamma(α+β)
+}
+
+func Beta_PDF(α float64, β float64) func(x float64) float64 {
+	return BetaDist{α, β}.PDF
+}
+func Beta_LnPDF(α float64, β float64) func(x float64) float64 {
+	return BetaDist{α, β}.LogPDF
+}
+func NextBeta(α float64, β float64) float64 { return NextBetaWith(DefaultRNG, α, β) }
+func NextBetaWith(rng RNG, α float64, β float64) float64 {
 	dα := []float64{α, β}
-	return NextDirichlet(dα)[0]
+	return NextDirichletWith(rng, dα)[0]
 }
 func Beta(α float64, β float64) func() float64 {
-	return func() float64 { return NextBeta(α, β) }
+	return BetaDist{α, β}.Rand
 }
 
 // Value of PDF of Beta distribution(α, β) at x
@@ -123,23 +204,7 @@ func Beta_PDF_At(α, β, x float64) float64 {
 
 // CDF of Beta-distribution
 func Beta_CDF(α float64, β float64) func(x float64) float64 {
-	return func(x float64) float64 {
-		//func Beta_CDF(α , β , x float64) float64 {
-		var y, res float64
-		y = math.Exp(LnΓ(α+β) - LnΓ(α) - LnΓ(β) + α*math.Log(x) + β*math.Log(1.0-x))
-		switch {
-		case x == 0:
-			res = 0.0
-		case x == 1.0:
-			res = 1.0
-		case x < (α+1.0)/(α+β+2.0):
-			res = y * betaContinuedFraction(α, β, x) / α
-		default:
-			res = 1.0 - y*betaContinuedFraction(β, α, 1.0-x)/β
-
-		}
-		return res
-	}
+	return BetaDist{α, β}.CDF
 }
 
 // Value of CDF of Beta distribution(α, β) at x
@@ -265,16 +330,10 @@ end:
 // p: Probability associated with the beta distribution
 // α: Parameter of the distribution
 // β: Parameter of the distribution
-// A: Optional lower bound to the interval of x
-// B: Optional upper bound to the interval of x
+//
+// The result is NaN if the inversion fails to converge.
 func BetaInv_CDF(α, β float64) func(p float64) float64 {
 	return func(p float64) float64 {
-		var x float64 = 0
-		var a float64 = 0
-		var b float64 = 1
-		var A float64 = 0
-		var B float64 = 1
-		var precision float64 = 1e-9
 		if p < 0.0 {
 			panic(fmt.Sprintf("p < 0"))
 		}
@@ -288,19 +347,7 @@ func BetaInv_CDF(α, β float64) func(p float64) float64 {
 			panic(fmt.Sprintf("β < 0.0"))
 		}
 
-		for (b - a) > precision {
-			x = (a + b) / 2
-			if BetaIncReg(α, β, x) > p {
-				b = x
-			} else {
-				a = x
-			}
-		}
-
-		if B > 0 && A > 0 {
-			x = x*(B-A) + A
-		}
-		return x
+		return BetaDist{α, β}.Quantile(p)
 	}
 }
 
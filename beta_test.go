@@ -1,10 +1,14 @@
 package stat
 
-import "testing"
+import (
+	"math"
+	"testing"
+)
 
 func TestBetaInvCDF(t *testing.T) {
 	dist := BetaInv_CDF(1, 4001)
-	if dist(0.5) != 0.0001732284784849848 {
-		t.Fail()
+	// Beta(1, β) has the closed form quantile 1 - (1-p)^(1/β)
+	if x := dist(0.5); math.Abs(x-0.00017322847848306232) > 1e-14*x {
+		t.Errorf("BetaInv_CDF(1, 4001)(0.5) = %v", x)
 	}
 }
@@ -1,16 +1,113 @@
 package stat
 
 import (
+	"math"
+
 	. "github.com/ematvey/go-fn/fn"
 )
 
+// Binomial distribution: number of successes in N trials with success probability Rho
+type BinomialDist struct {
+	Rho float64
+	N   int64
+}
+
+// NewBinomialDist returns a Binomial distribution, checking that 0 <= ρ <= 1 and n >= 0.
+func NewBinomialDist(ρ float64, n int64) (BinomialDist, error) {
+	if !isProbability(ρ) || n < 0 {
+		return BinomialDist{}, invalidParameter("Binomial ρ = %v, n = %d", ρ, n)
+	}
+	return BinomialDist{ρ, n}, nil
+}
+
+func (d BinomialDist) PMF(k int64) float64 {
+	if k < 0 || k > d.N {
+		return 0
+	}
+	return exp(d.LogPMF(k))
+}
+
+func (d BinomialDist) LogPMF(k int64) float64 {
+	if k < 0 || k > d.N {
+		return negInf
+	}
+	n := float64(d.N)
+	i := float64(k)
+	p := xlogy(i, d.Rho) + xlogy(n-i, 1-d.Rho)
+	p += LnΓ(n+1) - LnΓ(i+1) - LnΓ(n-i+1)
+	return p
+}
+
+func (d BinomialDist) logPMFs(dst []float64, k []int64) {
+	n := float64(d.N)
+	lnN := LnΓ(n + 1)
+	for i, ki := range k {
+		if ki < 0 || ki > d.N {
+			dst[i] = negInf
+			continue
+		}
+		j := float64(ki)
+		dst[i] = xlogy(j, d.Rho) + xlogy(n-j, 1-d.Rho) + lnN - LnΓ(j+1) - LnΓ(n-j+1)
+	}
+}
+
+func (d BinomialDist) CDF(k int64) float64      { return math.Exp(d.LogCDF(k)) }
+func (d BinomialDist) Survival(k int64) float64 { return math.Exp(d.LogSurvival(k)) }
+
+func (d BinomialDist) LogCDF(k int64) float64 {
+	lnI, _ := d.lnBetaIncReg(k)
+	return lnI
+}
+
+func (d BinomialDist) LogSurvival(k int64) float64 {
+	_, lnIc := d.lnBetaIncReg(k)
+	return lnIc
+}
+
+// P(X <= k) = I_{1-ρ}(n-k, k+1)
+func (d BinomialDist) lnBetaIncReg(k int64) (lnI, lnIc float64) {
+	switch {
+	case k < 0:
+		return negInf, 0
+	case k >= d.N:
+		return 0, negInf
+	}
+	return lnBetaIncReg(float64(d.N-k), float64(k+1), 1-d.Rho, d.Rho)
+}
+
+func (d BinomialDist) Quantile(p float64) int64 {
+	guess := quantileGuess(d.Mean(), sqrt(d.Variance()), d.Skewness(), p, 0, d.N)
+	return discreteQuantile(d.CDF, p, guess, 0, d.N)
+}
+
+func (d BinomialDist) Rand() int64            { return d.RandWith(DefaultRNG) }
+func (d BinomialDist) RandWith(rng RNG) int64 { return NextBinomialWith(rng, d.Rho, d.N) }
+func (d BinomialDist) Mean() float64          { return float64(d.N) * d.Rho }
+func (d BinomialDist) Variance() float64      { return float64(d.N) * d.Rho * (1 - d.Rho) }
+
+func (d BinomialDist) Mode() int64 {
+	k := int64(math.Floor(float64(d.N+1) * d.Rho))
+	if k > d.N {
+		return d.N
+	}
+	return k
+}
+
+func (d BinomialDist) Skewness() float64 {
+	return (1 - 2*d.Rho) / sqrt(d.Variance())
+}
+
+func (d BinomialDist) ExKurtosis() float64 {
+	return (1 - 6*d.Rho*(1-d.Rho)) / d.Variance()
+}
+
+func (d BinomialDist) Entropy() float64 {
+	return discreteEntropy(d.PMF, d.Mode(), 0, d.N)
+}
+
 // Probability Mass Function for the Binomial distribution
 func Binomial_PMF(ρ float64, n int64) func(i int64) float64 {
-	return func(i int64) float64 {
-		p := pow(ρ, float64(i)) * pow(1-ρ, float64(n-i))
-		p *= Γ(float64(n+1)) / (Γ(float64(i+1)) * Γ(float64(n-i+1)))
-		return p
-	}
+	return BinomialDist{ρ, n}.PMF
 }
 
 func Binomial_PMF_At(ρ float64, n, k int64) float64 {
@@ -20,22 +117,19 @@ func Binomial_PMF_At(ρ float64, n, k int64) float64 {
 
 // Natural logarithm of Probability Mass Function for the Binomial distribution
 func Binomial_LnPMF(ρ float64, n int64) func(i int64) float64 {
-	return func(i int64) float64 {
-		p := log(ρ)*float64(i) + log(1-ρ)*float64(n-i)
-		p += LnΓ(float64(n+1)) - LnΓ(float64(i+1)) - LnΓ(float64(n-i+1))
-		return p
-	}
+	return BinomialDist{ρ, n}.LogPMF
 }
 
-func NextBinomial(ρ float64, n int64) (result int64) {
-	for i := int64(0); i <= n; i++ {
-		result += NextBernoulli(ρ)
+func NextBinomial(ρ float64, n int64) int64 { return NextBinomialWith(DefaultRNG, ρ, n) }
+func NextBinomialWith(rng RNG, ρ float64, n int64) (result int64) {
+	for i := int64(0); i < n; i++ {
+		result += NextBernoulliWith(rng, ρ)
 	}
 	return
 }
 
 func Binomial(ρ float64, n int64) func() int64 {
-	return func() int64 { return NextBinomial(ρ, n) }
+	return BinomialDist{ρ, n}.Rand
 }
 
 // Cumulative Distribution Function for the Binomial distribution, trivial implementation
@@ -53,13 +147,19 @@ func Binomial_CDF_trivial(ρ float64, n int64) func(k int64) float64 {
 
 // Cumulative Distribution Function for the Binomial distribution
 func Binomial_CDF(ρ float64, n int64) func(k int64) float64 {
-	return func(k int64) float64 {
-		p := Beta_CDF_At((float64)(n-k), (float64)(k+1), 1-ρ)
-		return p
-	}
+	return BinomialDist{ρ, n}.CDF
 }
 
 func Binomial_CDF_At(ρ float64, n, k int64) float64 {
 	cdf := Binomial_CDF(ρ, n)
 	return cdf(k)
 }
+
+// Inverse of the cumulative distribution function: the smallest k with P(X <= k) >= p
+func Binomial_InvCDF(ρ float64, n int64) func(p float64) int64 {
+	return BinomialDist{ρ, n}.Quantile
+}
+
+func Binomial_InvCDF_For(ρ float64, n int64, p float64) int64 {
+	return BinomialDist{ρ, n}.Quantile(p)
+}
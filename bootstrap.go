@@ -0,0 +1,280 @@
+// Bootstrap resampling and confidence intervals
+
+package stat
+
+import (
+	"math"
+)
+
+// BootstrapResult holds the replicates of a statistic over bootstrap
+// resamples of the data. Estimate is the statistic of the data themselves,
+// Bias the mean of the replicates less Estimate, and StdErr their standard
+// deviation; replicates for which the statistic is NaN are left out of
+// these and of the intervals.
+//
+// The intervals are two-sided at confidence level conf, e.g. 0.95, and
+// take the α-quantile of R replicates to be the (R+1)α-th smallest, as R's
+// boot.ci does, interpolating between order statistics.
+type BootstrapResult struct {
+	Estimate   float64
+	Replicates []float64 // the statistic of resample i
+	Bias       float64
+	StdErr     float64
+
+	sorted  EmpiricalDist // the replicates that are not NaN
+	sizes   []int         // the number of observations in each sample
+	eval    func(idx [][]int) float64
+	workers int
+	seed    int64
+}
+
+// Bootstrap evaluates stat on replicates resamples of x, drawn with
+// replacement, from workers goroutines; see ParallelFor for workers and
+// seed, which make the replicates reproducible. stat must not modify its
+// argument, and is called concurrently.
+func Bootstrap(x []float64, stat func([]float64) float64, replicates, workers int, seed int64) (*BootstrapResult, error) {
+	if err := checkSample("Bootstrap", x, 2); err != nil {
+		return nil, err
+	}
+	return bootstrap([]int{len(x)}, func(idx [][]int) float64 {
+		return stat(gather(x, idx[0]))
+	}, replicates, workers, seed)
+}
+
+// BootstrapPaired is Bootstrap for a statistic of paired observations,
+// such as a correlation: each resample draws pairs (x[i], y[i]).
+func BootstrapPaired(x, y []float64, stat func(x, y []float64) float64, replicates, workers int, seed int64) (*BootstrapResult, error) {
+	if len(x) != len(y) {
+		return nil, dimensionMismatch("BootstrapPaired with %d and %d observations", len(x), len(y))
+	}
+	if err := checkTwoSamples("BootstrapPaired", x, y, 2); err != nil {
+		return nil, err
+	}
+	return bootstrap([]int{len(x)}, func(idx [][]int) float64 {
+		return stat(gather(x, idx[0]), gather(y, idx[0]))
+	}, replicates, workers, seed)
+}
+
+// BootstrapSamples is Bootstrap for a statistic of several independent
+// samples, such as a difference of means: each resample draws from every
+// sample separately, keeping their sizes.
+func BootstrapSamples(samples [][]float64, stat func([][]float64) float64, replicates, workers int, seed int64) (*BootstrapResult, error) {
+	if len(samples) == 0 {
+		return nil, invalidParameter("BootstrapSamples of no samples")
+	}
+	sizes := make([]int, len(samples))
+	for j, s := range samples {
+		if err := checkSample("BootstrapSamples", s, 2); err != nil {
+			return nil, err
+		}
+		sizes[j] = len(s)
+	}
+	return bootstrap(sizes, func(idx [][]int) float64 {
+		r := make([][]float64, len(samples))
+		for j, s := range samples {
+			r[j] = gather(s, idx[j])
+		}
+		return stat(r)
+	}, replicates, workers, seed)
+}
+
+// gather returns x[idx[0]], x[idx[1]], ...
+func gather(x []float64, idx []int) []float64 {
+	r := make([]float64, len(idx))
+	for i, k := range idx {
+		r[i] = x[k]
+	}
+	return r
+}
+
+// bootstrap evaluates a statistic of samples of the given sizes, which
+// eval computes from the indices of the observations drawn from each.
+func bootstrap(sizes []int, eval func(idx [][]int) float64, replicates, workers int, seed int64) (*BootstrapResult, error) {
+	if replicates < 2 {
+		return nil, invalidParameter("bootstrap with %d replicates", replicates)
+	}
+	b := &BootstrapResult{
+		Replicates: make([]float64, replicates),
+		sizes:      sizes,
+		eval:       eval,
+		workers:    workers,
+		seed:       seed,
+	}
+	b.Estimate = eval(b.identity())
+	if math.IsNaN(b.Estimate) {
+		return nil, invalidParameter("bootstrap statistic of the data is NaN")
+	}
+	ParallelFor(replicates, workers, seed, func(i int, rng RNG) {
+		b.Replicates[i] = eval(b.resample(rng))
+	})
+	var a Accumulator
+	kept := make([]float64, 0, replicates)
+	for _, r := range b.Replicates {
+		if !math.IsNaN(r) {
+			a.Add(r)
+			kept = append(kept, r)
+		}
+	}
+	if len(kept) < 2 {
+		return nil, invalidParameter("bootstrap statistic is NaN in %d of %d replicates", replicates-len(kept), replicates)
+	}
+	b.sorted, _ = NewEmpiricalDist(kept)
+	b.Bias = a.Mean() - b.Estimate
+	b.StdErr = sqrt(a.Variance())
+	return b, nil
+}
+
+// identity returns the indices of the data themselves.
+func (b *BootstrapResult) identity() [][]int {
+	idx := make([][]int, len(b.sizes))
+	for j, n := range b.sizes {
+		idx[j] = make([]int, n)
+		for i := range idx[j] {
+			idx[j][i] = i
+		}
+	}
+	return idx
+}
+
+// resample draws the indices of a resample of the data.
+func (b *BootstrapResult) resample(rng RNG) [][]int {
+	idx := make([][]int, len(b.sizes))
+	for j, n := range b.sizes {
+		idx[j] = make([]int, n)
+		for i := range idx[j] {
+			idx[j][i] = int(rng.Int63n(int64(n)))
+		}
+	}
+	return idx
+}
+
+// quantile returns the p-quantile of the replicates, the (R+1)p-th
+// smallest.
+func (b *BootstrapResult) quantile(p float64) float64 { return b.sorted.QuantileType(p, 6) }
+
+// PercentileInterval returns the interval between the (1-conf)/2 and
+// (1+conf)/2 quantiles of the replicates.
+func (b *BootstrapResult) PercentileInterval(conf float64) (lo, hi float64, err error) {
+	if err := checkConfidence("PercentileInterval", conf); err != nil {
+		return 0, 0, err
+	}
+	return b.quantile((1 - conf) / 2), b.quantile((1 + conf) / 2), nil
+}
+
+// BasicInterval returns the basic, or reverse percentile, interval, which
+// reflects the quantiles of the replicates about the estimate.
+func (b *BootstrapResult) BasicInterval(conf float64) (lo, hi float64, err error) {
+	if err := checkConfidence("BasicInterval", conf); err != nil {
+		return 0, 0, err
+	}
+	return 2*b.Estimate - b.quantile((1+conf)/2), 2*b.Estimate - b.quantile((1-conf)/2), nil
+}
+
+// BCaInterval returns Efron's bias-corrected and accelerated interval, the
+// percentile interval with its probabilities adjusted for the median bias
+// of the replicates and for the skewness of the statistic, which is
+// estimated by the jackknife. The jackknife evaluates the statistic once
+// for each observation left out, on the calling goroutine.
+func (b *BootstrapResult) BCaInterval(conf float64) (lo, hi float64, err error) {
+	if err := checkConfidence("BCaInterval", conf); err != nil {
+		return 0, 0, err
+	}
+	// the fraction of replicates below the estimate, counting ties as half
+	below := b.sorted.CDF(b.Estimate) - b.sorted.PDF(b.Estimate)/2
+	if below == 0 || below == 1 {
+		return 0, 0, invalidParameter("BCaInterval with all replicates on one side of the estimate")
+	}
+	z0 := Z_InvCDF_For(below)
+	a := acceleration(b.jackknife())
+	adjust := func(p float64) float64 {
+		z := z0 + Z_InvCDF_For(p)
+		return Z_CDF_At(z0 + z/(1-a*z))
+	}
+	return b.quantile(adjust((1 - conf) / 2)), b.quantile(adjust((1 + conf) / 2)), nil
+}
+
+// jackknife returns the statistic with each observation of each sample left
+// out in turn.
+func (b *BootstrapResult) jackknife() []float64 {
+	var θ []float64
+	idx := b.identity()
+	for j, all := range idx {
+		for i := range all {
+			idx[j] = append(append(make([]int, 0, len(all)-1), all[:i]...), all[i+1:]...)
+			θ = append(θ, b.eval(idx))
+		}
+		idx[j] = all
+	}
+	return θ
+}
+
+// acceleration returns the acceleration of BCa from the jackknife values θ,
+// a = Σ d³ / (6 (Σ d²)^(3/2)) with d the deviations of θ from their mean.
+func acceleration(θ []float64) float64 {
+	var m float64
+	for _, t := range θ {
+		m += t
+	}
+	m /= float64(len(θ))
+	var s2, s3 float64
+	for _, t := range θ {
+		d := m - t
+		s2 += d * d
+		s3 += d * d * d
+	}
+	if s2 == 0 {
+		return 0
+	}
+	return s3 / (6 * math.Pow(s2, 1.5))
+}
+
+// StudentizedInterval returns the bootstrap-t interval, which divides the
+// deviation of each replicate from the estimate by its own standard error,
+// and scales the quantiles of these t-values by StdErr. The standard error
+// of each replicate is that of the statistic over inner resamples of the
+// replicate's resample, so the interval costs replicates × inner
+// evaluations of the statistic. They run from the workers of the bootstrap,
+// and the inner resamples of replicate i draw from the stream
+// replicates + i of its seed, beyond those ParallelFor uses, so the
+// interval is reproducible too.
+func (b *BootstrapResult) StudentizedInterval(conf float64, inner int) (lo, hi float64, err error) {
+	if err := checkConfidence("StudentizedInterval", conf); err != nil {
+		return 0, 0, err
+	}
+	if inner < 2 {
+		return 0, 0, invalidParameter("StudentizedInterval with %d inner resamples", inner)
+	}
+	R := len(b.Replicates)
+	t := make([]float64, R)
+	ParallelFor(R, b.workers, b.seed, func(i int, rng RNG) {
+		// the same draws as for the replicate
+		idx := b.resample(rng)
+		in := NewStreamRNG(b.seed, R+i)
+		var a Accumulator
+		for k := 0; k < inner; k++ {
+			r := make([][]int, len(idx))
+			for j, ij := range idx {
+				r[j] = make([]int, len(ij))
+				for m := range r[j] {
+					r[j][m] = ij[in.Int63n(int64(len(ij)))]
+				}
+			}
+			if θ := b.eval(r); !math.IsNaN(θ) {
+				a.Add(θ)
+			}
+		}
+		t[i] = (b.Replicates[i] - b.Estimate) / sqrt(a.Variance())
+	})
+	kept := t[:0]
+	for _, ti := range t {
+		if !math.IsNaN(ti) {
+			kept = append(kept, ti)
+		}
+	}
+	d, err := NewEmpiricalDist(kept)
+	if err != nil {
+		return 0, 0, invalidParameter("StudentizedInterval without standard errors of the replicates")
+	}
+	return b.Estimate - d.QuantileType((1+conf)/2, 6)*b.StdErr,
+		b.Estimate - d.QuantileType((1-conf)/2, 6)*b.StdErr, nil
+}
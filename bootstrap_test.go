@@ -0,0 +1,138 @@
+package stat
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func mean(x []float64) float64 {
+	m, _ := sampleMoments(x)
+	return m
+}
+
+func TestBootstrapMean(t *testing.T) {
+	rng := NewRNG(12)
+	x := make([]float64, 200)
+	for i := range x {
+		x[i] = NextNormalWith(rng, 10, 3)
+	}
+	b, err := Bootstrap(x, mean, 4000, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the bootstrap standard error of the mean is σ̂/√n, with divisor n
+	m, v := sampleMoments(x)
+	n := float64(len(x))
+	check(t, "Estimate", b.Estimate, m, 0)
+	check(t, "StdErr", b.StdErr, sqrt(v/n), 0.05)
+	if abs(b.Bias) > 0.1*b.StdErr {
+		t.Errorf("Bias = %v, StdErr = %v", b.Bias, b.StdErr)
+	}
+
+	// for Normal data every interval is close to the t-interval
+	r, _ := TTest(x, 0, TwoSided, 0.9)
+	lo, hi, err := b.StudentizedInterval(0.9, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, iv := range []struct {
+		name string
+		f    func(float64) (float64, float64, error)
+	}{
+		{"percentile", b.PercentileInterval},
+		{"basic", b.BasicInterval},
+		{"BCa", b.BCaInterval},
+		{"studentized", func(float64) (float64, float64, error) { return lo, hi, nil }},
+	} {
+		lo, hi, err := iv.f(0.9)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := r.Upper - r.Lower
+		if abs(lo-r.Lower) > 0.05*w || abs(hi-r.Upper) > 0.05*w {
+			t.Errorf("%s interval [%v, %v], t-interval [%v, %v]", iv.name, lo, hi, r.Lower, r.Upper)
+		}
+	}
+
+	// the jackknife acceleration of the mean is the skewness over 6√n
+	var s3 float64
+	for _, xi := range x {
+		s3 += (xi - m) * (xi - m) * (xi - m)
+	}
+	check(t, "acceleration", acceleration(b.jackknife()), s3/(6*math.Pow(n*v, 1.5)), 1e-10)
+
+	if _, _, err := b.PercentileInterval(1); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("expected ErrInvalidParameter, got %v", err)
+	}
+	if _, err := Bootstrap(x[:1], mean, 100, 0, 1); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("expected ErrInvalidParameter, got %v", err)
+	}
+}
+
+func TestBootstrapReproducible(t *testing.T) {
+	x := []float64{3.1, 0.4, 7.7, 2.2, 5.9, 1.3, 4.4, 9.8, 0.9, 2.6}
+	median := func(x []float64) float64 {
+		e, _ := NewEmpiricalDist(x)
+		return e.QuantileType(0.5, 7)
+	}
+	a, _ := Bootstrap(x, median, 1000, 1, 5)
+	b, _ := Bootstrap(x, median, 1000, 7, 5)
+	for i := range a.Replicates {
+		if a.Replicates[i] != b.Replicates[i] {
+			t.Fatalf("replicate %d is %v with 1 worker, %v with 7", i, a.Replicates[i], b.Replicates[i])
+		}
+	}
+	la, ha, _ := a.StudentizedInterval(0.8, 20)
+	lb, hb, _ := b.StudentizedInterval(0.8, 20)
+	if la != lb || ha != hb {
+		t.Errorf("studentized intervals [%v, %v] and [%v, %v]", la, ha, lb, hb)
+	}
+}
+
+func TestBootstrapSkewed(t *testing.T) {
+	// for the mean of skewed data BCa moves the interval towards the long
+	// tail, relative to the basic interval
+	rng := NewRNG(13)
+	x := make([]float64, 40)
+	for i := range x {
+		x[i] = NextExpWith(rng, 1)
+	}
+	b, _ := Bootstrap(x, mean, 4000, 0, 2)
+	bl, bh, _ := b.BasicInterval(0.95)
+	pl, ph, _ := b.PercentileInterval(0.95)
+	cl, ch, _ := b.BCaInterval(0.95)
+	if !(cl > bl && ch > bh && ch > ph) {
+		t.Errorf("basic [%v, %v], percentile [%v, %v], BCa [%v, %v]", bl, bh, pl, ph, cl, ch)
+	}
+	check(t, "reflection", bl+ph, 2*b.Estimate, 1e-12)
+}
+
+func TestBootstrapPairedAndSamples(t *testing.T) {
+	rng := NewRNG(14)
+	x, y := make([]float64, 100), make([]float64, 100)
+	for i := range x {
+		x[i] = rng.NormFloat64()
+		y[i] = 0.6*x[i] + 0.8*rng.NormFloat64()
+	}
+	b, err := BootstrapPaired(x, y, pearson, 2000, 0, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the standard error of a correlation ρ is about (1 - ρ²)/√n
+	ρ := b.Estimate
+	check(t, "correlation StdErr", b.StdErr, (1-ρ*ρ)/10, 0.02)
+	if _, err := BootstrapPaired(x, y[1:], pearson, 100, 0, 3); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("expected ErrDimensionMismatch, got %v", err)
+	}
+
+	diff := func(s [][]float64) float64 { return mean(s[0]) - mean(s[1]) }
+	d, err := BootstrapSamples([][]float64{sleep2, sleep1}, diff, 4000, 0, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, v1 := sampleMoments(sleep1)
+	_, v2 := sampleMoments(sleep2)
+	check(t, "difference", d.Estimate, 1.58, 1e-14)
+	check(t, "difference StdErr", d.StdErr, sqrt(v1/10+v2/10), 0.05)
+}
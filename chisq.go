@@ -3,29 +3,101 @@
 package stat
 
 import (
+	"math"
+
 	. "github.com/ematvey/go-fn/fn"
 )
 
-func Xsquare_PDF(n int64) func(x float64) float64 {
-	k := float64(n) / 2
-	normalization := pow(0.5, k) / Γ(k)
-	return func(x float64) float64 {
-		return normalization * pow(x, k-1) * NextExp(-x/2)
+// Chi-Squared distribution with N degrees of freedom
+type XsquareDist struct {
+	N float64
+}
+
+// NewXsquareDist returns a Chi-Squared distribution, checking that n > 0.
+func NewXsquareDist(n float64) (XsquareDist, error) {
+	if !(n > 0) || math.IsInf(n, 0) {
+		return XsquareDist{}, invalidParameter("Xsquare n = %v", n)
 	}
+	return XsquareDist{n}, nil
 }
 
-func Xsquare_LnPDF(n int64) func(x float64) float64 {
-	k := float64(n) / 2
-	normalization := log(0.5)*k - LnΓ(k)
-	return func(x float64) float64 {
-		return normalization + log(x)*(k-1) - x/2
+func (d XsquareDist) PDF(x float64) float64 {
+	if x < 0 {
+		return 0
 	}
+	return exp(d.LogPDF(x))
 }
 
-//Xsquare(n) => sum of n N(0,1)^2
-func NextXsquare(n int64) (x float64) {
+func (d XsquareDist) LogPDF(x float64) float64 {
+	if x < 0 {
+		return negInf
+	}
+	k := d.N / 2
+	return log(0.5)*k - LnΓ(k) + xlogy(k-1, x) - x/2
+}
+
+func (d XsquareDist) logPDFs(dst, x []float64) {
+	k := d.N / 2
+	lnNorm := log(0.5)*k - LnΓ(k)
+	for i, xi := range x {
+		if xi < 0 {
+			dst[i] = negInf
+			continue
+		}
+		dst[i] = lnNorm + xlogy(k-1, xi) - xi/2
+	}
+}
+
+func (d XsquareDist) CDF(x float64) float64      { return exp(d.LogCDF(x)) }
+func (d XsquareDist) Survival(x float64) float64 { return exp(d.LogSurvival(x)) }
+
+func (d XsquareDist) LogCDF(x float64) float64 {
+	lnP, _ := lnGammaIncReg(d.N/2, x/2)
+	return lnP
+}
+
+func (d XsquareDist) LogSurvival(x float64) float64 {
+	_, lnQ := lnGammaIncReg(d.N/2, x/2)
+	return lnQ
+}
+
+func (d XsquareDist) Quantile(p float64) float64 {
+	return quantile(d, p, 2*gammaQuantileGuess(d.N/2, p), true)
+}
+
+func (d XsquareDist) Rand() float64            { return d.RandWith(DefaultRNG) }
+func (d XsquareDist) RandWith(rng RNG) float64 { return NextGammaWith(rng, d.N/2, 0.5) }
+func (d XsquareDist) Mean() float64            { return d.N }
+func (d XsquareDist) Variance() float64        { return 2 * d.N }
+
+func (d XsquareDist) Mode() float64 {
+	if d.N < 2 {
+		return 0
+	}
+	return d.N - 2
+}
+
+func (d XsquareDist) Skewness() float64   { return sqrt(8 / d.N) }
+func (d XsquareDist) ExKurtosis() float64 { return 12 / d.N }
+
+func (d XsquareDist) Entropy() float64 {
+	k := d.N / 2
+	return k + log(2) + LnΓ(k) + (1-k)*digamma(k)
+}
+
+func Xsquare_PDF(n int64) func(x float64) float64 {
+	return XsquareDist{float64(n)}.PDF
+}
+
+func Xsquare_LnPDF(n int64) func(x float64) float64 {
+	return XsquareDist{float64(n)}.LogPDF
+}
+
+// Xsquare(n) => sum of n N(0,1)^2
+func NextXsquare(n int64) float64 { return NextXsquareWith(DefaultRNG, n) }
+func NextXsquareWith(rng RNG, n int64) (x float64) {
 	for i := iZero; i < n; i++ {
-		n := NextNormal(0, 1)
+		n := rng.NormFloat64()
 		x += n * n
 	}
 	return
@@ -37,17 +109,12 @@ func Xsquare(n int64) func() float64 {
 	}
 }
 
-//Cumulative density function of the Chi-Squared distribution
+// Cumulative density function of the Chi-Squared distribution
 func Xsquare_CDF(n int64) func(p float64) float64 {
-	return func(p float64) float64 {
-		return Γr(float64(n)/2, p/2)
-	}
+	return XsquareDist{float64(n)}.CDF
 }
 
-//Inverse CDF (Quantile) function of the Chi-Squared distribution
+// Inverse CDF (Quantile) function of the Chi-Squared distribution
 func Xsquare_InvCDF(n int64) func(p float64) float64 {
-	return func(p float64) float64 {
-		//return Gamma_InvCDF_At(n/2, 2, p)  to be implemented
-		return Gamma_InvCDF_For(float64(n)/2, 2, p)
-	}
+	return XsquareDist{float64(n)}.Quantile
 }
@@ -4,18 +4,133 @@ import (
 	"math"
 )
 
-func Choice_PMF(θ []float64) func(i int64) float64 {
-	return func(i int64) float64 {
-		return θ[i]
+// Categorical distribution over 0, 1, ..., len(Theta)-1 with weights Theta
+type ChoiceDist struct {
+	Theta []float64
+}
+
+// NewChoiceDist returns a categorical distribution, checking that the
+// weights are non-negative and sum to one.
+func NewChoiceDist(θ []float64) (ChoiceDist, error) {
+	if len(θ) == 0 {
+		return ChoiceDist{}, invalidParameter("Choice θ is empty")
 	}
+	sum := fZero
+	for i, w := range θ {
+		if !(w >= 0) {
+			return ChoiceDist{}, invalidParameter("Choice θ[%d] = %v", i, w)
+		}
+		sum += w
+	}
+	if math.Abs(sum-1) > 1e-9*float64(len(θ)) {
+		return ChoiceDist{}, invalidParameter("Choice θ sums to %v", sum)
+	}
+	return ChoiceDist{θ}, nil
 }
-func Choice_LnPMF(θ []float64) func(i int64) float64 {
-	return func(i int64) float64 {
-		return log(θ[i])
+
+func (d ChoiceDist) PMF(k int64) float64 {
+	if k < 0 || k >= int64(len(d.Theta)) {
+		return 0
 	}
+	return d.Theta[k]
 }
-func NextChoice(θ []float64) int64 {
-	u := NextUniform()
+
+func (d ChoiceDist) LogPMF(k int64) float64 { return log(d.PMF(k)) }
+
+func (d ChoiceDist) CDF(k int64) float64 {
+	var p float64
+	for i := iZero; i <= k && i < int64(len(d.Theta)); i++ {
+		p += d.Theta[i]
+	}
+	return p
+}
+
+func (d ChoiceDist) Survival(k int64) float64 {
+	var p float64
+	for i := int64(len(d.Theta)) - 1; i > k && i >= 0; i-- {
+		p += d.Theta[i]
+	}
+	return p
+}
+
+func (d ChoiceDist) LogCDF(k int64) float64      { return log(d.CDF(k)) }
+func (d ChoiceDist) LogSurvival(k int64) float64 { return log(d.Survival(k)) }
+
+func (d ChoiceDist) Quantile(p float64) int64 {
+	if p <= 0 {
+		return 0
+	}
+	var sum float64
+	for i, θ := range d.Theta {
+		if sum += θ; sum >= p {
+			return int64(i)
+		}
+	}
+	return int64(len(d.Theta) - 1)
+}
+
+func (d ChoiceDist) Rand() int64            { return d.RandWith(DefaultRNG) }
+func (d ChoiceDist) RandWith(rng RNG) int64 { return NextChoiceWith(rng, d.Theta) }
+
+func (d ChoiceDist) Mean() (μ float64) {
+	for i, θ := range d.Theta {
+		μ += float64(i) * θ
+	}
+	return
+}
+
+func (d ChoiceDist) Variance() (v float64) {
+	μ := d.Mean()
+	for i, θ := range d.Theta {
+		v += (float64(i) - μ) * (float64(i) - μ) * θ
+	}
+	return
+}
+
+// Mode returns the first index with the largest weight.
+func (d ChoiceDist) Mode() int64 {
+	var k int
+	for i, θ := range d.Theta {
+		if θ > d.Theta[k] {
+			k = i
+		}
+	}
+	return int64(k)
+}
+
+func (d ChoiceDist) Skewness() float64 {
+	return d.centralMoment(3) / pow(d.Variance(), 1.5)
+}
+
+func (d ChoiceDist) ExKurtosis() float64 {
+	v := d.Variance()
+	return d.centralMoment(4)/(v*v) - 3
+}
+
+func (d ChoiceDist) Entropy() (h float64) {
+	for _, θ := range d.Theta {
+		h -= xlogy(θ, θ)
+	}
+	return
+}
+
+func (d ChoiceDist) centralMoment(n float64) (m float64) {
+	μ := d.Mean()
+	for i, θ := range d.Theta {
+		m += pow(float64(i)-μ, n) * θ
+	}
+	return
+}
+
+func Choice_PMF(θ []float64) func(i int64) float64 {
+	return ChoiceDist{θ}.PMF
+}
+func Choice_LnPMF(θ []float64) func(i int64) float64 {
+	return ChoiceDist{θ}.LogPMF
+}
+func NextChoice(θ []float64) int64 { return NextChoiceWith(DefaultRNG, θ) }
+func NextChoiceWith(rng RNG, θ []float64) int64 {
+	u := rng.Float64()
 	i := 0
 	sum := θ[0]
 	for ; sum < u && i < len(θ)-1; i++ {
@@ -27,14 +142,23 @@ func NextChoice(θ []float64) int64 {
 	return int64(i)
 }
 func Choice(θ []float64) func() int64 {
-	return func() int64 {
-		return NextChoice(θ)
-	}
+	return ChoiceDist{θ}.Rand
+}
+func Choice_InvCDF(θ []float64) func(p float64) int64 {
+	return ChoiceDist{θ}.Quantile
 }
 func NextLogChoice(lws []float64) int64 {
 	return LogChoice(lws)()
 }
+func NextLogChoiceWith(rng RNG, lws []float64) int64 {
+	return NextChoiceWith(rng, logWeights(lws))
+}
 func LogChoice(lws []float64) func() int64 {
+	return Choice(logWeights(lws))
+}
+
+// logWeights turns log-weights into normalized probabilities
+func logWeights(lws []float64) []float64 {
 	max := lws[0]
 	for _, lw := range lws[1:len(lws)] {
 		if lw > max {
@@ -51,5 +175,5 @@ func LogChoice(lws []float64) func() int64 {
 	for i := range ws {
 		ws[i] *= norm
 	}
-	return Choice(ws)
+	return ws
 }
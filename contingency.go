@@ -0,0 +1,383 @@
+// Contingency tables
+
+package stat
+
+import (
+	"math"
+	"sort"
+
+	. "github.com/ematvey/go-fn/fn"
+)
+
+// ContingencyTable is an r×c table of counts cross-classifying
+// observations by two factors.
+type ContingencyTable struct {
+	counts     [][]int64
+	rows, cols []int64
+	n          int64
+}
+
+// NewContingencyTable returns the table with the given counts, counts[i][j]
+// being the number of observations in row i and column j. It needs at least
+// two rows and two columns, and every row and column must have a positive
+// total.
+func NewContingencyTable(counts [][]int64) (ContingencyTable, error) {
+	r := len(counts)
+	if r < 2 || len(counts[0]) < 2 {
+		return ContingencyTable{}, invalidParameter("ContingencyTable needs at least 2 rows and 2 columns")
+	}
+	c := len(counts[0])
+	t := ContingencyTable{counts: make([][]int64, r), rows: make([]int64, r), cols: make([]int64, c)}
+	for i, row := range counts {
+		if len(row) != c {
+			return ContingencyTable{}, dimensionMismatch("ContingencyTable row %d has %d columns, want %d", i, len(row), c)
+		}
+		t.counts[i] = append([]int64(nil), row...)
+		for j, x := range row {
+			if x < 0 {
+				return ContingencyTable{}, invalidParameter("ContingencyTable count (%d, %d) = %d", i, j, x)
+			}
+			t.rows[i] += x
+			t.cols[j] += x
+			t.n += x
+		}
+	}
+	for i, x := range t.rows {
+		if x == 0 {
+			return ContingencyTable{}, invalidParameter("ContingencyTable row %d is empty", i)
+		}
+	}
+	for j, x := range t.cols {
+		if x == 0 {
+			return ContingencyTable{}, invalidParameter("ContingencyTable column %d is empty", j)
+		}
+	}
+	return t, nil
+}
+
+// Dims returns the numbers of rows and columns.
+func (t ContingencyTable) Dims() (r, c int) { return len(t.rows), len(t.cols) }
+
+// Total returns the number of observations.
+func (t ContingencyTable) Total() int64 { return t.n }
+
+// Expected returns the counts expected under independence of the rows and
+// columns, row total × column total / n.
+func (t ContingencyTable) Expected() [][]float64 {
+	e := make([][]float64, len(t.rows))
+	for i, ri := range t.rows {
+		e[i] = make([]float64, len(t.cols))
+		for j, cj := range t.cols {
+			e[i][j] = float64(ri) * float64(cj) / float64(t.n)
+		}
+	}
+	return e
+}
+
+// StdResiduals returns the standardized residuals
+// (O - E) / √(E (1 - row total/n) (1 - column total/n)),
+// which are approximately standard Normal under independence.
+func (t ContingencyTable) StdResiduals() [][]float64 {
+	e := t.Expected()
+	n := float64(t.n)
+	for i, ri := range t.rows {
+		for j, cj := range t.cols {
+			v := e[i][j] * (1 - float64(ri)/n) * (1 - float64(cj)/n)
+			e[i][j] = (float64(t.counts[i][j]) - e[i][j]) / sqrt(v)
+		}
+	}
+	return e
+}
+
+// pearson returns Pearson's X² statistic, with Yates' continuity correction
+// if yates is set.
+func (t ContingencyTable) pearson(yates bool) float64 {
+	var x2 float64
+	for i, row := range t.Expected() {
+		for j, e := range row {
+			d := abs(float64(t.counts[i][j]) - e)
+			if yates {
+				d -= math.Min(0.5, d)
+			}
+			x2 += d * d / e
+		}
+	}
+	return x2
+}
+
+// CramersV returns Cramér's V = √(X² / (n (min(r, c) - 1))), a measure of
+// association between 0 and 1, from the uncorrected X².
+func (t ContingencyTable) CramersV() float64 {
+	k := len(t.rows)
+	if len(t.cols) < k {
+		k = len(t.cols)
+	}
+	return sqrt(t.pearson(false) / (float64(t.n) * float64(k-1)))
+}
+
+func (t ContingencyTable) df() float64 {
+	return float64((len(t.rows) - 1) * (len(t.cols) - 1))
+}
+
+// ContingencyTestResult is the outcome of a test of independence on a
+// contingency table, together with the expected counts, standardized
+// residuals and Cramér's V of the table.
+type ContingencyTestResult struct {
+	TestResult
+	Expected, StdResiduals [][]float64
+	CramersV               float64
+}
+
+func (t ContingencyTable) result(r TestResult) ContingencyTestResult {
+	return ContingencyTestResult{
+		TestResult:   r,
+		Expected:     t.Expected(),
+		StdResiduals: t.StdResiduals(),
+		CramersV:     t.CramersV(),
+	}
+}
+
+// ChiSquareTest is Pearson's chi-square test of independence, with
+// (r-1)(c-1) degrees of freedom and the p-value from the upper tail of
+// Xsquare_CDF. If correct is set, 2×2 tables get Yates' continuity
+// correction; larger tables never do.
+func (t ContingencyTable) ChiSquareTest(correct bool) ContingencyTestResult {
+	yates := correct && len(t.rows) == 2 && len(t.cols) == 2
+	x2 := t.pearson(yates)
+	df := t.df()
+	return t.result(TestResult{Statistic: x2, PValue: XsquareDist{df}.Survival(x2), DF: df})
+}
+
+// GTest is the likelihood-ratio test of independence, with
+// G = 2 Σ O log(O/E) on (r-1)(c-1) degrees of freedom.
+func (t ContingencyTable) GTest() ContingencyTestResult {
+	var g float64
+	for i, row := range t.Expected() {
+		for j, e := range row {
+			o := float64(t.counts[i][j])
+			g += 2 * xlogy(o, o/e)
+		}
+	}
+	df := t.df()
+	return t.result(TestResult{Statistic: g, PValue: XsquareDist{df}.Survival(g), DF: df})
+}
+
+// maxFisherNodes bounds the number of partial tables FisherTest extends.
+const maxFisherNodes = 10000000
+
+// FisherTest is Fisher's exact test of independence, conditional on the row
+// and column totals. For a 2×2 table the count in the first cell is
+// Hypergeometric, any alternative may be given, Less meaning an odds ratio
+// below 1, and the statistic is the sample odds ratio. Larger tables admit
+// only the two-sided test, whose statistic is the probability of the
+// observed table; they are enumerated by a network algorithm, and an error
+// is returned if there are too many partial tables.
+// The two-sided p-value sums the probabilities of the tables no more likely
+// than the observed one.
+func (t ContingencyTable) FisherTest(alt Alternative) (ContingencyTestResult, error) {
+	if !alt.valid() {
+		return ContingencyTestResult{}, invalidParameter("FisherTest alternative %d", alt)
+	}
+	if len(t.rows) == 2 && len(t.cols) == 2 {
+		return t.result(t.fisher2x2(alt)), nil
+	}
+	if alt != TwoSided {
+		return ContingencyTestResult{}, invalidParameter("FisherTest on a %d×%d table must be two-sided", len(t.rows), len(t.cols))
+	}
+	p, lp, err := t.fisherRxC()
+	if err != nil {
+		return ContingencyTestResult{}, err
+	}
+	return t.result(TestResult{Statistic: exp(lp), PValue: p}), nil
+}
+
+// fisherRelErr is the relative tolerance within which tables count as
+// equally likely as the observed one, as in R.
+const fisherRelErr = 1 + 1e-7
+
+func (t ContingencyTable) fisher2x2(alt Alternative) TestResult {
+	a, b := t.counts[0][0], t.counts[0][1]
+	c, d := t.counts[1][0], t.counts[1][1]
+	h := HypergeometricDist{t.n, t.cols[0], t.rows[0]}
+	or := float64(a) * float64(d) / (float64(b) * float64(c))
+	if math.IsNaN(or) {
+		or = 1
+	}
+	var p float64
+	switch alt {
+	case Less:
+		p = h.CDF(a)
+	case Greater:
+		p = h.Survival(a - 1)
+	default:
+		lo, hi := h.support()
+		lim := h.LogPMF(a) + math.Log(fisherRelErr)
+		var lp []float64
+		for k := lo; k <= hi; k++ {
+			if l := h.LogPMF(k); l <= lim {
+				lp = append(lp, l)
+			}
+		}
+		p = math.Min(1, exp(logSumExp(lp)))
+	}
+	return TestResult{Statistic: or, PValue: p}
+}
+
+// fisherRxC returns the two-sided p-value of t and its log probability.
+//
+// It runs through the columns, in the manner of the network algorithm of
+// Mehta and Patel (1983). A node is a partial table, described by what is
+// left of the row totals. Since the rows can be permuted, the totals are
+// kept sorted, which merges many nodes. Each node holds the distinct log
+// probabilities of the paths that reach it, with their multiplicities.
+//
+// Every completion of a node has a log probability within bounds. If all
+// completions are at most as likely as t, their total is added in closed
+// form. If none are, the path is dropped. Otherwise the path is extended
+// by every split of the next column.
+func (t ContingencyTable) fisherRxC() (p, lpObs float64, err error) {
+	r, c := len(t.rows), len(t.cols)
+	lnFact := make([]float64, t.n+1)
+	for i := range lnFact {
+		lnFact[i] = LnΓ(float64(i + 1))
+	}
+	// P(table) = Π r_i! Π c_j! / (n! Π x_ij!)
+	lnConst := -lnFact[t.n]
+	for _, x := range t.rows {
+		lnConst += lnFact[x]
+	}
+	for _, x := range t.cols {
+		lnConst += lnFact[x]
+	}
+	lpObs = lnConst
+	for _, row := range t.counts {
+		for _, x := range row {
+			lpObs -= lnFact[x]
+		}
+	}
+	lim := lpObs + math.Log(fisherRelErr)
+
+	// spread returns the largest -Σ log x! over m non-negative integers
+	// summing to s, attained by spreading s evenly.
+	spread := func(s int64, m int) float64 {
+		q, k := s/int64(m), s%int64(m)
+		return -float64(k)*lnFact[q+1] - float64(int64(m)-k)*lnFact[q]
+	}
+
+	type node struct {
+		rem   []int64
+		paths map[int64]fisherPath
+	}
+	key := func(rem []int64) string {
+		b := make([]byte, 0, 8*len(rem))
+		for _, x := range rem {
+			b = append(b, byte(x), byte(x>>8), byte(x>>16), byte(x>>24),
+				byte(x>>32), byte(x>>40), byte(x>>48), byte(x>>56))
+		}
+		return string(b)
+	}
+	rows := append([]int64(nil), t.rows...)
+	sortInt64s(rows)
+	level := map[string]*node{key(rows): {rows, map[int64]fisherPath{0: {lnConst, 1}}}}
+	var visits int
+	for j := 0; j < c && len(level) > 0; j++ {
+		next := map[string]*node{}
+		for _, nd := range level {
+			// bound -Σ log x! over the completions, relaxing one set of
+			// margins at a time
+			var hiRows, hiCols, loRows, loCols float64
+			var nrem int64
+			for _, x := range nd.rem {
+				hiRows += spread(x, c-j)
+				loRows -= lnFact[x]
+				nrem += x
+			}
+			for _, x := range t.cols[j:] {
+				hiCols += spread(x, r)
+				loCols -= lnFact[x]
+			}
+			hi, lo := math.Min(hiRows, hiCols), math.Max(loRows, loCols)
+			all := lnFact[nrem] + loRows + loCols
+			var open []fisherPath
+			for _, ph := range nd.paths {
+				switch {
+				case ph.lp+hi <= lim:
+					p += ph.count * exp(ph.lp+all)
+				case ph.lp+lo > lim:
+					// no completion counts
+				case j == c-1:
+					// unreachable: with one column left lo = hi
+				default:
+					open = append(open, ph)
+				}
+			}
+			if len(open) == 0 {
+				continue
+			}
+			// split column j among the rows
+			x := make([]int64, r)
+			var split func(i int, left int64, lx float64)
+			split = func(i int, left int64, lx float64) {
+				if i == r-1 {
+					if left > nd.rem[i] {
+						return
+					}
+					x[i] = left
+					lx -= lnFact[left]
+					rem := make([]int64, r)
+					for k := range rem {
+						rem[k] = nd.rem[k] - x[k]
+					}
+					sortInt64s(rem)
+					k := key(rem)
+					child := next[k]
+					if child == nil {
+						child = &node{rem, map[int64]fisherPath{}}
+						next[k] = child
+					}
+					for _, ph := range open {
+						visits++
+						lp := ph.lp + lx
+						q := int64(math.Round(lp * 1e9))
+						c := child.paths[q]
+						child.paths[q] = fisherPath{lp, c.count + ph.count}
+					}
+					return
+				}
+				var below int64
+				for _, y := range nd.rem[i+1:] {
+					below += y
+				}
+				lo := left - below
+				if lo < 0 {
+					lo = 0
+				}
+				hi := left
+				if nd.rem[i] < hi {
+					hi = nd.rem[i]
+				}
+				for v := lo; v <= hi; v++ {
+					x[i] = v
+					split(i+1, left-v, lx-lnFact[v])
+				}
+			}
+			split(0, t.cols[j], 0)
+			if visits > maxFisherNodes {
+				return 0, lpObs, invalidParameter("FisherTest: a %d×%d table of %d observations is too large to enumerate", r, c, t.n)
+			}
+		}
+		level = next
+	}
+	return math.Min(1, p), lpObs, nil
+}
+
+// fisherPath is a set of equally likely partial tables: their common log
+// probability so far, and how many there are.
+type fisherPath struct {
+	lp, count float64
+}
+
+// sortInt64s sorts x in increasing order.
+func sortInt64s(x []int64) {
+	sort.Slice(x, func(a, b int) bool { return x[a] < x[b] })
+}
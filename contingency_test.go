@@ -0,0 +1,110 @@
+package stat
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHypergeometricDist(t *testing.T) {
+	d := HypergeometricDist{20, 7, 12}
+	// the support starts above 0 when the draws must include successes
+	if lo, hi := d.support(); lo != 0 || hi != 7 {
+		t.Errorf("support = [%d, %d]", lo, hi)
+	}
+	d = HypergeometricDist{20, 15, 12}
+	if lo, hi := d.support(); lo != 7 || hi != 12 {
+		t.Errorf("support = [%d, %d]", lo, hi)
+	}
+	var sum float64
+	for k := int64(7); k <= 12; k++ {
+		sum += d.PMF(k)
+		check(t, "CDF", d.CDF(k), sum, 1e-13)
+		check(t, "Survival", d.Survival(k), 1-sum, 1e-13)
+	}
+	check(t, "PMF", d.PMF(9), 5005.0*10/125970, 1e-13)
+	if d.PMF(6) != 0 || d.CDF(6) != 0 || d.Survival(12) != 0 {
+		t.Error("mass outside the support")
+	}
+}
+
+func TestContingencyTable(t *testing.T) {
+	// the party affiliation example of R's chisq.test
+	tab, err := NewContingencyTable([][]int64{{762, 327, 468}, {484, 239, 477}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := tab.ChiSquareTest(true)
+	check(t, "X²", r.Statistic, 30.07015, 1e-6)
+	check(t, "DF", r.DF, 2, 0)
+	check(t, "p", r.PValue, 2.953589e-07, 1e-6)
+	e := 1246.0 * 1557 / 2757
+	check(t, "Expected", r.Expected[0][0], e, 1e-12)
+	v := e * (1 - 1246.0/2757) * (1 - 1557.0/2757)
+	check(t, "StdResiduals", r.StdResiduals[0][0], (762-e)/math.Sqrt(v), 1e-12)
+	check(t, "CramersV", r.CramersV, math.Sqrt(r.Statistic/2757), 1e-12)
+
+	g := tab.GTest()
+	var want float64
+	for i, row := range g.Expected {
+		for j, e := range row {
+			o := float64(tab.counts[i][j])
+			want += 2 * o * math.Log(o/e)
+		}
+	}
+	check(t, "G", g.Statistic, want, 1e-12)
+	check(t, "G p", g.PValue, 1-Xsquare_CDF(2)(want), 1e-9)
+
+	if _, err := NewContingencyTable([][]int64{{1, 2}, {0, 0}}); err == nil {
+		t.Error("empty row accepted")
+	}
+	if _, err := NewContingencyTable([][]int64{{1, 2}, {3}}); err == nil {
+		t.Error("ragged table accepted")
+	}
+}
+
+func TestFisherTest(t *testing.T) {
+	// the tea tasting example of R's fisher.test
+	tea, _ := NewContingencyTable([][]int64{{3, 1}, {1, 3}})
+	r, err := tea.FisherTest(TwoSided)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, "two-sided", r.PValue, 34.0/70, 1e-14)
+	check(t, "odds ratio", r.Statistic, 9, 0)
+	r, _ = tea.FisherTest(Greater)
+	check(t, "greater", r.PValue, 17.0/70, 1e-14)
+	r, _ = tea.FisherTest(Less)
+	check(t, "less", r.PValue, 69.0/70, 1e-14)
+	// with Yates' correction X² = 0.5
+	check(t, "Yates", tea.ChiSquareTest(true).Statistic, 0.5, 1e-14)
+	check(t, "uncorrected", tea.ChiSquareTest(false).Statistic, 2, 1e-14)
+
+	// the enumeration agrees with the Hypergeometric test on 2×2 tables
+	for _, c := range [][][]int64{{{3, 1}, {1, 3}}, {{10, 2}, {3, 15}}, {{0, 5}, {6, 1}}} {
+		tab, _ := NewContingencyTable(c)
+		r, _ := tab.FisherTest(TwoSided)
+		p, lp, err := tab.fisherRxC()
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, "r×c p", p, r.PValue, 1e-12)
+		h := HypergeometricDist{tab.n, tab.cols[0], tab.rows[0]}
+		check(t, "r×c probability", exp(lp), h.PMF(c[0][0]), 1e-12)
+	}
+
+	// the job satisfaction example of R's fisher.test
+	job, _ := NewContingencyTable([][]int64{
+		{1, 3, 10, 6},
+		{2, 3, 10, 7},
+		{1, 6, 14, 12},
+		{0, 1, 9, 11},
+	})
+	r, err = job.FisherTest(TwoSided)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, "Job", r.PValue, 0.7826849, 1e-6)
+	if _, err := job.FisherTest(Less); err == nil {
+		t.Error("one-sided test accepted on a 4×4 table")
+	}
+}
@@ -0,0 +1,398 @@
+// Correlation
+
+package stat
+
+import (
+	"math"
+
+	mx "github.com/skelterjohn/go.matrix"
+)
+
+// CorrelationMethod selects a correlation coefficient.
+type CorrelationMethod int
+
+const (
+	PearsonCorrelation  CorrelationMethod = iota // product-moment correlation
+	SpearmanCorrelation                          // Pearson correlation of the ranks
+	KendallCorrelation                           // Kendall's τ-b
+)
+
+// CorrelationResult is the outcome of a test that a correlation coefficient
+// is zero. Estimate is the coefficient and [Lower, Upper] its confidence
+// interval, one-sided for a one-sided alternative.
+type CorrelationResult struct {
+	TestResult
+	Estimate, Lower, Upper float64
+}
+
+// maxExactSpearman is the largest sample whose Spearman test enumerates all
+// permutations.
+const maxExactSpearman = 9
+
+func checkPairs(name string, x, y []float64, min int) error {
+	if len(x) != len(y) {
+		return dimensionMismatch("%s with %d and %d observations", name, len(x), len(y))
+	}
+	if err := checkSample(name, x, min); err != nil {
+		return err
+	}
+	return checkSample(name, y, min)
+}
+
+// pearson returns the Pearson correlation of x and y, NaN if either is
+// constant.
+func pearson(x, y []float64) float64 {
+	μx, _ := sampleMoments(x)
+	μy, _ := sampleMoments(y)
+	var sxy, sxx, syy float64
+	for i := range x {
+		dx, dy := x[i]-μx, y[i]-μy
+		sxy += dx * dy
+		sxx += dx * dx
+		syy += dy * dy
+	}
+	r := sxy / sqrt(sxx*syy)
+	return math.Max(-1, math.Min(1, r))
+}
+
+// kendall returns Kendall's τ-b of x and y and the difference S of the
+// numbers of concordant and discordant pairs.
+func kendall(x, y []float64) (τ, S float64) {
+	n := len(x)
+	var tx, ty float64 // pairs tied in x, in y
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			a, b := sign(x[i]-x[j]), sign(y[i]-y[j])
+			S += a * b
+			if a == 0 {
+				tx++
+			}
+			if b == 0 {
+				ty++
+			}
+		}
+	}
+	n0 := float64(n*(n-1)) / 2
+	return S / sqrt((n0-tx)*(n0-ty)), S
+}
+
+func sign(x float64) float64 {
+	switch {
+	case x > 0:
+		return 1
+	case x < 0:
+		return -1
+	}
+	return 0
+}
+
+// fisherInterval returns the confidence interval for a correlation r from
+// the Normal approximation to its Fisher transform atanh(r), whose standard
+// error is se.
+func fisherInterval(r, se float64, alt Alternative, conf float64) (lo, hi float64) {
+	z := math.Atanh(r)
+	lo, hi = -1, 1
+	switch alt {
+	case Less:
+		hi = math.Tanh(z + Z_InvCDF_For(conf)*se)
+	case Greater:
+		lo = math.Tanh(z - Z_InvCDF_For(conf)*se)
+	default:
+		q := Z_InvCDF_For((1 + conf) / 2)
+		lo, hi = math.Tanh(z-q*se), math.Tanh(z+q*se)
+	}
+	return
+}
+
+// correlationT completes the t-test of a correlation r estimated from n
+// observations after removing k other variables.
+func correlationT(r float64, n, k int, alt Alternative, conf float64) CorrelationResult {
+	df := float64(n - 2 - k)
+	t := r * sqrt(df/(1-r*r))
+	res := CorrelationResult{
+		TestResult: TestResult{Statistic: t, PValue: pValue(StudentsTDist{df}, t, alt), DF: df},
+		Estimate:   r,
+		Lower:      math.NaN(),
+		Upper:      math.NaN(),
+	}
+	if m := n - 3 - k; m > 0 {
+		res.Lower, res.Upper = fisherInterval(r, 1/sqrt(float64(m)), alt, conf)
+	}
+	return res
+}
+
+// PearsonTest is the test that the Pearson correlation of x and y is zero,
+// with statistic t = r √((n-2)/(1-r²)) on n - 2 degrees of freedom. The
+// confidence interval at level conf comes from the Fisher z-transform, and
+// is NaN for fewer than 4 observations.
+func PearsonTest(x, y []float64, alt Alternative, conf float64) (CorrelationResult, error) {
+	if err := checkMeanTest("PearsonTest", alt, conf); err != nil {
+		return CorrelationResult{}, err
+	}
+	if err := checkPairs("PearsonTest", x, y, 3); err != nil {
+		return CorrelationResult{}, err
+	}
+	r := pearson(x, y)
+	if math.IsNaN(r) {
+		return CorrelationResult{}, invalidParameter("PearsonTest: a sample is constant")
+	}
+	return correlationT(r, len(x), 0, alt, conf), nil
+}
+
+// SpearmanTest is the test that Spearman's rank correlation ρ of x and y is
+// zero, using midranks for ties. The statistic is S = (n³-n)(1-ρ)/6, the
+// sum of squared rank differences when there are no ties. Without ties and
+// for at most 9 observations the p-value is exact, from all permutations;
+// otherwise it uses the t approximation of PearsonTest. The confidence
+// interval comes from the Fisher z-transform with the standard error
+// √(1.06/(n-3)) of Fieller, Hartley and Pearson (1957).
+func SpearmanTest(x, y []float64, alt Alternative, conf float64) (CorrelationResult, error) {
+	if err := checkMeanTest("SpearmanTest", alt, conf); err != nil {
+		return CorrelationResult{}, err
+	}
+	if err := checkPairs("SpearmanTest", x, y, 3); err != nil {
+		return CorrelationResult{}, err
+	}
+	rx, ry := midranks(x), midranks(y)
+	ρ := pearson(rx, ry)
+	if math.IsNaN(ρ) {
+		return CorrelationResult{}, invalidParameter("SpearmanTest: a sample is constant")
+	}
+	n := len(x)
+	nf := float64(n)
+	res := correlationT(ρ, n, 0, alt, conf)
+	res.Statistic = (nf*nf*nf - nf) * (1 - ρ) / 6
+	res.DF = 0
+	if n <= maxExactSpearman && tieSum(x) == 0 && tieSum(y) == 0 {
+		var d int
+		for i := range rx {
+			e := int(rx[i] - ry[i])
+			d += e * e
+		}
+		// ρ > 0 means a small sum of squared rank differences
+		a := alt
+		switch alt {
+		case Less:
+			a = Greater
+		case Greater:
+			a = Less
+		}
+		res.PValue = exactRankP(spearmanPMF(n), d, a)
+	}
+	if n > 3 {
+		res.Lower, res.Upper = fisherInterval(ρ, sqrt(1.06/(nf-3)), alt, conf)
+	}
+	return res, nil
+}
+
+// spearmanPMF returns the null distribution of the sum of squared rank
+// differences D over the n! permutations, indexed by D.
+func spearmanPMF(n int) []float64 {
+	p := make([]float64, (n*n*n-n)/3+1)
+	perm := make([]int, n)
+	for i := range perm {
+		perm[i] = i
+	}
+	var total float64
+	var visit func(k int)
+	visit = func(k int) {
+		if k == n {
+			var d int
+			for i, j := range perm {
+				d += (i - j) * (i - j)
+			}
+			p[d]++
+			total++
+			return
+		}
+		for i := k; i < n; i++ {
+			perm[k], perm[i] = perm[i], perm[k]
+			visit(k + 1)
+			perm[k], perm[i] = perm[i], perm[k]
+		}
+	}
+	visit(0)
+	for i := range p {
+		p[i] /= total
+	}
+	return p
+}
+
+// KendallTest is the test that Kendall's τ-b of x and y is zero. Without
+// ties and for fewer than 50 observations the statistic is the number of
+// concordant pairs, with its exact distribution; otherwise it is the
+// Normal score of S, the number of concordant minus discordant pairs,
+// using the variance of S corrected for ties. The confidence interval
+// comes from the Fisher z-transform with the standard error √(0.437/(n-4))
+// of Fieller, Hartley and Pearson (1957), and is NaN for fewer than 5
+// observations.
+func KendallTest(x, y []float64, alt Alternative, conf float64) (CorrelationResult, error) {
+	if err := checkMeanTest("KendallTest", alt, conf); err != nil {
+		return CorrelationResult{}, err
+	}
+	if err := checkPairs("KendallTest", x, y, 2); err != nil {
+		return CorrelationResult{}, err
+	}
+	τ, S := kendall(x, y)
+	if math.IsNaN(τ) {
+		return CorrelationResult{}, invalidParameter("KendallTest: a sample is constant")
+	}
+	n := len(x)
+	nf := float64(n)
+	res := CorrelationResult{Estimate: τ, Lower: math.NaN(), Upper: math.NaN()}
+	if n < maxExactRank && tieSum(x) == 0 && tieSum(y) == 0 {
+		c := (nf*(nf-1)/2 + S) / 2
+		res.Statistic = c
+		res.PValue = exactRankP(kendallPMF(n), int(math.Round(c)), alt)
+	} else {
+		v0 := nf * (nf - 1) * (2*nf + 5)
+		var vt, vu, t1, u1, t2, u2 float64
+		for _, g := range tieGroups(x) {
+			vt += g * (g - 1) * (2*g + 5)
+			t1 += g * (g - 1)
+			t2 += g * (g - 1) * (g - 2)
+		}
+		for _, g := range tieGroups(y) {
+			vu += g * (g - 1) * (2*g + 5)
+			u1 += g * (g - 1)
+			u2 += g * (g - 1) * (g - 2)
+		}
+		v := (v0-vt-vu)/18 + t1*u1/(2*nf*(nf-1)) + t2*u2/(9*nf*(nf-1)*(nf-2))
+		z := S / sqrt(v)
+		res.Statistic = z
+		res.PValue = pValue(NormalDist{0, 1}, z, alt)
+	}
+	if n > 4 {
+		res.Lower, res.Upper = fisherInterval(τ, sqrt(0.437/(nf-4)), alt, conf)
+	}
+	return res, nil
+}
+
+// tieGroups returns the sizes of the groups of tied values in x.
+func tieGroups(x []float64) []float64 {
+	s := sortedCopy(x)
+	var g []float64
+	for i := 0; i < len(s); {
+		j := i
+		for j < len(s) && s[j] == s[i] {
+			j++
+		}
+		if j-i > 1 {
+			g = append(g, float64(j-i))
+		}
+		i = j
+	}
+	return g
+}
+
+// kendallPMF returns the null distribution of the number of concordant
+// pairs among n untied observations, which is that of the number of
+// inversions of a random permutation.
+func kendallPMF(n int) []float64 {
+	p := []float64{1}
+	for k := 2; k <= n; k++ {
+		// the k-th element adds 0 ... k-1 inversions with equal probability
+		q := make([]float64, len(p)+k-1)
+		var s float64
+		for i := range q {
+			if i < len(p) {
+				s += p[i]
+			}
+			if i >= k {
+				s -= p[i-k]
+			}
+			q[i] = s / float64(k)
+		}
+		p = q
+	}
+	return p
+}
+
+// PartialCorrelationTest is the test that the partial correlation of x and
+// y given the control variables z is zero: the Pearson correlation of
+// what is left of x and y after linear regression on z. Each z[k] is one
+// control variable, observed alongside x and y. The t statistic has
+// n - 2 - len(z) degrees of freedom and the Fisher z-transform interval a
+// standard error of 1/√(n - 3 - len(z)).
+func PartialCorrelationTest(x, y []float64, z [][]float64, alt Alternative, conf float64) (CorrelationResult, error) {
+	if err := checkMeanTest("PartialCorrelationTest", alt, conf); err != nil {
+		return CorrelationResult{}, err
+	}
+	k := len(z)
+	if err := checkPairs("PartialCorrelationTest", x, y, k+3); err != nil {
+		return CorrelationResult{}, err
+	}
+	vars := append([][]float64{x, y}, z...)
+	for _, v := range z {
+		if err := checkPairs("PartialCorrelationTest", x, v, k+3); err != nil {
+			return CorrelationResult{}, err
+		}
+	}
+	// the partial correlation is -P₀₁ / √(P₀₀ P₁₁) for P the inverse of the
+	// correlation matrix of x, y and z
+	m := len(vars)
+	c := make([][]float64, m)
+	for i := range c {
+		c[i] = make([]float64, m)
+		for j := range c[i] {
+			c[i][j] = pearson(vars[i], vars[j])
+		}
+	}
+	P, err := mx.MakeDenseMatrixStacked(c).Inverse()
+	if err != nil {
+		return CorrelationResult{}, invalidParameter("PartialCorrelationTest: the variables are collinear")
+	}
+	r := -P.Get(0, 1) / sqrt(P.Get(0, 0)*P.Get(1, 1))
+	if math.IsNaN(r) || abs(r) > 1+1e-12 {
+		return CorrelationResult{}, invalidParameter("PartialCorrelationTest: the variables are collinear")
+	}
+	r = math.Max(-1, math.Min(1, r))
+	return correlationT(r, len(x), k, alt, conf), nil
+}
+
+// CorrelationMatrix returns the correlations between the columns of data,
+// data[i][j] being observation i of variable j. Each correlation uses the
+// observations in which both of its variables are present, i.e. not NaN,
+// and is NaN if fewer than two remain or a variable is constant on them.
+func CorrelationMatrix(data [][]float64, m CorrelationMethod) ([][]float64, error) {
+	if m < PearsonCorrelation || m > KendallCorrelation {
+		return nil, invalidParameter("CorrelationMatrix method %d", m)
+	}
+	if len(data) == 0 {
+		return nil, invalidParameter("CorrelationMatrix of no observations")
+	}
+	p := len(data[0])
+	for i, row := range data {
+		if len(row) != p {
+			return nil, dimensionMismatch("CorrelationMatrix row %d has %d columns, want %d", i, len(row), p)
+		}
+	}
+	c := make([][]float64, p)
+	for j := range c {
+		c[j] = make([]float64, p)
+	}
+	for j := 0; j < p; j++ {
+		for k := j; k < p; k++ {
+			var x, y []float64
+			for _, row := range data {
+				if !math.IsNaN(row[j]) && !math.IsNaN(row[k]) {
+					x = append(x, row[j])
+					y = append(y, row[k])
+				}
+			}
+			r := math.NaN()
+			if len(x) >= 2 {
+				switch m {
+				case PearsonCorrelation:
+					r = pearson(x, y)
+				case SpearmanCorrelation:
+					r = pearson(midranks(x), midranks(y))
+				case KendallCorrelation:
+					r, _ = kendall(x, y)
+				}
+			}
+			c[j][k], c[k][j] = r, r
+		}
+	}
+	return c, nil
+}
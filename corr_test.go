@@ -0,0 +1,148 @@
+package stat
+
+import (
+	"math"
+	"testing"
+)
+
+// the examples of R's cor.test, from Hollander and Wolfe
+var (
+	corrX = []float64{44.4, 45.9, 41.9, 53.3, 44.7, 44.1, 50.7, 45.2, 60.1}
+	corrY = []float64{2.6, 3.1, 2.5, 5.0, 3.6, 4.0, 5.2, 2.8, 3.8}
+)
+
+func TestPearsonTest(t *testing.T) {
+	r, err := PearsonTest(corrX, corrY, TwoSided, 0.95)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, "r", r.Estimate, 0.5711816, 1e-7)
+	check(t, "t", r.Statistic, 1.841083, 1e-6)
+	check(t, "DF", r.DF, 7, 0)
+	check(t, "p", r.PValue, 0.1081731, 1e-7)
+	check(t, "lower", r.Lower, -0.1497426, 1e-7)
+	check(t, "upper", r.Upper, 0.8955795, 1e-7)
+
+	g, _ := PearsonTest(corrX, corrY, Greater, 0.95)
+	check(t, "greater p", g.PValue, r.PValue/2, 1e-14)
+	if g.Upper != 1 || g.Lower <= r.Lower {
+		t.Errorf("one-sided interval [%v, %v]", g.Lower, g.Upper)
+	}
+	if _, err := PearsonTest(corrX, corrY[1:], TwoSided, 0.95); err == nil {
+		t.Error("unpaired samples accepted")
+	}
+}
+
+func TestSpearmanTest(t *testing.T) {
+	r, err := SpearmanTest(corrX, corrY, Greater, 0.95)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, "ρ", r.Estimate, 0.6, 1e-14)
+	check(t, "S", r.Statistic, 48, 1e-12)
+	check(t, "p", r.PValue, 0.0484, 1e-4)
+
+	// the permutation distribution sums to 1 and is symmetric about its mean
+	p := spearmanPMF(5)
+	var sum float64
+	for d := range p {
+		sum += p[d]
+		check(t, "symmetry", p[d], p[len(p)-1-d], 1e-15)
+	}
+	check(t, "mass", sum, 1, 1e-14)
+
+	// with ties the t approximation is used
+	x := []float64{1, 2, 2, 4, 5, 6, 7}
+	y := []float64{2, 1, 4, 3, 7, 5, 6}
+	r, _ = SpearmanTest(x, y, TwoSided, 0.95)
+	ρ := pearson(midranks(x), midranks(y))
+	tt := ρ * math.Sqrt(5/(1-ρ*ρ))
+	check(t, "ties p", r.PValue, 2*StudentsT_Survival_At(5, tt), 1e-14)
+}
+
+func TestKendallTest(t *testing.T) {
+	r, err := KendallTest(corrX, corrY, Greater, 0.95)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, "τ", r.Estimate, 0.4444444, 1e-7)
+	check(t, "T", r.Statistic, 26, 0)
+	check(t, "p", r.PValue, 0.05971947, 1e-7)
+
+	// the inversion counts of 4 elements, 1 3 5 6 5 3 1 out of 24
+	for i, c := range []float64{1, 3, 5, 6, 5, 3, 1} {
+		check(t, "kendallPMF", kendallPMF(4)[i], c/24, 1e-15)
+	}
+
+	// τ-b with ties against its definition
+	x := []float64{1, 2, 2, 3, 4, 4, 4, 5}
+	y := []float64{1, 3, 2, 2, 5, 4, 6, 6}
+	τ, S := kendall(x, y)
+	check(t, "S", S, 20, 0)
+	check(t, "τ-b", τ, 20/math.Sqrt((28-4)*(28-2)), 1e-14)
+	r, _ = KendallTest(x, y, TwoSided, 0.95)
+	v := (8.0*7*21-2*1*9-3*2*11-2*(2*1*9))/18 + (2+6)*(2+2)/(2*8.0*7) + 6*0/(9*8.0*7*6)
+	check(t, "z", r.Statistic, 20/math.Sqrt(v), 1e-14)
+}
+
+func TestPartialCorrelationTest(t *testing.T) {
+	rng := NewRNG(3)
+	n := 200
+	x, y, z := make([]float64, n), make([]float64, n), make([]float64, n)
+	for i := range z {
+		z[i] = rng.NormFloat64()
+		x[i] = z[i] + rng.NormFloat64()
+		y[i] = z[i] + rng.NormFloat64()
+	}
+	// x and y are correlated only through z
+	r, err := PartialCorrelationTest(x, y, [][]float64{z}, TwoSided, 0.95)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.PValue < 0.01 {
+		t.Errorf("partial correlation %v has p-value %v", r.Estimate, r.PValue)
+	}
+	if m, _ := PearsonTest(x, y, TwoSided, 0.95); m.PValue > 1e-6 {
+		t.Errorf("marginal correlation %v has p-value %v", m.Estimate, m.PValue)
+	}
+	check(t, "DF", r.DF, float64(n-3), 0)
+
+	// with one control r = (r_xy - r_xz r_yz) / √((1 - r_xz²)(1 - r_yz²))
+	rxy, rxz, ryz := pearson(x, y), pearson(x, z), pearson(y, z)
+	check(t, "r", r.Estimate, (rxy-rxz*ryz)/math.Sqrt((1-rxz*rxz)*(1-ryz*ryz)), 1e-12)
+
+	// with no controls it is the Pearson correlation
+	r0, _ := PartialCorrelationTest(x, y, nil, TwoSided, 0.95)
+	p0, _ := PearsonTest(x, y, TwoSided, 0.95)
+	check(t, "no controls", r0.PValue, p0.PValue, 1e-12)
+}
+
+func TestCorrelationMatrix(t *testing.T) {
+	data := make([][]float64, len(corrX))
+	for i := range data {
+		data[i] = []float64{corrX[i], corrY[i], -corrX[i]}
+	}
+	data[2][1] = math.NaN()
+	for _, m := range []CorrelationMethod{PearsonCorrelation, SpearmanCorrelation, KendallCorrelation} {
+		c, err := CorrelationMatrix(data, m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, "diagonal", c[1][1], 1, 1e-14)
+		check(t, "negated", c[0][2], -1, 1e-14)
+		check(t, "symmetric", c[0][1], c[1][0], 0)
+		// the pair with a missing value drops that observation
+		x := append(append([]float64(nil), corrX[:2]...), corrX[3:]...)
+		y := append(append([]float64(nil), corrY[:2]...), corrY[3:]...)
+		var want float64
+		switch m {
+		case PearsonCorrelation:
+			want = pearson(x, y)
+		case SpearmanCorrelation:
+			want = pearson(midranks(x), midranks(y))
+		default:
+			want, _ = kendall(x, y)
+		}
+		check(t, "pairwise", c[0][1], want, 1e-14)
+	}
+}
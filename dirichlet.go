@@ -1,52 +1,88 @@
 package stat
 
 import (
+	"math"
+
 	. "github.com/ematvey/go-fn/fn"
 )
 
-func Dirichlet_PDF(α []float64) func(θ []float64) float64 {
-	return func(θ []float64) float64 {
-		if len(θ) != len(α) {
-			return 0
-		}
-		l := float64(1.0)
-		totalα := float64(0)
-		for i := 0; i < len(θ); i++ {
-			if θ[i] < 0 || θ[i] > 1 {
-				return 0
-			}
-			l *= pow(θ[i], α[i]-1)
-			l /= Γ(α[i])
-			totalα += α[i]
+// Dirichlet distribution with concentration parameters Alpha
+type DirichletDist struct {
+	Alpha []float64
+}
+
+// NewDirichletDist returns a Dirichlet distribution, checking that there are at
+// least two components and every α[i] > 0.
+func NewDirichletDist(α []float64) (DirichletDist, error) {
+	if len(α) < 2 {
+		return DirichletDist{}, dimensionMismatch("Dirichlet needs at least 2 components, got %d", len(α))
+	}
+	for i, a := range α {
+		if !(a > 0) || math.IsInf(a, 0) {
+			return DirichletDist{}, invalidParameter("Dirichlet α[%d] = %v", i, a)
 		}
-		l *= Γ(totalα)
-		return l
 	}
+	return DirichletDist{α}, nil
 }
-func Dirichlet_LnPDF(α []float64) func(x []float64) float64 {
-	return func(x []float64) float64 {
-		if len(x) != len(α) {
+
+func (d DirichletDist) PDF(θ []float64) float64 {
+	return exp(d.LogPDF(θ))
+}
+
+func (d DirichletDist) LogPDF(θ []float64) float64 {
+	if len(θ) != len(d.Alpha) {
+		return negInf
+	}
+	l := fZero
+	totalα := fZero
+	for i, α := range d.Alpha {
+		if θ[i] < 0 || θ[i] > 1 {
 			return negInf
 		}
-		l := fZero
-		totalα := float64(0)
-		for i := 0; i < len(x); i++ {
-			if x[i] < 0 || x[i] > 1 {
-				return negInf
-			}
-			l += (α[i] - 1) * log(x[i])
-			l -= LnΓ(α[i])
-			totalα += α[i]
-		}
-		l += LnΓ(totalα)
-		return l
+		l += xlogy(α-1, θ[i])
+		l -= LnΓ(α)
+		totalα += α
 	}
+	l += LnΓ(totalα)
+	return l
+}
+
+func (d DirichletDist) Rand() []float64            { return d.RandWith(DefaultRNG) }
+func (d DirichletDist) RandWith(rng RNG) []float64 { return NextDirichletWith(rng, d.Alpha) }
+
+func (d DirichletDist) Mean() []float64 {
+	var α0 float64
+	for _, α := range d.Alpha {
+		α0 += α
+	}
+	μ := make([]float64, len(d.Alpha))
+	for i, α := range d.Alpha {
+		μ[i] = α / α0
+	}
+	return μ
+}
+
+// Cov(θi, θj) = μi (δij - μj) / (α0 + 1)
+func (d DirichletDist) Covariance() [][]float64 {
+	var α0 float64
+	for _, α := range d.Alpha {
+		α0 += α
+	}
+	return categoricalCovariance(d.Mean(), 1/(α0+1))
+}
+
+func Dirichlet_PDF(α []float64) func(θ []float64) float64 {
+	return DirichletDist{α}.PDF
+}
+func Dirichlet_LnPDF(α []float64) func(x []float64) float64 {
+	return DirichletDist{α}.LogPDF
 }
-func NextDirichlet(α []float64) []float64 {
+func NextDirichlet(α []float64) []float64 { return NextDirichletWith(DefaultRNG, α) }
+func NextDirichletWith(rng RNG, α []float64) []float64 {
 	x := make([]float64, len(α))
 	sum := fZero
 	for i := 0; i < len(α); i++ {
-		x[i] = NextGamma(α[i], 1.0)
+		x[i] = NextGammaWith(rng, α[i], 1.0)
 		sum += x[i]
 	}
 	for i := 0; i < len(α); i++ {
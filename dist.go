@@ -0,0 +1,170 @@
+// Distribution interfaces
+
+package stat
+
+import (
+	"math"
+)
+
+// ContinuousDistribution is a univariate distribution over the reals.
+// It bundles what the X_PDF, X_LnPDF, X_CDF, X_InvCDF and X closures of a
+// family provide into a single value that can be passed around.
+//
+// Survival is P(X > x). It and the LogCDF and LogSurvival methods are
+// computed directly rather than from CDF, so they keep their relative
+// accuracy far into the tails. Quantile returns NaN if the numerical
+// inversion of the CDF fails to converge.
+type ContinuousDistribution interface {
+	PDF(x float64) float64
+	LogPDF(x float64) float64
+	CDF(x float64) float64
+	Survival(x float64) float64
+	LogCDF(x float64) float64
+	LogSurvival(x float64) float64
+	Quantile(p float64) float64
+	Rand() float64
+	RandWith(rng RNG) float64
+	Mean() float64
+	Variance() float64
+	Mode() float64
+	Skewness() float64
+	ExKurtosis() float64
+	Entropy() float64
+}
+
+// DiscreteDistribution is a univariate distribution over the integers.
+type DiscreteDistribution interface {
+	PMF(k int64) float64
+	LogPMF(k int64) float64
+	CDF(k int64) float64
+	Survival(k int64) float64
+	LogCDF(k int64) float64
+	LogSurvival(k int64) float64
+	Quantile(p float64) int64
+	Rand() int64
+	RandWith(rng RNG) int64
+	Mean() float64
+	Variance() float64
+	Mode() int64
+	Skewness() float64
+	ExKurtosis() float64
+	Entropy() float64
+}
+
+var (
+	_ ContinuousDistribution = NormalDist{}
+	_ ContinuousDistribution = ExpDist{}
+	_ ContinuousDistribution = UniformDist{}
+	_ ContinuousDistribution = GammaDist{}
+	_ ContinuousDistribution = InvGammaDist{}
+	_ ContinuousDistribution = BetaDist{}
+	_ ContinuousDistribution = XsquareDist{}
+	_ ContinuousDistribution = FDist{}
+	_ ContinuousDistribution = StudentsTDist{}
+	_ ContinuousDistribution = LocationScaleDist{}
+	_ ContinuousDistribution = TruncatedDist{}
+	_ ContinuousDistribution = MixtureDist{}
+	_ ContinuousDistribution = EmpiricalDist{}
+	_ ContinuousDistribution = KDEDist{}
+	_ ContinuousDistribution = KolmogorovDist{}
+	_ ContinuousDistribution = StudentizedRangeDist{}
+
+	_ DiscreteDistribution = BernoulliDist{}
+	_ DiscreteDistribution = BinomialDist{}
+	_ DiscreteDistribution = PoissonDist{}
+	_ DiscreteDistribution = GeometricDist{}
+	_ DiscreteDistribution = NegativeBinomialDist{}
+	_ DiscreteDistribution = RangeDist{}
+	_ DiscreteDistribution = ChoiceDist{}
+	_ DiscreteDistribution = HypergeometricDist{}
+)
+
+// discreteQuantile returns the smallest k in [lo, hi] with cdf(k) >= p. It
+// starts at guess, brackets the answer by doubling steps and then bisects,
+// so it needs O(log |k - guess|) evaluations of cdf.
+func discreteQuantile(cdf func(k int64) float64, p float64, guess, lo, hi int64) int64 {
+	switch {
+	case p <= 0:
+		return lo
+	case p >= 1:
+		return hi
+	}
+	if guess < lo {
+		guess = lo
+	}
+	if guess > hi {
+		guess = hi
+	}
+	// find a < b with cdf(a) < p <= cdf(b), where a = lo-1 stands for cdf = 0
+	var a, b int64
+	if cdf(guess) >= p {
+		b = guess
+		a = b - 1
+		for step := int64(1); a >= lo && cdf(a) >= p; step *= 2 {
+			b = a
+			if a-lo < step {
+				a = lo - 1
+			} else {
+				a -= step
+			}
+		}
+	} else {
+		a = guess
+		b = a + 1
+		for step := int64(1); b < hi && cdf(b) < p; step *= 2 {
+			a = b
+			if hi-b < step {
+				b = hi
+			} else {
+				b += step
+			}
+		}
+	}
+	for b-a > 1 {
+		m := a + (b-a)/2
+		if cdf(m) >= p {
+			b = m
+		} else {
+			a = m
+		}
+	}
+	return b
+}
+
+// quantileGuess returns the Cornish-Fisher approximation to the p-quantile of
+// a distribution with mean μ, standard deviation σ and skewness γ, rounded to
+// an integer and clamped to [lo, hi].
+func quantileGuess(μ, σ, γ, p float64, lo, hi int64) int64 {
+	z := Z_InvCDF_For(p)
+	x := math.Floor(μ + σ*(z+γ*(z*z-1)/6))
+	switch {
+	case !(x > float64(lo)):
+		return lo
+	case x >= float64(hi):
+		return hi
+	}
+	return int64(x)
+}
+
+// discreteEntropy sums -p log p over [lo, hi], walking outwards from the mode
+// until the terms no longer contribute.
+func discreteEntropy(pmf func(k int64) float64, mode, lo, hi int64) float64 {
+	var h float64
+	for k := mode; k <= hi; k++ {
+		p := pmf(k)
+		t := -xlogy(p, p)
+		h += t
+		if p == 0 || (k > mode && t <= 1e-17*h) || k == hi {
+			break
+		}
+	}
+	for k := mode - 1; k >= lo; k-- {
+		p := pmf(k)
+		t := -xlogy(p, p)
+		h += t
+		if p == 0 || t <= 1e-17*h {
+			break
+		}
+	}
+	return h
+}
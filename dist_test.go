@@ -0,0 +1,261 @@
+package stat
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+var continuousDists = []ContinuousDistribution{
+	NormalDist{1, 2},
+	ExpDist{1.5},
+	UniformDist{-1, 3},
+	GammaDist{2.5, 2},
+	InvGammaDist{3, 2},
+	BetaDist{2, 5},
+	XsquareDist{4},
+	FDist{5, 20},
+	StudentsTDist{6},
+}
+
+var discreteDists = []DiscreteDistribution{
+	BernoulliDist{0.3},
+	BinomialDist{0.3, 20},
+	PoissonDist{4.5},
+	GeometricDist{0.25},
+	NegativeBinomialDist{0.4, 3},
+	RangeDist{7},
+	ChoiceDist{[]float64{0.2, 0.5, 0.3}},
+	HypergeometricDist{30, 12, 10},
+}
+
+func simpson(f func(float64) float64, a, b float64, n int) float64 {
+	h := (b - a) / float64(n)
+	s := f(a) + f(b)
+	for i := 1; i < n; i++ {
+		w := 2.0
+		if i%2 == 1 {
+			w = 4
+		}
+		s += w * f(a+float64(i)*h)
+	}
+	return s * h / 3
+}
+
+func TestContinuousDistributions(t *testing.T) {
+	for _, d := range continuousDists {
+		lo, hi := d.Quantile(0.1), d.Quantile(0.9)
+		for _, p := range []float64{0.1, 0.5, 0.9} {
+			if c := d.CDF(d.Quantile(p)); math.Abs(c-p) > 1e-3 {
+				t.Errorf("%#v: CDF(Quantile(%v)) = %v", d, p, c)
+			}
+		}
+		if mass := simpson(d.PDF, lo, hi, 2000); math.Abs(mass-0.8) > 1e-3 {
+			t.Errorf("%#v: PDF integrates to %v over the 10-90%% range", d, mass)
+		}
+		x := d.Quantile(0.3)
+		if math.Abs(math.Log(d.PDF(x))-d.LogPDF(x)) > 1e-9 {
+			t.Errorf("%#v: LogPDF disagrees with PDF at %v", d, x)
+		}
+	}
+}
+
+func TestDiscreteDistributions(t *testing.T) {
+	for _, d := range discreteDists {
+		hi := d.Quantile(1 - 1e-12)
+		var sum, mean, sq float64
+		for k := int64(0); k <= hi; k++ {
+			p := d.PMF(k)
+			sum += p
+			mean += float64(k) * p
+			sq += float64(k*k) * p
+			if math.Abs(sum-d.CDF(k)) > 1e-9 {
+				t.Errorf("%#v: CDF(%d) = %v, sum of PMF = %v", d, k, d.CDF(k), sum)
+			}
+			if p > 0 && math.Abs(math.Log(p)-d.LogPMF(k)) > 1e-9 {
+				t.Errorf("%#v: LogPMF(%d) disagrees with PMF", d, k)
+			}
+		}
+		if math.Abs(mean-d.Mean()) > 1e-6 {
+			t.Errorf("%#v: Mean() = %v, expected %v", d, d.Mean(), mean)
+		}
+		if v := sq - mean*mean; math.Abs(v-d.Variance()) > 1e-6 {
+			t.Errorf("%#v: Variance() = %v, expected %v", d, d.Variance(), v)
+		}
+	}
+}
+
+func TestMoments(t *testing.T) {
+	for _, d := range continuousDists {
+		lo, hi := d.Quantile(1e-10), d.Quantile(1-1e-10)
+		μ, σ := d.Mean(), math.Sqrt(d.Variance())
+		moment := func(n float64) float64 {
+			return simpson(func(x float64) float64 { return math.Pow((x-μ)/σ, n) * d.PDF(x) }, lo, hi, 200000)
+		}
+		if s := moment(3); math.Abs(s-d.Skewness()) > 1e-2 {
+			t.Errorf("%#v: Skewness() = %v, expected %v", d, d.Skewness(), s)
+		}
+		if k := moment(4) - 3; math.Abs(k-d.ExKurtosis()) > 0.05*math.Max(1, math.Abs(k)) {
+			t.Errorf("%#v: ExKurtosis() = %v, expected %v", d, d.ExKurtosis(), k)
+		}
+		h := simpson(func(x float64) float64 { return -d.PDF(x) * d.LogPDF(x) }, lo, hi, 200000)
+		if math.Abs(h-d.Entropy()) > 1e-4 {
+			t.Errorf("%#v: Entropy() = %v, expected %v", d, d.Entropy(), h)
+		}
+		if m := d.Mode(); d.PDF(m) < d.PDF(m-1e-3) || d.PDF(m) < d.PDF(m+1e-3) {
+			t.Errorf("%#v: Mode() = %v is not a local maximum", d, m)
+		}
+	}
+	for _, d := range discreteDists {
+		hi := d.Quantile(1 - 1e-15)
+		μ, σ := d.Mean(), math.Sqrt(d.Variance())
+		var m3, m4, h float64
+		for k := int64(0); k <= hi; k++ {
+			p, z := d.PMF(k), (float64(k)-μ)/σ
+			m3 += z * z * z * p
+			m4 += z * z * z * z * p
+			if p > 0 {
+				h -= p * math.Log(p)
+			}
+		}
+		if math.Abs(m3-d.Skewness()) > 1e-6 {
+			t.Errorf("%#v: Skewness() = %v, expected %v", d, d.Skewness(), m3)
+		}
+		if math.Abs(m4-3-d.ExKurtosis()) > 1e-6 {
+			t.Errorf("%#v: ExKurtosis() = %v, expected %v", d, d.ExKurtosis(), m4-3)
+		}
+		if math.Abs(h-d.Entropy()) > 1e-9 {
+			t.Errorf("%#v: Entropy() = %v, expected %v", d, d.Entropy(), h)
+		}
+		if m := d.Mode(); d.PMF(m) < d.PMF(m-1) || d.PMF(m) < d.PMF(m+1) {
+			t.Errorf("%#v: Mode() = %v is not a local maximum", d, m)
+		}
+	}
+}
+
+func TestDigamma(t *testing.T) {
+	const γ = 0.5772156649015329
+	for _, c := range []struct{ x, ψ float64 }{
+		{1, -γ},
+		{0.5, -γ - 2*math.Ln2},
+		{4, 1 + 1.0/2 + 1.0/3 - γ},
+		{-0.5, 2 - γ - 2*math.Ln2},
+		{100, 4.600161852738087},
+	} {
+		if ψ := digamma(c.x); math.Abs(ψ-c.ψ) > 1e-12 {
+			t.Errorf("digamma(%v) = %v, expected %v", c.x, ψ, c.ψ)
+		}
+	}
+	for _, c := range []struct{ x, ψ1 float64 }{
+		{1, π * π / 6},
+		{0.5, π * π / 2},
+		{2, π*π/6 - 1},
+		{-0.5, π*π/2 + 4},
+		{100, 0.010050166663333571},
+	} {
+		if ψ1 := trigamma(c.x); math.Abs(ψ1-c.ψ1) > 1e-12*c.ψ1 {
+			t.Errorf("trigamma(%v) = %v, expected %v", c.x, ψ1, c.ψ1)
+		}
+	}
+}
+
+func TestDiscreteQuantiles(t *testing.T) {
+	ds := append([]DiscreteDistribution{
+		PoissonDist{1e6},
+		PoissonDist{1e-3},
+		BinomialDist{0.999, 1000000},
+		NegativeBinomialDist{1e-4, 2.5},
+		GeometricDist{1e-9},
+		RangeDist{10},
+	}, discreteDists...)
+	for _, d := range ds {
+		for _, p := range []float64{1e-300, 1e-12, 0.001, 0.1, 0.3, 0.5, 0.9, 0.999, 1 - 1e-12} {
+			k := d.Quantile(p)
+			if d.CDF(k) < p || d.CDF(k-1) >= p {
+				t.Errorf("%#v: Quantile(%v) = %d, CDF(%d) = %v, CDF(%d) = %v",
+					d, p, k, k, d.CDF(k), k-1, d.CDF(k-1))
+			}
+		}
+	}
+	if k := Poisson_InvCDF_For(4.5, 0.999); k != 12 {
+		t.Errorf("Poisson_InvCDF_For(4.5, 0.999) = %d, expected 12", k)
+	}
+}
+
+func TestSampleMeans(t *testing.T) {
+	const n = 100000
+	for _, d := range continuousDists {
+		var s float64
+		for i := 0; i < n; i++ {
+			s += d.Rand()
+		}
+		if se := math.Sqrt(d.Variance() / n); math.Abs(s/n-d.Mean()) > 5*se {
+			t.Errorf("%#v: sample mean %v, expected %v", d, s/n, d.Mean())
+		}
+	}
+	for _, d := range discreteDists {
+		var s float64
+		for i := 0; i < n; i++ {
+			s += float64(d.Rand())
+		}
+		if se := math.Sqrt(d.Variance() / n); math.Abs(s/n-d.Mean()) > 5*se {
+			t.Errorf("%#v: sample mean %v, expected %v", d, s/n, d.Mean())
+		}
+	}
+}
+
+func TestPoissonLargeRate(t *testing.T) {
+	// the product of uniforms underflows above λ ≈ 745
+	const n = 100000
+	rng := NewRNG(15)
+	for _, λ := range []float64{12, 1000, 1e4} {
+		var a Accumulator
+		for i := 0; i < n; i++ {
+			a.Add(float64(NextPoissonWith(rng, λ)))
+		}
+		if se := math.Sqrt(λ / n); math.Abs(a.Mean()-λ) > 5*se {
+			t.Errorf("λ = %v: sample mean %v", λ, a.Mean())
+		}
+		// the variance of the sample variance is about 2λ²/n
+		if se := λ * math.Sqrt(2.0/n); math.Abs(a.Variance()-λ) > 5*se {
+			t.Errorf("λ = %v: sample variance %v", λ, a.Variance())
+		}
+	}
+}
+
+func TestConstructorErrors(t *testing.T) {
+	nan := math.NaN()
+	errs := []error{}
+	add := func(_ interface{}, err error) { errs = append(errs, err) }
+	add(NewNormalDist(0, 0))
+	add(NewNormalDist(nan, 1))
+	add(NewExpDist(-1))
+	add(NewUniformDist(1, 1))
+	add(NewGammaDist(0, 1))
+	add(NewInvGammaDist(1, nan))
+	add(NewBetaDist(1, -2))
+	add(NewXsquareDist(0))
+	add(NewFDist(1, 0))
+	add(NewStudentsTDist(-3))
+	add(NewBernoulliDist(1.5))
+	add(NewBinomialDist(0.5, -1))
+	add(NewPoissonDist(-0.1))
+	add(NewGeometricDist(0))
+	add(NewNegativeBinomialDist(0.5, 0))
+	add(NewRangeDist(0))
+	add(NewChoiceDist([]float64{0.5, 0.6}))
+	add(NewHypergeometricDist(10, 11, 5))
+	add(NewMultinomialDist([]float64{0.5, 0.5}, -1))
+	add(NewDirichletDist([]float64{1, 0}))
+	for i, err := range errs {
+		if !errors.Is(err, ErrInvalidParameter) {
+			t.Errorf("case %d: expected ErrInvalidParameter, got %v", i, err)
+		}
+	}
+	if _, err := NewNormalDist(1, 2); err != nil {
+		t.Error(err)
+	}
+	if _, err := NewDirichletDist([]float64{1}); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("expected ErrDimensionMismatch, got %v", err)
+	}
+}
@@ -0,0 +1,301 @@
+// Fitting Gaussian mixtures by expectation-maximization
+
+package stat
+
+import (
+	"math"
+	"sort"
+
+	. "github.com/skelterjohn/go.matrix"
+)
+
+const (
+	emMaxIter = 1000
+	emTol     = 1e-10 // relative change in the log-likelihood
+	emFloor   = 1e-6  // variance floor, relative to the variance of the data
+)
+
+// FitNormalMixture estimates a mixture of k Normal distributions from x by
+// expectation-maximization. The means are seeded by k-means++ from the
+// default source; see FitNormalMixtureWith.
+func FitNormalMixture(x []float64, k int) (MixtureDist, error) {
+	return FitNormalMixtureWith(DefaultRNG, x, k)
+}
+
+// FitNormalMixtureWith is FitNormalMixture seeded from rng. The components of
+// the result are NormalDist values sorted by mean. Each variance is kept
+// above a small fraction of the variance of x, so that a component cannot
+// collapse onto a single point. If the log-likelihood has not converged
+// after emMaxIter iterations, the last estimate is returned with an error
+// wrapping ErrNoConvergence.
+func FitNormalMixtureWith(rng RNG, x []float64, k int) (MixtureDist, error) {
+	n := len(x)
+	if k < 1 || n < k {
+		return MixtureDist{}, invalidParameter("mixture of %d components from %d points", k, n)
+	}
+	var m, s2 float64
+	for i, xi := range x {
+		if !isFinite(xi) {
+			return MixtureDist{}, invalidParameter("x[%d] = %v", i, xi)
+		}
+		m += xi
+	}
+	m /= float64(n)
+	for _, xi := range x {
+		s2 += (xi - m) * (xi - m)
+	}
+	if s2 /= float64(n); s2 == 0 {
+		return MixtureDist{}, invalidParameter("mixture data are constant")
+	}
+	floor := emFloor * s2
+
+	points := make([][]float64, n)
+	for i, xi := range x {
+		points[i] = []float64{xi}
+	}
+	w := make([]float64, k)
+	μ := kMeansPlusPlus(rng, points, k)
+	σ2 := make([]float64, k)
+	for j := range w {
+		w[j] = 1 / float64(k)
+		σ2[j] = s2
+	}
+
+	r := make([][]float64, n)
+	for i := range r {
+		r[i] = make([]float64, k)
+	}
+	l := make([]float64, k)
+	ll := negInf
+	var err error
+	for iter := 0; ; iter++ {
+		// E step
+		var llNew float64
+		for i, xi := range x {
+			for j := range l {
+				l[j] = log(w[j]) + NormalDist{μ[j][0], sqrt(σ2[j])}.LogPDF(xi)
+			}
+			llNew += emResponsibilities(r[i], l)
+		}
+		if abs(llNew-ll) <= emTol*abs(llNew) {
+			break
+		}
+		if iter == emMaxIter {
+			err = noConvergence("Normal mixture log-likelihood still changing after %d iterations", emMaxIter)
+			break
+		}
+		ll = llNew
+
+		// M step
+		for j := 0; j < k; j++ {
+			var nj, sx float64
+			for i, xi := range x {
+				nj += r[i][j]
+				sx += r[i][j] * xi
+			}
+			w[j] = nj / float64(n)
+			if nj < 1e-300 {
+				continue // keep the parameters of a component that has lost all its points
+			}
+			μj := sx / nj
+			var ss float64
+			for i, xi := range x {
+				ss += r[i][j] * (xi - μj) * (xi - μj)
+			}
+			μ[j][0], σ2[j] = μj, math.Max(ss/nj, floor)
+		}
+	}
+
+	order := make([]int, k)
+	for j := range order {
+		order[j] = j
+	}
+	sort.Slice(order, func(a, b int) bool { return μ[order[a]][0] < μ[order[b]][0] })
+	d := MixtureDist{make([]float64, k), make([]ContinuousDistribution, k)}
+	for a, j := range order {
+		d.Weights[a] = w[j]
+		d.Components[a] = NormalDist{μ[j][0], sqrt(σ2[j])}
+	}
+	return d, err
+}
+
+// FitMVNormalMixture estimates a mixture of k multivariate Normal
+// distributions from the rows of X by expectation-maximization, seeded from
+// the default source; see FitMVNormalMixtureWith.
+func FitMVNormalMixture(X *DenseMatrix, k int) (*MVNormalMixtureDist, error) {
+	return FitMVNormalMixtureWith(DefaultRNG, X, k)
+}
+
+// FitMVNormalMixtureWith is FitMVNormalMixture seeded from rng. Each
+// covariance has a small multiple of the identity added, scaled by the
+// average variance of the columns of X, which keeps it positive definite.
+// Non-convergence is reported as by FitNormalMixtureWith.
+func FitMVNormalMixtureWith(rng RNG, X *DenseMatrix, k int) (*MVNormalMixtureDist, error) {
+	n, p := X.Rows(), X.Cols()
+	if k < 1 || n < k || p < 1 {
+		return nil, invalidParameter("mixture of %d components from %d points", k, n)
+	}
+	points := make([][]float64, n)
+	cols := make([]*DenseMatrix, n)
+	mean := make([]float64, p)
+	for i := range points {
+		points[i] = make([]float64, p)
+		for a := range points[i] {
+			v := X.Get(i, a)
+			if !isFinite(v) {
+				return nil, invalidParameter("X[%d, %d] = %v", i, a, v)
+			}
+			points[i][a] = v
+			mean[a] += v / float64(n)
+		}
+		cols[i] = MakeDenseMatrix(points[i], p, 1)
+	}
+	var tr float64
+	for _, x := range points {
+		for a := range x {
+			tr += (x[a] - mean[a]) * (x[a] - mean[a]) / float64(n)
+		}
+	}
+	if tr == 0 {
+		return nil, invalidParameter("mixture data are constant")
+	}
+	floor := emFloor * tr / float64(p)
+
+	w := make([]float64, k)
+	μ := kMeansPlusPlus(rng, points, k)
+	Σ := make([][][]float64, k)
+	for j := range w {
+		w[j] = 1 / float64(k)
+		Σ[j] = make([][]float64, p)
+		for a := range Σ[j] {
+			Σ[j][a] = make([]float64, p)
+			Σ[j][a][a] = tr / float64(p)
+		}
+	}
+
+	comps := make([]*MVNormalDist, k)
+	build := func() error {
+		for j := range comps {
+			c, err := NewMVNormalDist(MakeDenseMatrix(append([]float64(nil), μ[j]...), p, 1), MakeDenseMatrixStacked(Σ[j]))
+			if err != nil {
+				return err
+			}
+			comps[j] = c
+		}
+		return nil
+	}
+	if err := build(); err != nil {
+		return nil, err
+	}
+
+	r := make([][]float64, n)
+	for i := range r {
+		r[i] = make([]float64, k)
+	}
+	l := make([]float64, k)
+	ll := negInf
+	var err error
+	for iter := 0; ; iter++ {
+		var llNew float64
+		for i := range points {
+			for j, c := range comps {
+				l[j] = log(w[j]) + c.LogPDF(cols[i])
+			}
+			llNew += emResponsibilities(r[i], l)
+		}
+		if abs(llNew-ll) <= emTol*abs(llNew) {
+			break
+		}
+		if iter == emMaxIter {
+			err = noConvergence("MVNormal mixture log-likelihood still changing after %d iterations", emMaxIter)
+			break
+		}
+		ll = llNew
+
+		for j := 0; j < k; j++ {
+			var nj float64
+			μj := make([]float64, p)
+			for i, x := range points {
+				nj += r[i][j]
+				for a := range x {
+					μj[a] += r[i][j] * x[a]
+				}
+			}
+			w[j] = nj / float64(n)
+			if nj < 1e-300 {
+				continue
+			}
+			for a := range μj {
+				μj[a] /= nj
+			}
+			for a := 0; a < p; a++ {
+				for b := 0; b <= a; b++ {
+					var s float64
+					for i, x := range points {
+						s += r[i][j] * (x[a] - μj[a]) * (x[b] - μj[b])
+					}
+					Σ[j][a][b], Σ[j][b][a] = s/nj, s/nj
+				}
+				Σ[j][a][a] += floor
+			}
+			μ[j] = μj
+		}
+		if err := build(); err != nil {
+			return nil, err
+		}
+	}
+	return &MVNormalMixtureDist{w, comps}, err
+}
+
+// emResponsibilities normalizes the log joint densities l into posterior
+// component probabilities r and returns the log-likelihood of the point.
+func emResponsibilities(r, l []float64) float64 {
+	lse := logSumExp(l)
+	for j := range l {
+		r[j] = math.Exp(l[j] - lse)
+	}
+	return lse
+}
+
+// kMeansPlusPlus picks k of the points as initial centres, each with
+// probability proportional to its squared distance from the nearest centre
+// already chosen.
+func kMeansPlusPlus(rng RNG, points [][]float64, k int) [][]float64 {
+	n := len(points)
+	centres := [][]float64{append([]float64(nil), points[rng.Int63n(int64(n))]...)}
+	d2 := make([]float64, n)
+	for i := range d2 {
+		d2[i] = math.Inf(1)
+	}
+	for len(centres) < k {
+		var sum float64
+		last := centres[len(centres)-1]
+		for i, x := range points {
+			var d float64
+			for a := range x {
+				d += (x[a] - last[a]) * (x[a] - last[a])
+			}
+			d2[i] = math.Min(d2[i], d)
+			sum += d2[i]
+		}
+		var next int
+		if sum > 0 {
+			// draw in proportion to d2; if rounding leaves u beyond the
+			// last weight, the last point with a positive one is taken
+			u := rng.Float64() * sum
+			for i, di := range d2 {
+				if di > 0 {
+					next = i
+					if u < di {
+						break
+					}
+					u -= di
+				}
+			}
+		} else {
+			next = int(rng.Int63n(int64(n))) // fewer distinct points than k
+		}
+		centres = append(centres, append([]float64(nil), points[next]...))
+	}
+	return centres
+}
@@ -0,0 +1,198 @@
+// Empirical distribution of observed data
+
+package stat
+
+import (
+	"math"
+	"sort"
+)
+
+// EmpiricalDist puts mass 1/n on each of n observations. It must be created
+// by NewEmpiricalDist, which keeps a sorted copy of the data.
+//
+// The distribution is discrete, so PDF and LogPDF return the probability
+// mass at x rather than a density.
+type EmpiricalDist struct {
+	x []float64
+}
+
+// NewEmpiricalDist returns the empirical distribution of x, checking that x
+// is non-empty and contains no NaN.
+func NewEmpiricalDist(x []float64) (EmpiricalDist, error) {
+	if len(x) == 0 {
+		return EmpiricalDist{}, invalidParameter("empirical distribution of no data")
+	}
+	s := make([]float64, len(x))
+	for i, xi := range x {
+		if math.IsNaN(xi) {
+			return EmpiricalDist{}, invalidParameter("x[%d] is NaN", i)
+		}
+		s[i] = xi
+	}
+	sort.Float64s(s)
+	return EmpiricalDist{s}, nil
+}
+
+// Len returns the number of observations.
+func (d EmpiricalDist) Len() int { return len(d.x) }
+
+// below returns the number of observations < x, and upTo those <= x.
+func (d EmpiricalDist) below(x float64) int { return sort.SearchFloat64s(d.x, x) }
+func (d EmpiricalDist) upTo(x float64) int {
+	return sort.Search(len(d.x), func(i int) bool { return d.x[i] > x })
+}
+
+func (d EmpiricalDist) n() float64 { return float64(len(d.x)) }
+
+func (d EmpiricalDist) PDF(x float64) float64    { return float64(d.upTo(x)-d.below(x)) / d.n() }
+func (d EmpiricalDist) LogPDF(x float64) float64 { return log(d.PDF(x)) }
+func (d EmpiricalDist) CDF(x float64) float64    { return float64(d.upTo(x)) / d.n() }
+
+func (d EmpiricalDist) Survival(x float64) float64 {
+	return float64(len(d.x)-d.upTo(x)) / d.n()
+}
+
+func (d EmpiricalDist) LogCDF(x float64) float64      { return log(d.CDF(x)) }
+func (d EmpiricalDist) LogSurvival(x float64) float64 { return log(d.Survival(x)) }
+
+// Quantile returns the smallest observation x with CDF(x) >= p, which is
+// Hyndman-Fan type 1.
+func (d EmpiricalDist) Quantile(p float64) float64 { return d.QuantileType(p, 1) }
+
+// QuantileType returns the p-quantile of the observations by definition t of
+// Hyndman and Fan (1996), 1 to 9. Types 1 to 3 return observations; types 4
+// to 9 interpolate between them, and type 7 is the default of R and NumPy.
+// It returns NaN for p outside [0, 1] or an unknown type.
+func (d EmpiricalDist) QuantileType(p float64, t int) float64 {
+	if !isProbability(p) {
+		return math.NaN()
+	}
+	n := d.n()
+	var m float64
+	switch t {
+	case 1, 2, 4:
+		m = 0
+	case 3:
+		m = -0.5
+	case 5:
+		m = 0.5
+	case 6:
+		m = p
+	case 7:
+		m = 1 - p
+	case 8:
+		m = (p + 1) / 3
+	case 9:
+		m = p/4 + 3.0/8
+	default:
+		return math.NaN()
+	}
+	// x_j is the j-th smallest observation, and the quantile lies between
+	// x_j and x_{j+1}; fuzz absorbs rounding in np + m, as R does
+	const fuzz = 4 * epsilon
+	h := n*p + m
+	j := math.Floor(h + fuzz)
+	g := h - j
+	if abs(g) < fuzz {
+		g = 0
+	}
+	var γ float64
+	switch t {
+	case 1:
+		γ = step(g > 0)
+	case 2:
+		γ = 1 - 0.5*step(g == 0)
+	case 3:
+		γ = step(g > 0 || math.Mod(j, 2) != 0)
+	default:
+		γ = g
+	}
+	lo, hi := d.order(j), d.order(j+1)
+	if γ == 0 || lo == hi {
+		return lo
+	}
+	return (1-γ)*lo + γ*hi
+}
+
+// order returns the j-th smallest observation, clamping j to [1, n].
+func (d EmpiricalDist) order(j float64) float64 {
+	switch {
+	case j < 1:
+		return d.x[0]
+	case j > d.n():
+		return d.x[len(d.x)-1]
+	}
+	return d.x[int(j)-1]
+}
+
+func step(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func (d EmpiricalDist) Rand() float64 { return d.RandWith(DefaultRNG) }
+
+// RandWith resamples one observation uniformly.
+func (d EmpiricalDist) RandWith(rng RNG) float64 {
+	return d.x[NextRangeWith(rng, int64(len(d.x)))]
+}
+
+func (d EmpiricalDist) Mean() (μ float64) {
+	for _, xi := range d.x {
+		μ += xi
+	}
+	return μ / d.n()
+}
+
+// Variance is the variance of the distribution, dividing by n rather than
+// n-1.
+func (d EmpiricalDist) Variance() float64 { return d.centralMoment(2) }
+
+func (d EmpiricalDist) Skewness() float64 {
+	return d.centralMoment(3) / pow(d.Variance(), 1.5)
+}
+
+func (d EmpiricalDist) ExKurtosis() float64 {
+	v := d.Variance()
+	return d.centralMoment(4)/(v*v) - 3
+}
+
+func (d EmpiricalDist) centralMoment(k float64) (m float64) {
+	μ := d.Mean()
+	for _, xi := range d.x {
+		m += pow(xi-μ, k)
+	}
+	return m / d.n()
+}
+
+// Mode returns the smallest of the most frequent observations.
+func (d EmpiricalDist) Mode() float64 {
+	best, count := d.x[0], 0
+	for i := 0; i < len(d.x); {
+		j := i
+		for j < len(d.x) && d.x[j] == d.x[i] {
+			j++
+		}
+		if j-i > count {
+			best, count = d.x[i], j-i
+		}
+		i = j
+	}
+	return best
+}
+
+// Entropy is the entropy of the probability masses of the distinct values.
+func (d EmpiricalDist) Entropy() (h float64) {
+	for i := 0; i < len(d.x); {
+		j := i
+		for j < len(d.x) && d.x[j] == d.x[i] {
+			j++
+		}
+		p := float64(j-i) / d.n()
+		h -= p * log(p)
+		i = j
+	}
+	return
+}
@@ -0,0 +1,76 @@
+package stat
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestEmpiricalQuantileTypes(t *testing.T) {
+	d, err := NewEmpiricalDist([]float64{3, 1, 4, 1, 5, 9, 2, 6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// sorted: 1 1 2 3 4 5 6 9; values as given by R's quantile(x, p, type = t)
+	for _, c := range []struct {
+		p    float64
+		want [9]float64
+	}{
+		{0.25, [9]float64{1, 1.5, 1, 1, 1.5, 1.25, 1.75, 1.4166666666666667, 1.4375}},
+		{0.5, [9]float64{3, 3.5, 3, 3, 3.5, 3.5, 3.5, 3.5, 3.5}},
+		{0, [9]float64{1, 1, 1, 1, 1, 1, 1, 1, 1}},
+		{1, [9]float64{9, 9, 9, 9, 9, 9, 9, 9, 9}},
+	} {
+		for typ := 1; typ <= 9; typ++ {
+			if got := d.QuantileType(c.p, typ); abs(got-c.want[typ-1]) > 1e-14 {
+				t.Errorf("type %d, p = %v: got %v, want %v", typ, c.p, got, c.want[typ-1])
+			}
+		}
+	}
+
+	// quantile(1:10, 0.1, type = t); np is not exact in floating point
+	x := make([]float64, 10)
+	for i := range x {
+		x[i] = float64(i + 1)
+	}
+	e, _ := NewEmpiricalDist(x)
+	for typ, want := range []float64{1, 1.5, 1, 1, 1.5, 1.1, 1.9, 1.3666666666666667, 1.4} {
+		if got := e.QuantileType(0.1, typ+1); abs(got-want) > 1e-14 {
+			t.Errorf("1:10 type %d: got %v, want %v", typ+1, got, want)
+		}
+	}
+	if !math.IsNaN(d.QuantileType(0.5, 10)) || !math.IsNaN(d.QuantileType(1.5, 7)) {
+		t.Error("invalid type or probability did not give NaN")
+	}
+}
+
+func TestEmpirical(t *testing.T) {
+	d, _ := NewEmpiricalDist([]float64{3, 1, 4, 1, 5, 9, 2, 6})
+	check(t, "CDF(1)", d.CDF(1), 0.25, 0)
+	check(t, "CDF(0.5)", d.CDF(0.5), 0, 0)
+	check(t, "Survival(4)", d.Survival(4), 0.375, 0)
+	check(t, "PDF(1)", d.PDF(1), 0.25, 0)
+	check(t, "PDF(1.5)", d.PDF(1.5), 0, 0)
+	check(t, "Mean", d.Mean(), 3.875, 1e-15)
+	check(t, "Variance", d.Variance(), 6.609375, 1e-15)
+	check(t, "Mode", d.Mode(), 1, 0)
+	check(t, "Entropy", d.Entropy(), -(0.25*math.Log(0.25) + 6*0.125*math.Log(0.125)), 1e-15)
+	for _, p := range []float64{0.1, 0.25, 0.3, 0.99} {
+		if x := d.Quantile(p); d.CDF(x) < p || d.CDF(math.Nextafter(x, -1)) >= p {
+			t.Errorf("Quantile(%v) = %v is not the generalized inverse of the CDF", p, x)
+		}
+	}
+
+	rng := NewRNG(1)
+	counts := map[float64]int{}
+	for i := 0; i < 8000; i++ {
+		counts[d.RandWith(rng)]++
+	}
+	if c := counts[1]; c < 1800 || c > 2200 {
+		t.Errorf("resampled 1 %d times of 8000, expected about 2000", c)
+	}
+
+	if _, err := NewEmpiricalDist(nil); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("expected ErrInvalidParameter, got %v", err)
+	}
+}
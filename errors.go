@@ -0,0 +1,39 @@
+// Errors reported by the package
+
+package stat
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrInvalidParameter is returned when a distribution parameter lies
+	// outside its domain, e.g. a non-positive standard deviation.
+	ErrInvalidParameter = errors.New("stat: invalid parameter")
+
+	// ErrDimensionMismatch is returned when vector or matrix parameters
+	// do not have compatible shapes.
+	ErrDimensionMismatch = errors.New("stat: dimension mismatch")
+
+	// ErrNoConvergence is returned when an iterative method exhausts its
+	// iterations without meeting its tolerance.
+	ErrNoConvergence = errors.New("stat: no convergence")
+)
+
+func invalidParameter(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidParameter, fmt.Sprintf(format, args...))
+}
+
+func dimensionMismatch(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrDimensionMismatch, fmt.Sprintf(format, args...))
+}
+
+func noConvergence(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrNoConvergence, fmt.Sprintf(format, args...))
+}
+
+// isProbability reports whether 0 <= ρ <= 1, rejecting NaN.
+func isProbability(ρ float64) bool {
+	return ρ >= 0 && ρ <= 1
+}
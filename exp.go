@@ -1,27 +1,79 @@
 package stat
 
 import (
-	"math/rand"
+	"math"
 )
 
-func Exp_PDF(λ float64) func(x float64) float64 {
-	return func(x float64) float64 {
-		if x < 0 {
-			return 0
-		}
-		return λ * NextExp(-1*λ*x)
+// Exponential distribution with rate Lambda
+type ExpDist struct {
+	Lambda float64
+}
+
+// NewExpDist returns an Exponential distribution, checking that λ > 0.
+func NewExpDist(λ float64) (ExpDist, error) {
+	if !(λ > 0) || math.IsInf(λ, 0) {
+		return ExpDist{}, invalidParameter("Exp λ = %v", λ)
 	}
+	return ExpDist{λ}, nil
 }
 
-func Exp_LnPDF(λ float64) func(x float64) float64 {
-	return func(x float64) float64 {
-		if x < 0 {
-			return negInf
-		}
-		return log(λ) - λ*x
+func (d ExpDist) PDF(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	return d.Lambda * exp(-d.Lambda*x)
+}
+
+func (d ExpDist) LogPDF(x float64) float64 {
+	if x < 0 {
+		return negInf
 	}
+	return log(d.Lambda) - d.Lambda*x
+}
+
+func (d ExpDist) CDF(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	return -math.Expm1(-d.Lambda * x)
+}
+
+func (d ExpDist) Survival(x float64) float64 { return exp(d.LogSurvival(x)) }
+
+func (d ExpDist) LogCDF(x float64) float64 {
+	if x < 0 {
+		return negInf
+	}
+	return log1mexp(-d.Lambda * x)
+}
+
+func (d ExpDist) LogSurvival(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	return -d.Lambda * x
+}
+
+func (d ExpDist) Quantile(p float64) float64 { return -math.Log1p(-p) / d.Lambda }
+func (d ExpDist) Rand() float64              { return d.RandWith(DefaultRNG) }
+func (d ExpDist) RandWith(rng RNG) float64   { return NextExpWith(rng, d.Lambda) }
+func (d ExpDist) Mean() float64              { return 1 / d.Lambda }
+func (d ExpDist) Variance() float64          { return 1 / (d.Lambda * d.Lambda) }
+
+func (d ExpDist) Mode() float64       { return 0 }
+func (d ExpDist) Skewness() float64   { return 2 }
+func (d ExpDist) ExKurtosis() float64 { return 6 }
+func (d ExpDist) Entropy() float64    { return 1 - log(d.Lambda) }
+
+func Exp_PDF(λ float64) func(x float64) float64 {
+	return ExpDist{λ}.PDF
+}
+
+func Exp_LnPDF(λ float64) func(x float64) float64 {
+	return ExpDist{λ}.LogPDF
 }
 
-func NextExp(λ float64) float64 { return rand.ExpFloat64() / λ }
+func NextExp(λ float64) float64              { return NextExpWith(DefaultRNG, λ) }
+func NextExpWith(rng RNG, λ float64) float64 { return rng.ExpFloat64() / λ }
 
-func Exp(λ float64) func() float64 { return func() float64 { return NextExp(λ) } }
+func Exp(λ float64) func() float64 { return ExpDist{λ}.Rand }
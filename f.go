@@ -3,24 +3,138 @@ package stat
 
 import (
 	"fmt"
+	"math"
 
 	. "github.com/ematvey/go-fn/fn"
 )
 
-func F_PDF(d1 float64, d2 float64) func(x float64) float64 {
-	normalization := 1 / B(d1/2, d2/2)
-	return func(x float64) float64 {
-		return normalization * sqrt(pow(d1*x, d1)*pow(d2, d2)/pow(d1*x+d2, d1+d2)) / x
+// F-distribution with D1 and D2 degrees of freedom
+type FDist struct {
+	D1, D2 float64
+}
+
+// NewFDist returns an F-distribution, checking that d1 > 0 and d2 > 0.
+func NewFDist(d1, d2 float64) (FDist, error) {
+	if !(d1 > 0) || !(d2 > 0) || math.IsInf(d1, 0) || math.IsInf(d2, 0) {
+		return FDist{}, invalidParameter("F d1 = %v, d2 = %v", d1, d2)
 	}
+	return FDist{d1, d2}, nil
 }
-func F_LnPDF(d1 float64, d2 float64) func(x float64) float64 {
-	normalization := -LnB(d1/2, d2/2)
-	return func(x float64) float64 {
-		return normalization + log(d1*x)*d1/2 + log(d2)*d2/2 - log(d1*x+d2)*(d1+d2)/2 - log(x)
+
+func (d FDist) PDF(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	return exp(d.LogPDF(x))
+}
+
+func (d FDist) LogPDF(x float64) float64 {
+	if x < 0 {
+		return negInf
+	}
+	d1, d2 := d.D1, d.D2
+	return -LnB(d1/2, d2/2) + log(d1/d2)*d1/2 + xlogy(d1/2-1, x) - math.Log1p(d1*x/d2)*(d1+d2)/2
+}
+
+func (d FDist) logPDFs(dst, x []float64) {
+	d1, d2 := d.D1, d.D2
+	lnNorm := -LnB(d1/2, d2/2) + log(d1/d2)*d1/2
+	for i, xi := range x {
+		if xi < 0 {
+			dst[i] = negInf
+			continue
+		}
+		dst[i] = lnNorm + xlogy(d1/2-1, xi) - math.Log1p(d1*xi/d2)*(d1+d2)/2
 	}
 }
-func NextF(d1 int64, d2 int64) float64 {
-	return (NextXsquare(d1) * float64(d2)) / (NextXsquare(d2) * float64(d1))
+
+func (d FDist) CDF(x float64) float64      { return exp(d.LogCDF(x)) }
+func (d FDist) Survival(x float64) float64 { return exp(d.LogSurvival(x)) }
+
+func (d FDist) LogCDF(x float64) float64 {
+	lnI, _ := d.lnBetaIncReg(x)
+	return lnI
+}
+
+func (d FDist) LogSurvival(x float64) float64 {
+	_, lnIc := d.lnBetaIncReg(x)
+	return lnIc
+}
+
+// F(x) = I_y(d1/2, d2/2) with y = d1 x / (d1 x + d2)
+func (d FDist) lnBetaIncReg(x float64) (lnI, lnIc float64) {
+	if x <= 0 {
+		return negInf, 0
+	}
+	s := d.D1*x + d.D2
+	return lnBetaIncReg(d.D1/2, d.D2/2, d.D1*x/s, d.D2/s)
+}
+
+func (d FDist) Quantile(p float64) float64 { return quantile(d, p, 1, true) }
+
+func (d FDist) Rand() float64 { return d.RandWith(DefaultRNG) }
+
+func (d FDist) RandWith(rng RNG) float64 {
+	return (NextGammaWith(rng, d.D1/2, 0.5) * d.D2) / (NextGammaWith(rng, d.D2/2, 0.5) * d.D1)
+}
+
+func (d FDist) Mean() float64 {
+	if d.D2 <= 2 {
+		return math.Inf(1)
+	}
+	return d.D2 / (d.D2 - 2)
+}
+
+func (d FDist) Variance() float64 {
+	d1, d2 := d.D1, d.D2
+	switch {
+	case d2 <= 2:
+		return math.NaN()
+	case d2 <= 4:
+		return math.Inf(1)
+	}
+	return 2 * d2 * d2 * (d1 + d2 - 2) / (d1 * (d2 - 2) * (d2 - 2) * (d2 - 4))
+}
+
+func (d FDist) Mode() float64 {
+	if d.D1 <= 2 {
+		return 0
+	}
+	return (d.D1 - 2) / d.D1 * d.D2 / (d.D2 + 2)
+}
+
+func (d FDist) Skewness() float64 {
+	d1, d2 := d.D1, d.D2
+	if d2 <= 6 {
+		return math.NaN()
+	}
+	return (2*d1 + d2 - 2) * sqrt(8*(d2-4)) / ((d2 - 6) * sqrt(d1*(d1+d2-2)))
+}
+
+func (d FDist) ExKurtosis() float64 {
+	d1, d2 := d.D1, d.D2
+	if d2 <= 8 {
+		return math.NaN()
+	}
+	return 12 * (d1*(5*d2-22)*(d1+d2-2) + (d2-4)*(d2-2)*(d2-2)) /
+		(d1 * (d2 - 6) * (d2 - 8) * (d1 + d2 - 2))
+}
+
+func (d FDist) Entropy() float64 {
+	h1, h2 := d.D1/2, d.D2/2
+	return LnB(h1, h2) + (1-h1)*digamma(h1) - (1+h2)*digamma(h2) +
+		(h1+h2)*digamma(h1+h2) + log(d.D2/d.D1)
+}
+
+func F_PDF(d1 float64, d2 float64) func(x float64) float64 {
+	return FDist{d1, d2}.PDF
+}
+func F_LnPDF(d1 float64, d2 float64) func(x float64) float64 {
+	return FDist{d1, d2}.LogPDF
+}
+func NextF(d1 int64, d2 int64) float64 { return NextFWith(DefaultRNG, d1, d2) }
+func NextFWith(rng RNG, d1 int64, d2 int64) float64 {
+	return (NextXsquareWith(rng, d1) * float64(d2)) / (NextXsquareWith(rng, d2) * float64(d1))
 }
 func F(d1 int64, d2 int64) func() float64 {
 	return func() float64 {
@@ -30,10 +144,7 @@ func F(d1 int64, d2 int64) func() float64 {
 
 // CDF of F-distribution
 func F_CDF(df1, df2 float64) func(x float64) float64 {
-	return func(x float64) float64 {
-		y := df1 * x / (df1*x + df2)
-		return BetaIncReg(df1/2.0, df2/2.0, y)
-	}
+	return FDist{df1, df2}.CDF
 }
 
 // Value of CDF of F-distribution at x
@@ -42,7 +153,8 @@ func F_CDF_At(df1, df2, x float64) float64 {
 	return cdf(x)
 }
 
-// Inverse CDF (Quantile) function of F-distribution
+// Inverse CDF (Quantile) function of F-distribution; NaN if the inversion
+// fails to converge
 func F_InvCDF(df1, df2 float64) func(p float64) float64 {
 	return func(p float64) float64 {
 		if p < 0.0 {
@@ -58,7 +170,7 @@ func F_InvCDF(df1, df2 float64) func(p float64) float64 {
 			panic(fmt.Sprintf("df2 < 1"))
 		}
 
-		return ((1/BetaInv_CDF_For(df2/2, df1/2, 1-p) - 1) * df2 / df1)
+		return FDist{df1, df2}.Quantile(p)
 	}
 }
 
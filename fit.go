@@ -0,0 +1,519 @@
+// Maximum-likelihood fitting of univariate distributions
+
+package stat
+
+import (
+	"math"
+
+	mx "github.com/skelterjohn/go.matrix"
+)
+
+// Fit summarizes a maximum-likelihood fit. Params and StdErr follow the
+// order of the fields of the fitted distribution. The standard errors come
+// from the inverse of the observed information, the negative Hessian of the
+// log-likelihood at the estimate; they are NaN where the likelihood is not
+// smooth at its maximum, as for the ends of a Uniform distribution.
+type Fit struct {
+	Params []float64
+	StdErr []float64
+	LogLik float64
+	N      int // number of observations
+	K      int // number of free parameters
+}
+
+// AIC returns Akaike's information criterion 2K - 2 log L.
+func (f Fit) AIC() float64 { return 2*float64(f.K) - 2*f.LogLik }
+
+// BIC returns the Bayesian information criterion K log N - 2 log L.
+func (f Fit) BIC() float64 { return float64(f.K)*log(float64(f.N)) - 2*f.LogLik }
+
+// newFit evaluates the log-likelihood of d and the standard errors from the
+// information matrix, or from ll differentiated numerically if info is nil.
+func newFit(d ContinuousDistribution, x []float64, θ []float64, info [][]float64, ll func([]float64) float64) Fit {
+	if info == nil {
+		info = numericInfo(ll, θ)
+	}
+	return Fit{θ, stdErrors(info), sumLogPDF(d, x), len(x), len(θ)}
+}
+
+func newDiscreteFit(d DiscreteDistribution, k []int64, θ []float64, info [][]float64, ll func([]float64) float64) Fit {
+	if info == nil {
+		info = numericInfo(ll, θ)
+	}
+	return Fit{θ, stdErrors(info), sumLogPMF(d, k), len(k), len(θ)}
+}
+
+func sumLogPDF(d ContinuousDistribution, x []float64) (l float64) {
+	for _, xi := range x {
+		l += d.LogPDF(xi)
+	}
+	return
+}
+
+func sumLogPMF(d DiscreteDistribution, k []int64) (l float64) {
+	for _, ki := range k {
+		l += d.LogPMF(ki)
+	}
+	return
+}
+
+// stdErrors returns the square roots of the diagonal of the inverse of the
+// information matrix, NaN where it is not positive definite.
+func stdErrors(info [][]float64) []float64 {
+	se := make([]float64, len(info))
+	inv, err := mx.MakeDenseMatrixStacked(info).Inverse()
+	for i := range se {
+		if err != nil || !(inv.Get(i, i) >= 0) {
+			se[i] = math.NaN()
+		} else {
+			se[i] = sqrt(inv.Get(i, i))
+		}
+	}
+	return se
+}
+
+// numericInfo returns the negative Hessian of ll at θ by central differences
+// with steps relative to each parameter, so that the result does not depend
+// on the units of the data; only a parameter that is exactly 0 takes an
+// absolute step.
+func numericInfo(ll func([]float64) float64, θ []float64) [][]float64 {
+	n := len(θ)
+	h := make([]float64, n)
+	for i, t := range θ {
+		if h[i] = 1e-4 * abs(t); h[i] == 0 {
+			h[i] = 1e-4
+		}
+	}
+	at := func(i, j int, si, sj float64) float64 {
+		x := append([]float64(nil), θ...)
+		x[i] += si * h[i]
+		x[j] += sj * h[j]
+		return ll(x)
+	}
+	f0 := ll(θ)
+	H := make([][]float64, n)
+	for i := range H {
+		H[i] = make([]float64, n)
+	}
+	for i := 0; i < n; i++ {
+		x := append([]float64(nil), θ...)
+		x[i] = θ[i] + h[i]
+		fp := ll(x)
+		x[i] = θ[i] - h[i]
+		fm := ll(x)
+		H[i][i] = -(fp - 2*f0 + fm) / (h[i] * h[i])
+		for j := 0; j < i; j++ {
+			v := -(at(i, j, 1, 1) - at(i, j, 1, -1) - at(i, j, -1, 1) + at(i, j, -1, -1)) / (4 * h[i] * h[j])
+			H[i][j], H[j][i] = v, v
+		}
+	}
+	return H
+}
+
+// sampleMoments returns the mean and the variance with divisor n.
+func sampleMoments(x []float64) (m, v float64) {
+	for _, xi := range x {
+		m += xi
+	}
+	m /= float64(len(x))
+	for _, xi := range x {
+		v += (xi - m) * (xi - m)
+	}
+	return m, v / float64(len(x))
+}
+
+// checkData verifies that x is non-empty and that every value satisfies ok.
+func checkData(family string, x []float64, ok func(float64) bool) error {
+	if len(x) == 0 {
+		return invalidParameter("%s fit to no data", family)
+	}
+	for i, xi := range x {
+		if !ok(xi) {
+			return invalidParameter("%s fit: x[%d] = %v is outside the support", family, i, xi)
+		}
+	}
+	return nil
+}
+
+func checkCounts(family string, k []int64, ok func(int64) bool) error {
+	if len(k) == 0 {
+		return invalidParameter("%s fit to no data", family)
+	}
+	for i, ki := range k {
+		if !ok(ki) {
+			return invalidParameter("%s fit: k[%d] = %d is outside the support", family, i, ki)
+		}
+	}
+	return nil
+}
+
+func isPositive(x float64) bool { return x > 0 && !math.IsInf(x, 1) }
+
+// findPositiveRoot returns the root of f on (0, ∞), starting from x0 and
+// widening the bracket by factors of two on either side.
+func findPositiveRoot(f func(float64) float64, x0 float64) (float64, error) {
+	g := func(u float64) float64 { return f(exp(u)) }
+	a, b := log(x0)-1, log(x0)+1
+	for i := 0; (g(a) > 0) == (g(b) > 0); i++ {
+		if i == 60 {
+			return math.NaN(), noConvergence("no sign change of the score between %v and %v", exp(a), exp(b))
+		}
+		a, b = a-1, b+1
+	}
+	u, err := FindRoot(g, a, b, 4*epsilon)
+	return exp(u), err
+}
+
+// FitNormal returns the maximum-likelihood Normal distribution for x, whose
+// σ uses the divisor n.
+func FitNormal(x []float64) (NormalDist, Fit, error) {
+	if err := checkData("Normal", x, isFinite); err != nil {
+		return NormalDist{}, Fit{}, err
+	}
+	μ, v := sampleMoments(x)
+	if v == 0 {
+		return NormalDist{}, Fit{}, invalidParameter("Normal fit: data are constant")
+	}
+	d := NormalDist{μ, sqrt(v)}
+	n := float64(len(x))
+	info := [][]float64{{n / v, 0}, {0, 2 * n / v}}
+	return d, newFit(d, x, []float64{d.Mu, d.Sigma}, info, nil), nil
+}
+
+// FitExp returns the maximum-likelihood Exponential distribution for x.
+func FitExp(x []float64) (ExpDist, Fit, error) {
+	if err := checkData("Exp", x, func(x float64) bool { return x >= 0 && !math.IsInf(x, 1) }); err != nil {
+		return ExpDist{}, Fit{}, err
+	}
+	m, _ := sampleMoments(x)
+	if m == 0 {
+		return ExpDist{}, Fit{}, invalidParameter("Exp fit: data are all zero")
+	}
+	d := ExpDist{1 / m}
+	info := [][]float64{{float64(len(x)) / (d.Lambda * d.Lambda)}}
+	return d, newFit(d, x, []float64{d.Lambda}, info, nil), nil
+}
+
+// FitUniform returns the smallest Uniform distribution containing x. The
+// likelihood is not differentiable there, so the standard errors are NaN.
+func FitUniform(x []float64) (UniformDist, Fit, error) {
+	if err := checkData("Uniform", x, isFinite); err != nil {
+		return UniformDist{}, Fit{}, err
+	}
+	d := UniformDist{x[0], x[0]}
+	for _, xi := range x {
+		d.Min, d.Max = math.Min(d.Min, xi), math.Max(d.Max, xi)
+	}
+	if d.Min == d.Max {
+		return UniformDist{}, Fit{}, invalidParameter("Uniform fit: data are constant")
+	}
+	f := Fit{[]float64{d.Min, d.Max}, []float64{math.NaN(), math.NaN()}, sumLogPDF(d, x), len(x), 2}
+	return d, f, nil
+}
+
+// FitGamma returns the maximum-likelihood Gamma distribution for x. The
+// shape solves log k - ψ(k) = log mean(x) - mean(log x), and θ = mean(x)/k.
+func FitGamma(x []float64) (GammaDist, Fit, error) {
+	if err := checkData("Gamma", x, isPositive); err != nil {
+		return GammaDist{}, Fit{}, err
+	}
+	k, θ, err := fitGammaShape(x)
+	if err != nil {
+		return GammaDist{}, Fit{}, err
+	}
+	d := GammaDist{k, θ}
+	n := float64(len(x))
+	info := [][]float64{{n * trigamma(k), n / θ}, {n / θ, n * k / (θ * θ)}}
+	return d, newFit(d, x, []float64{k, θ}, info, nil), nil
+}
+
+func fitGammaShape(x []float64) (k, θ float64, err error) {
+	m, _ := sampleMoments(x)
+	var ml float64
+	for _, xi := range x {
+		ml += log(xi)
+	}
+	s := log(m) - ml/float64(len(x))
+	if !(s > 0) {
+		return 0, 0, invalidParameter("Gamma fit: data are constant")
+	}
+	// Minka's approximation as the starting point
+	k0 := (3 - s + sqrt((s-3)*(s-3)+24*s)) / (12 * s)
+	k, err = findPositiveRoot(func(k float64) float64 { return log(k) - digamma(k) - s }, k0)
+	return k, m / k, err
+}
+
+// FitInvGamma returns the maximum-likelihood Inverse Gamma distribution for
+// x, by fitting a Gamma distribution to 1/x.
+func FitInvGamma(x []float64) (InvGammaDist, Fit, error) {
+	if err := checkData("InvGamma", x, isPositive); err != nil {
+		return InvGammaDist{}, Fit{}, err
+	}
+	y := make([]float64, len(x))
+	for i, xi := range x {
+		y[i] = 1 / xi
+	}
+	k, θ, err := fitGammaShape(y)
+	if err != nil {
+		return InvGammaDist{}, Fit{}, err
+	}
+	d := InvGammaDist{k, 1 / θ}
+	n := float64(len(x))
+	info := [][]float64{{n * trigamma(d.A), -n / d.B}, {-n / d.B, n * d.A / (d.B * d.B)}}
+	return d, newFit(d, x, []float64{d.A, d.B}, info, nil), nil
+}
+
+// FitBeta returns the maximum-likelihood Beta distribution for x, by Newton's
+// method from the method-of-moments estimate.
+func FitBeta(x []float64) (BetaDist, Fit, error) {
+	if err := checkData("Beta", x, func(x float64) bool { return x > 0 && x < 1 }); err != nil {
+		return BetaDist{}, Fit{}, err
+	}
+	n := float64(len(x))
+	m, v := sampleMoments(x)
+	if v == 0 {
+		return BetaDist{}, Fit{}, invalidParameter("Beta fit: data are constant")
+	}
+	var s1, s2 float64
+	for _, xi := range x {
+		s1 += log(xi)
+		s2 += math.Log1p(-xi)
+	}
+	s1, s2 = s1/n, s2/n
+	ll := func(α, β float64) float64 { return (α-1)*s1 + (β-1)*s2 - lnBeta(α, β) }
+
+	c := m*(1-m)/v - 1
+	if !(c > 0) {
+		c = 1
+	}
+	α, β := m*c, (1-m)*c
+	var err error
+	for iter := 0; ; iter++ {
+		if iter == 200 {
+			err = noConvergence("Beta fit after %d Newton steps", iter)
+			break
+		}
+		ψab, ψ1ab := digamma(α+β), trigamma(α+β)
+		g1, g2 := s1-digamma(α)+ψab, s2-digamma(β)+ψab
+		h11, h22, h12 := trigamma(α)-ψ1ab, trigamma(β)-ψ1ab, -ψ1ab // negative Hessian
+		det := h11*h22 - h12*h12
+		dα, dβ := (h22*g1-h12*g2)/det, (h11*g2-h12*g1)/det
+		// halve the step until it stays positive and does not decrease ll
+		t := 1.0
+		for ; t > 1e-10; t /= 2 {
+			if a, b := α+t*dα, β+t*dβ; a > 0 && b > 0 && ll(a, b) >= ll(α, β)-1e-15 {
+				break
+			}
+		}
+		α, β = α+t*dα, β+t*dβ
+		if abs(t*dα) <= 1e-14*α && abs(t*dβ) <= 1e-14*β {
+			break
+		}
+	}
+	d := BetaDist{α, β}
+	ψ1ab := trigamma(α + β)
+	info := [][]float64{{n * (trigamma(α) - ψ1ab), -n * ψ1ab}, {-n * ψ1ab, n * (trigamma(β) - ψ1ab)}}
+	return d, newFit(d, x, []float64{α, β}, info, nil), err
+}
+
+// FitXsquare returns the maximum-likelihood Chi-Squared distribution for x,
+// whose degrees of freedom solve ψ(n/2) = mean(log x) - log 2.
+func FitXsquare(x []float64) (XsquareDist, Fit, error) {
+	if err := checkData("Xsquare", x, isPositive); err != nil {
+		return XsquareDist{}, Fit{}, err
+	}
+	var ml float64
+	for _, xi := range x {
+		ml += log(xi)
+	}
+	s := ml/float64(len(x)) - math.Ln2
+	ν, err := findPositiveRoot(func(ν float64) float64 { return s - digamma(ν/2) }, 1)
+	if err != nil {
+		return XsquareDist{}, Fit{}, err
+	}
+	d := XsquareDist{ν}
+	info := [][]float64{{float64(len(x)) * trigamma(ν/2) / 4}}
+	return d, newFit(d, x, []float64{ν}, info, nil), nil
+}
+
+// FitF returns the maximum-likelihood F-distribution for x, by Nelder-Mead
+// over the logs of the degrees of freedom.
+func FitF(x []float64) (FDist, Fit, error) {
+	if err := checkData("F", x, isPositive); err != nil {
+		return FDist{}, Fit{}, err
+	}
+	m, v := sampleMoments(x)
+	// method of moments where it applies
+	d1, d2 := 5.0, 10.0
+	if m > 1 {
+		d2 = 2 * m / (m - 1)
+	}
+	if d2 > 4 {
+		if c := v * (d2 - 2) * (d2 - 2) * (d2 - 4) / (2 * d2 * d2); c > 1 {
+			d1 = (d2 - 2) / (c - 1)
+		}
+	}
+	ll := func(θ []float64) float64 { return sumLogPDF(FDist{θ[0], θ[1]}, x) }
+	u, _, err := nelderMead(func(u []float64) float64 {
+		return -ll([]float64{exp(u[0]), exp(u[1])})
+	}, []float64{log(d1), log(d2)}, 0.5, 1e-10)
+	d := FDist{exp(u[0]), exp(u[1])}
+	return d, newFit(d, x, []float64{d.D1, d.D2}, nil, ll), err
+}
+
+// FitStudentsT returns the maximum-likelihood location-scale Student's t
+// distribution for x, with Params ν, μ and σ. The search is over log ν and
+// log σ by Nelder-Mead, with ν capped at 1e6, where the distribution is
+// Normal for all practical purposes.
+func FitStudentsT(x []float64) (LocationScaleDist, Fit, error) {
+	if err := checkData("StudentsT", x, isFinite); err != nil {
+		return LocationScaleDist{}, Fit{}, err
+	}
+	e, _ := NewEmpiricalDist(x)
+	μ, σ := e.QuantileType(0.5, 7), (e.QuantileType(0.75, 7)-e.QuantileType(0.25, 7))/1.349
+	if !(σ > 0) {
+		if _, v := sampleMoments(x); v > 0 {
+			σ = sqrt(v)
+		} else {
+			return LocationScaleDist{}, Fit{}, invalidParameter("StudentsT fit: data are constant")
+		}
+	}
+	ll := func(θ []float64) float64 {
+		return sumLogPDF(LocationScaleDist{StudentsTDist{θ[0]}, θ[1], θ[2]}, x)
+	}
+	u, _, err := nelderMead(func(u []float64) float64 {
+		if u[0] > log(1e6) {
+			return math.Inf(1)
+		}
+		return -ll([]float64{exp(u[0]), u[1], exp(u[2])})
+	}, []float64{log(5), μ, log(σ)}, 0.5, 1e-10)
+	d := LocationScaleDist{StudentsTDist{exp(u[0])}, u[1], exp(u[2])}
+	return d, newFit(d, x, []float64{exp(u[0]), u[1], exp(u[2])}, nil, ll), err
+}
+
+// FitBernoulli returns the maximum-likelihood Bernoulli distribution for k.
+func FitBernoulli(k []int64) (BernoulliDist, Fit, error) {
+	if err := checkCounts("Bernoulli", k, func(k int64) bool { return k == 0 || k == 1 }); err != nil {
+		return BernoulliDist{}, Fit{}, err
+	}
+	ρ := countMean(k)
+	d := BernoulliDist{ρ}
+	info := [][]float64{{float64(len(k)) / (ρ * (1 - ρ))}}
+	return d, newDiscreteFit(d, k, []float64{ρ}, info, nil), nil
+}
+
+// FitBinomial returns the maximum-likelihood Binomial distribution with n
+// trials for k. Only ρ is estimated, so Params has one element.
+func FitBinomial(k []int64, n int64) (BinomialDist, Fit, error) {
+	if n < 1 {
+		return BinomialDist{}, Fit{}, invalidParameter("Binomial fit with n = %d", n)
+	}
+	if err := checkCounts("Binomial", k, func(k int64) bool { return k >= 0 && k <= n }); err != nil {
+		return BinomialDist{}, Fit{}, err
+	}
+	ρ := countMean(k) / float64(n)
+	d := BinomialDist{ρ, n}
+	info := [][]float64{{float64(len(k)) * float64(n) / (ρ * (1 - ρ))}}
+	return d, newDiscreteFit(d, k, []float64{ρ}, info, nil), nil
+}
+
+// FitPoisson returns the maximum-likelihood Poisson distribution for k.
+func FitPoisson(k []int64) (PoissonDist, Fit, error) {
+	if err := checkCounts("Poisson", k, func(k int64) bool { return k >= 0 }); err != nil {
+		return PoissonDist{}, Fit{}, err
+	}
+	λ := countMean(k)
+	d := PoissonDist{λ}
+	info := [][]float64{{float64(len(k)) / λ}}
+	return d, newDiscreteFit(d, k, []float64{λ}, info, nil), nil
+}
+
+// FitGeometric returns the maximum-likelihood Geometric distribution for k.
+func FitGeometric(k []int64) (GeometricDist, Fit, error) {
+	if err := checkCounts("Geometric", k, func(k int64) bool { return k >= 0 }); err != nil {
+		return GeometricDist{}, Fit{}, err
+	}
+	ρ := 1 / (1 + countMean(k))
+	d := GeometricDist{ρ}
+	info := [][]float64{{float64(len(k)) / (ρ * ρ * (1 - ρ))}}
+	return d, newDiscreteFit(d, k, []float64{ρ}, info, nil), nil
+}
+
+// FitNegativeBinomial returns the maximum-likelihood Negative Binomial
+// distribution for k. r solves the profile score equation
+// Σ ψ(k_i + r) - n ψ(r) + n log(r / (r + mean(k))) = 0 and ρ = r / (r +
+// mean(k)). Data that are not overdispersed have no finite estimate of r,
+// which is reported as ErrNoConvergence.
+func FitNegativeBinomial(k []int64) (NegativeBinomialDist, Fit, error) {
+	if err := checkCounts("NegativeBinomial", k, func(k int64) bool { return k >= 0 }); err != nil {
+		return NegativeBinomialDist{}, Fit{}, err
+	}
+	x := make([]float64, len(k))
+	for i, ki := range k {
+		x[i] = float64(ki)
+	}
+	m, v := sampleMoments(x)
+	if !(v > m) {
+		return NegativeBinomialDist{}, Fit{}, noConvergence("NegativeBinomial fit: variance %v does not exceed mean %v", v, m)
+	}
+	n := float64(len(k))
+	score := func(r float64) float64 {
+		s := n * (log(r/(r+m)) - digamma(r))
+		for _, xi := range x {
+			s += digamma(xi + r)
+		}
+		return s
+	}
+	r, err := findPositiveRoot(score, m*m/(v-m))
+	if err != nil {
+		return NegativeBinomialDist{}, Fit{}, err
+	}
+	d := NegativeBinomialDist{r / (r + m), r}
+	ll := func(θ []float64) float64 { return sumLogPMF(NegativeBinomialDist{θ[0], θ[1]}, k) }
+	return d, newDiscreteFit(d, k, []float64{d.Rho, d.R}, nil, ll), nil
+}
+
+// FitChoice returns the maximum-likelihood categorical distribution over
+// 0, ..., m-1 for k: the observed frequencies. Params holds all m weights,
+// of which m-1 are free.
+func FitChoice(k []int64, m int) (ChoiceDist, Fit, error) {
+	if err := checkCounts("Choice", k, func(k int64) bool { return k >= 0 && k < int64(m) }); err != nil {
+		return ChoiceDist{}, Fit{}, err
+	}
+	n := float64(len(k))
+	θ := make([]float64, m)
+	for _, ki := range k {
+		θ[ki] += 1 / n
+	}
+	se := make([]float64, m)
+	for i, t := range θ {
+		se[i] = sqrt(t * (1 - t) / n)
+	}
+	d := ChoiceDist{θ}
+	return d, Fit{θ, se, sumLogPMF(d, k), len(k), m - 1}, nil
+}
+
+// FitRange returns the maximum-likelihood discrete uniform distribution over
+// 0, ..., N-1 for k, with N one more than the largest value. As for
+// FitUniform, the standard error is NaN.
+func FitRange(k []int64) (RangeDist, Fit, error) {
+	if err := checkCounts("Range", k, func(k int64) bool { return k >= 0 }); err != nil {
+		return RangeDist{}, Fit{}, err
+	}
+	var max int64
+	for _, ki := range k {
+		if ki > max {
+			max = ki
+		}
+	}
+	d := RangeDist{max + 1}
+	return d, Fit{[]float64{float64(d.N)}, []float64{math.NaN()}, sumLogPMF(d, k), len(k), 1}, nil
+}
+
+func countMean(k []int64) float64 {
+	var s float64
+	for _, ki := range k {
+		s += float64(ki)
+	}
+	return s / float64(len(k))
+}
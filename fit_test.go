@@ -0,0 +1,243 @@
+package stat
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestFitExact(t *testing.T) {
+	d, f, err := FitNormal([]float64{1, 2, 3, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	σ := math.Sqrt(1.25)
+	check(t, "Normal μ", d.Mu, 2.5, 1e-15)
+	check(t, "Normal σ", d.Sigma, σ, 1e-15)
+	check(t, "Normal SE(μ)", f.StdErr[0], σ/2, 1e-15)
+	check(t, "Normal SE(σ)", f.StdErr[1], σ/math.Sqrt(8), 1e-15)
+	ll := -2*math.Log(2*π*1.25) - 2
+	check(t, "Normal LogLik", f.LogLik, ll, 1e-14)
+	check(t, "AIC", f.AIC(), 4-2*ll, 1e-14)
+	check(t, "BIC", f.BIC(), 2*math.Log(4)-2*ll, 1e-14)
+
+	p, f, _ := FitPoisson([]int64{0, 2, 3, 3})
+	check(t, "Poisson λ", p.Lambda, 2, 0)
+	check(t, "Poisson SE", f.StdErr[0], math.Sqrt(0.5), 1e-15)
+
+	c, f, _ := FitChoice([]int64{0, 2, 2, 1}, 4)
+	if c.Theta[2] != 0.5 || c.Theta[3] != 0 || f.K != 3 {
+		t.Errorf("FitChoice: θ = %v, K = %d", c.Theta, f.K)
+	}
+
+	u, f, _ := FitUniform([]float64{0.5, -1, 3})
+	if u.Min != -1 || u.Max != 3 || !math.IsNaN(f.StdErr[0]) {
+		t.Errorf("FitUniform: %+v, SE %v", u, f.StdErr)
+	}
+}
+
+// TestFitMaximizes checks on simulated data that each fit recovers the
+// parameters within a few standard errors, that the log-likelihood cannot be
+// improved by moving any parameter, and that the reported standard errors
+// agree with the numerically differentiated likelihood.
+func TestFitMaximizes(t *testing.T) {
+	rng := NewRNG(9)
+	draw := func(d ContinuousDistribution, n int) []float64 {
+		x := make([]float64, n)
+		for i := range x {
+			x[i] = d.RandWith(rng)
+		}
+		return x
+	}
+	for _, c := range []struct {
+		truth ContinuousDistribution
+		fit   func(x []float64) (ContinuousDistribution, Fit, error)
+		make  func(θ []float64) ContinuousDistribution
+	}{
+		{NormalDist{3, 2},
+			func(x []float64) (ContinuousDistribution, Fit, error) { return FitNormal(x) },
+			func(θ []float64) ContinuousDistribution { return NormalDist{θ[0], θ[1]} }},
+		{ExpDist{0.7},
+			func(x []float64) (ContinuousDistribution, Fit, error) { return FitExp(x) },
+			func(θ []float64) ContinuousDistribution { return ExpDist{θ[0]} }},
+		{GammaDist{2.5, 1.5},
+			func(x []float64) (ContinuousDistribution, Fit, error) { return FitGamma(x) },
+			func(θ []float64) ContinuousDistribution { return GammaDist{θ[0], θ[1]} }},
+		{GammaDist{0.3, 4},
+			func(x []float64) (ContinuousDistribution, Fit, error) { return FitGamma(x) },
+			func(θ []float64) ContinuousDistribution { return GammaDist{θ[0], θ[1]} }},
+		{InvGammaDist{3, 2},
+			func(x []float64) (ContinuousDistribution, Fit, error) { return FitInvGamma(x) },
+			func(θ []float64) ContinuousDistribution { return InvGammaDist{θ[0], θ[1]} }},
+		{BetaDist{2, 5},
+			func(x []float64) (ContinuousDistribution, Fit, error) { return FitBeta(x) },
+			func(θ []float64) ContinuousDistribution { return BetaDist{θ[0], θ[1]} }},
+		{BetaDist{0.5, 0.5},
+			func(x []float64) (ContinuousDistribution, Fit, error) { return FitBeta(x) },
+			func(θ []float64) ContinuousDistribution { return BetaDist{θ[0], θ[1]} }},
+		{XsquareDist{4},
+			func(x []float64) (ContinuousDistribution, Fit, error) { return FitXsquare(x) },
+			func(θ []float64) ContinuousDistribution { return XsquareDist{θ[0]} }},
+		{FDist{6, 12},
+			func(x []float64) (ContinuousDistribution, Fit, error) { return FitF(x) },
+			func(θ []float64) ContinuousDistribution { return FDist{θ[0], θ[1]} }},
+		{LocationScaleDist{StudentsTDist{4}, 10, 3},
+			func(x []float64) (ContinuousDistribution, Fit, error) { return FitStudentsT(x) },
+			func(θ []float64) ContinuousDistribution {
+				return LocationScaleDist{StudentsTDist{θ[0]}, θ[1], θ[2]}
+			}},
+	} {
+		x := draw(c.truth, 3000)
+		d, f, err := c.fit(x)
+		if err != nil {
+			t.Errorf("%#v: %v", c.truth, err)
+			continue
+		}
+		ll := func(θ []float64) float64 { return sumLogPDF(c.make(θ), x) }
+		checkFit(t, c.truth, d, f, ll, c.make(f.Params).Mean(), d.Mean())
+		checkRecovered(t, c.truth, f)
+	}
+}
+
+func TestFitScaleInvariant(t *testing.T) {
+	// rescaling the data rescales the location and scale and their
+	// standard errors, whatever the units
+	rng := NewRNG(11)
+	x, y := make([]float64, 500), make([]float64, 500)
+	for i := range x {
+		x[i] = 10 + 3*NextStudentsTWith(rng, 4)
+		y[i] = 1e-9 * x[i]
+	}
+	_, fx, _ := FitStudentsT(x)
+	_, fy, _ := FitStudentsT(y)
+	for i, scale := range []float64{1, 1e-9, 1e-9} {
+		check(t, "Params", fy.Params[i]/scale, fx.Params[i], 1e-5)
+		check(t, "StdErr", fy.StdErr[i]/scale, fx.StdErr[i], 1e-4)
+	}
+}
+
+func TestFitDiscrete(t *testing.T) {
+	rng := NewRNG(10)
+	draw := func(d DiscreteDistribution, n int) []int64 {
+		k := make([]int64, n)
+		for i := range k {
+			k[i] = d.RandWith(rng)
+		}
+		return k
+	}
+	for _, c := range []struct {
+		truth DiscreteDistribution
+		fit   func(k []int64) (DiscreteDistribution, Fit, error)
+		make  func(θ []float64) DiscreteDistribution
+	}{
+		{BernoulliDist{0.3},
+			func(k []int64) (DiscreteDistribution, Fit, error) { return FitBernoulli(k) },
+			func(θ []float64) DiscreteDistribution { return BernoulliDist{θ[0]} }},
+		{BinomialDist{0.4, 12},
+			func(k []int64) (DiscreteDistribution, Fit, error) { return FitBinomial(k, 12) },
+			func(θ []float64) DiscreteDistribution { return BinomialDist{θ[0], 12} }},
+		{PoissonDist{3.5},
+			func(k []int64) (DiscreteDistribution, Fit, error) { return FitPoisson(k) },
+			func(θ []float64) DiscreteDistribution { return PoissonDist{θ[0]} }},
+		{GeometricDist{0.2},
+			func(k []int64) (DiscreteDistribution, Fit, error) { return FitGeometric(k) },
+			func(θ []float64) DiscreteDistribution { return GeometricDist{θ[0]} }},
+		{NegativeBinomialDist{0.3, 2.5},
+			func(k []int64) (DiscreteDistribution, Fit, error) { return FitNegativeBinomial(k) },
+			func(θ []float64) DiscreteDistribution { return NegativeBinomialDist{θ[0], θ[1]} }},
+	} {
+		k := draw(c.truth, 3000)
+		d, f, err := c.fit(k)
+		if err != nil {
+			t.Errorf("%#v: %v", c.truth, err)
+			continue
+		}
+		ll := func(θ []float64) float64 { return sumLogPMF(c.make(θ), k) }
+		checkFit(t, c.truth, d, f, ll, c.make(f.Params).Mean(), d.Mean())
+		checkRecovered(t, c.truth, f)
+	}
+
+	if _, _, err := FitNegativeBinomial([]int64{2, 2, 3, 2}); !errors.Is(err, ErrNoConvergence) {
+		t.Errorf("underdispersed data: expected ErrNoConvergence, got %v", err)
+	}
+	if _, _, err := FitPoisson([]int64{1, -1}); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("negative count: expected ErrInvalidParameter, got %v", err)
+	}
+}
+
+func TestFitErrors(t *testing.T) {
+	_, _, e1 := FitNormal(nil)
+	_, _, e2 := FitNormal([]float64{2, 2})
+	_, _, e3 := FitGamma([]float64{1, -2})
+	_, _, e4 := FitBeta([]float64{0.5, 1})
+	_, _, e5 := FitExp([]float64{0, 0})
+	for i, err := range []error{e1, e2, e3, e4, e5} {
+		if !errors.Is(err, ErrInvalidParameter) {
+			t.Errorf("case %d: expected ErrInvalidParameter, got %v", i, err)
+		}
+	}
+}
+
+// checkFit verifies that f describes d and that ll is maximal at f.Params.
+func checkFit(t *testing.T, truth, d interface{}, f Fit, ll func([]float64) float64, mean, dMean float64) {
+	t.Helper()
+	if mean != dMean {
+		t.Errorf("%#v: Params %v do not describe the fitted %#v", truth, f.Params, d)
+	}
+	check(t, "LogLik", f.LogLik, ll(f.Params), 1e-12)
+	for i := range f.Params {
+		for _, s := range []float64{-1, 1} {
+			θ := append([]float64(nil), f.Params...)
+			θ[i] += s * 1e-3 * f.StdErr[i]
+			if l := ll(θ); l > f.LogLik+1e-9 {
+				t.Errorf("%#v: moving parameter %d raises the log-likelihood from %v to %v", truth, i, f.LogLik, l)
+			}
+		}
+	}
+	num := stdErrors(numericInfo(ll, f.Params))
+	for i, se := range f.StdErr {
+		if !(se > 0) || abs(se-num[i]) > 1e-4*se {
+			t.Errorf("%#v: SE[%d] = %v, numerically %v", truth, i, se, num[i])
+		}
+	}
+}
+
+// checkRecovered verifies that the true parameters, taken in the order of
+// the fields, lie within 4 standard errors of the estimates.
+func checkRecovered(t *testing.T, truth interface{}, f Fit) {
+	t.Helper()
+	var θ []float64
+	switch d := truth.(type) {
+	case NormalDist:
+		θ = []float64{d.Mu, d.Sigma}
+	case ExpDist:
+		θ = []float64{d.Lambda}
+	case GammaDist:
+		θ = []float64{d.K, d.Theta}
+	case InvGammaDist:
+		θ = []float64{d.A, d.B}
+	case BetaDist:
+		θ = []float64{d.Alpha, d.Beta}
+	case XsquareDist:
+		θ = []float64{d.N}
+	case FDist:
+		θ = []float64{d.D1, d.D2}
+	case LocationScaleDist:
+		θ = []float64{d.Base.(StudentsTDist).Nu, d.Loc, d.Scale}
+	case BernoulliDist:
+		θ = []float64{d.Rho}
+	case BinomialDist:
+		θ = []float64{d.Rho}
+	case PoissonDist:
+		θ = []float64{d.Lambda}
+	case GeometricDist:
+		θ = []float64{d.Rho}
+	case NegativeBinomialDist:
+		θ = []float64{d.Rho, d.R}
+	}
+	for i := range θ {
+		if abs(θ[i]-f.Params[i]) > 4*f.StdErr[i] {
+			t.Errorf("%#v: parameter %d estimated as %v ± %v", truth, i, f.Params[i], f.StdErr[i])
+		}
+	}
+}
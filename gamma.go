@@ -24,40 +24,105 @@ func Gamma_PDF(α float64, λ float64) func(x float64) float64 {
 }
 */
 
-// Probability density function
-func Gamma_PDF(k float64, θ float64) func(x float64) float64 {
-	return func(x float64) float64 {
-		if x < 0 {
-			return 0
-		}
-		return pow(x, k-1) * exp(-x/θ) / (Γ(k) * pow(θ, k))
+// Gamma distribution with shape K and scale Theta
+type GammaDist struct {
+	K, Theta float64
+}
+
+// NewGammaDist returns a Gamma distribution, checking that k > 0 and θ > 0.
+func NewGammaDist(k, θ float64) (GammaDist, error) {
+	if !(k > 0) || !(θ > 0) || math.IsInf(k, 0) || math.IsInf(θ, 0) {
+		return GammaDist{}, invalidParameter("Gamma k = %v, θ = %v", k, θ)
 	}
+	return GammaDist{k, θ}, nil
 }
 
-// Natural logarithm of the probability density function
-func Gamma_LnPDF(α float64, λ float64) func(x float64) float64 {
-	expPart := Exp_LnPDF(λ)
-	return func(x float64) float64 {
-		if x < 0 {
-			return negInf
+func (d GammaDist) PDF(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	return exp(d.LogPDF(x))
+}
+
+func (d GammaDist) LogPDF(x float64) float64 {
+	if x < 0 {
+		return negInf
+	}
+	return xlogy(d.K-1, x) - x/d.Theta - LnΓ(d.K) - d.K*log(d.Theta)
+}
+
+func (d GammaDist) logPDFs(dst, x []float64) {
+	lnNorm := -LnΓ(d.K) - d.K*log(d.Theta)
+	for i, xi := range x {
+		if xi < 0 {
+			dst[i] = negInf
+			continue
 		}
-		return expPart(x) + (α-1)*log(λ*x) - LnΓ(α)
+		dst[i] = xlogy(d.K-1, xi) - xi/d.Theta + lnNorm
+	}
+}
+
+func (d GammaDist) CDF(x float64) float64      { return exp(d.LogCDF(x)) }
+func (d GammaDist) Survival(x float64) float64 { return exp(d.LogSurvival(x)) }
+
+func (d GammaDist) LogCDF(x float64) float64 {
+	lnP, _ := lnGammaIncReg(d.K, x/d.Theta)
+	return lnP
+}
+
+func (d GammaDist) LogSurvival(x float64) float64 {
+	_, lnQ := lnGammaIncReg(d.K, x/d.Theta)
+	return lnQ
+}
+
+func (d GammaDist) Quantile(p float64) float64 {
+	return quantile(d, p, gammaQuantileGuess(d.K, p)*d.Theta, true)
+}
+
+func (d GammaDist) Rand() float64            { return d.RandWith(DefaultRNG) }
+func (d GammaDist) RandWith(rng RNG) float64 { return NextGammaWith(rng, d.K, 1/d.Theta) }
+func (d GammaDist) Mean() float64            { return d.K * d.Theta }
+func (d GammaDist) Variance() float64        { return d.K * d.Theta * d.Theta }
+
+func (d GammaDist) Mode() float64 {
+	if d.K < 1 {
+		return 0
 	}
+	return (d.K - 1) * d.Theta
+}
+
+func (d GammaDist) Skewness() float64   { return 2 / sqrt(d.K) }
+func (d GammaDist) ExKurtosis() float64 { return 6 / d.K }
+
+func (d GammaDist) Entropy() float64 {
+	return d.K + log(d.Theta) + LnΓ(d.K) + (1-d.K)*digamma(d.K)
+}
+
+// Probability density function, θ is the scale
+func Gamma_PDF(k float64, θ float64) func(x float64) float64 {
+	return GammaDist{k, θ}.PDF
+}
+
+// Natural logarithm of the probability density function, λ is the rate
+func Gamma_LnPDF(α float64, λ float64) func(x float64) float64 {
+	return GammaDist{α, 1 / λ}.LogPDF
 }
 
-// Random value drawn from the distribution
-func NextGamma(α float64, λ float64) float64 {
+// Random value drawn from the distribution, λ is the rate
+func NextGamma(α float64, λ float64) float64 { return NextGammaWith(DefaultRNG, α, λ) }
+func NextGammaWith(rng RNG, α float64, λ float64) float64 {
 	//if α is a small integer, this way is faster on my laptop
 	if α == float64(int64(α)) && α <= 15 {
-		x := NextExp(λ)
+		x := NextExpWith(rng, λ)
 		for i := 1; i < int(α); i++ {
-			x += NextExp(λ)
+			x += NextExpWith(rng, λ)
 		}
 		return x
 	}
 
-	if α < 0.75 {
-		return RejectionSample(Gamma_PDF(α, λ), Exp_PDF(λ), Exp(λ), 1)
+	if α < 1 {
+		// Gamma(α) = Gamma(α+1) * U^(1/α)
+		return NextGammaWith(rng, α+1, λ) * pow(rng.Float64(), 1/α)
 	}
 
 	//Tadikamalla ACM '73
@@ -69,7 +134,7 @@ func NextGamma(α float64, λ float64) float64 {
 	p := 1.0 / (2 - exp(-s))
 	var x, y float64
 	for i := 1; ; i++ {
-		u := NextUniform()
+		u := rng.Float64()
 		if u > p {
 			var e float64
 			for e = -log((1 - u) / (1 - p)); e > s; e = e - a/b {
@@ -80,7 +145,7 @@ func NextGamma(α float64, λ float64) float64 {
 			x = a - b*log(u/p)
 			y = x - a
 		}
-		u2 := NextUniform()
+		u2 := rng.Float64()
 		if log(u2) <= a*log(d*x)-x+y/b+c {
 			break
 		}
@@ -98,10 +163,7 @@ func Gamma_CDF(k float64, θ float64) func(x float64) float64 {
 		if k < 0 || θ < 0 {
 			panic(fmt.Sprintf("k < 0 || θ < 0"))
 		}
-		if x < 0 {
-			return 0
-		}
-		return Iγ(k, x/θ) / Γ(k)
+		return GammaDist{k, θ}.CDF(x)
 	}
 }
 
@@ -145,32 +207,24 @@ func Gamma_CDF_At(k, θ, x float64) float64 {
 	return cdf(x)
 }
 
-// Inverse CDF (Quantile) function
+// Inverse CDF (Quantile) function; NaN if the inversion fails to converge
 func Gamma_InvCDF(k float64, θ float64) func(x float64) float64 {
-	return func(x float64) float64 {
-		var eps, y_new, h float64
-		eps = 1e-4
-		y := k * θ
-		y_old := y
-	L:
-		for i := 0; i < 100; i++ {
-			h = (Gamma_CDF_At(k, θ, y_old) - x) / Gamma_PDF_At(k, θ, y_old)
-			y_new = y_old - h
-			if y_new <= eps {
-				y_new = y_old / 10
-				h = y_old - y_new
-			}
-			if math.Abs(h) < eps {
-				break L
-			}
-			y_old = y_new
-		}
-		return y_new
-	}
+	return GammaDist{k, θ}.Quantile
 }
 
 // Value of the inverse CDF for probability p
 func Gamma_InvCDF_For(k, θ, p float64) float64 {
-	cdf := Gamma_InvCDF(k, θ)
-	return cdf(p)
+	return GammaDist{k, θ}.Quantile(p)
+}
+
+// gammaQuantileGuess approximates the p-quantile of Gamma(k, 1) by the
+// Wilson-Hilferty transformation, or by P(k, x) ≈ x^k / Γ(k+1) in the lower
+// tail where that breaks down.
+func gammaQuantileGuess(k, p float64) float64 {
+	c := 1 / (9 * k)
+	x := k * math.Pow(1-c+Z_InvCDF_For(p)*math.Sqrt(c), 3)
+	if small := math.Exp((math.Log(p) + LnΓ(k+1)) / k); !(x > 0) || small < x {
+		return small
+	}
+	return x
 }
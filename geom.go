@@ -1,17 +1,110 @@
 package stat
 
+import (
+	"math"
+)
+
+// Geometric distribution: number of failures before the first success,
+// each trial succeeding with probability Rho
+type GeometricDist struct {
+	Rho float64
+}
+
+// NewGeometricDist returns a Geometric distribution, checking that 0 < ρ <= 1.
+func NewGeometricDist(ρ float64) (GeometricDist, error) {
+	if !isProbability(ρ) || ρ == 0 {
+		return GeometricDist{}, invalidParameter("Geometric ρ = %v", ρ)
+	}
+	return GeometricDist{ρ}, nil
+}
+
+func (d GeometricDist) PMF(k int64) float64 {
+	if k < 0 {
+		return 0
+	}
+	return d.Rho * pow(1-d.Rho, float64(k))
+}
+
+func (d GeometricDist) LogPMF(k int64) float64 {
+	if k < 0 {
+		return negInf
+	}
+	return log(d.Rho) + xlogy(float64(k), 1-d.Rho)
+}
+
+func (d GeometricDist) CDF(k int64) float64 {
+	if k < 0 {
+		return 0
+	}
+	return -math.Expm1(float64(k+1) * math.Log1p(-d.Rho))
+}
+
+func (d GeometricDist) Survival(k int64) float64 { return exp(d.LogSurvival(k)) }
+
+func (d GeometricDist) LogCDF(k int64) float64 {
+	if k < 0 {
+		return negInf
+	}
+	return log1mexp(d.LogSurvival(k))
+}
+
+// P(X > k) = (1-ρ)^(k+1)
+func (d GeometricDist) LogSurvival(k int64) float64 {
+	if k < 0 {
+		return 0
+	}
+	return float64(k+1) * math.Log1p(-d.Rho)
+}
+
+func (d GeometricDist) Quantile(p float64) int64 {
+	// the closed form ceil(log(1-p) / log(1-ρ)) - 1 may be off by one after rounding
+	guess := int64(math.MaxInt64)
+	if x := math.Ceil(math.Log1p(-p)/math.Log1p(-d.Rho)) - 1; x < math.MaxInt64 {
+		guess = int64(x)
+	}
+	return discreteQuantile(d.CDF, p, guess, 0, math.MaxInt64)
+}
+
+func (d GeometricDist) Rand() int64            { return d.RandWith(DefaultRNG) }
+func (d GeometricDist) RandWith(rng RNG) int64 { return NextGeometricWith(rng, d.Rho) }
+func (d GeometricDist) Mean() float64          { return (1 - d.Rho) / d.Rho }
+func (d GeometricDist) Variance() float64      { return (1 - d.Rho) / (d.Rho * d.Rho) }
+
+func (d GeometricDist) Mode() int64         { return 0 }
+func (d GeometricDist) Skewness() float64   { return (2 - d.Rho) / sqrt(1-d.Rho) }
+func (d GeometricDist) ExKurtosis() float64 { return 6 + d.Rho*d.Rho/(1-d.Rho) }
+
+func (d GeometricDist) Entropy() float64 {
+	ρ := d.Rho
+	return (-xlogy(1-ρ, 1-ρ) - xlogy(ρ, ρ)) / ρ
+}
+
 func Geometric_PMF(ρ float64) func(i int64) float64 {
-	return func(n int64) float64 { return ρ * pow(ρ, float64(n)) }
+	return GeometricDist{ρ}.PMF
 }
 func Geometric_LnPMF(ρ float64) func(i int64) float64 {
-	return func(n int64) float64 { return log(ρ) + float64(n)*log(ρ) }
+	return GeometricDist{ρ}.LogPMF
 }
 
-//NextGeometric(ρ) => # of NextBernoulli(ρ) failures before one success
-func NextGeometric(ρ float64) int64 {
-	if NextBernoulli(ρ) == 1 {
-		return 1 + NextGeometric(ρ)
+// NextGeometric(ρ) => # of NextBernoulli(ρ) failures before one success
+func NextGeometric(ρ float64) int64 { return NextGeometricWith(DefaultRNG, ρ) }
+func NextGeometricWith(rng RNG, ρ float64) (k int64) {
+	for NextBernoulliWith(rng, ρ) == 0 {
+		k++
 	}
-	return 0
+	return
+}
+func Geometric(ρ float64) func() int64 { return GeometricDist{ρ}.Rand }
+
+func Geometric_CDF(ρ float64) func(k int64) float64 {
+	return GeometricDist{ρ}.CDF
+}
+
+// Inverse of the cumulative distribution function: the smallest k with P(X <= k) >= p
+func Geometric_InvCDF(ρ float64) func(p float64) int64 {
+	return GeometricDist{ρ}.Quantile
+}
+
+func Geometric_InvCDF_For(ρ, p float64) int64 {
+	return GeometricDist{ρ}.Quantile(p)
 }
-func Geometric(ρ float64) func() int64 { return func() int64 { return NextGeometric(ρ) } }
@@ -0,0 +1,415 @@
+// Goodness-of-fit tests
+
+package stat
+
+import (
+	"math"
+	"sort"
+
+	. "github.com/ematvey/go-fn/fn"
+)
+
+// sortedCopy returns the values of x in increasing order, leaving x alone.
+func sortedCopy(x []float64) []float64 {
+	s := append([]float64(nil), x...)
+	sort.Float64s(s)
+	return s
+}
+
+// checkSample rejects samples that are too short or hold NaN.
+func checkSample(name string, x []float64, min int) error {
+	if len(x) < min {
+		return invalidParameter("%s needs at least %d observations, got %d", name, min, len(x))
+	}
+	for _, xi := range x {
+		if math.IsNaN(xi) {
+			return invalidParameter("%s sample holds NaN", name)
+		}
+	}
+	return nil
+}
+
+// KSTest is the one-sample Kolmogorov-Smirnov test of x against the
+// continuous distribution with the given CDF, e.g. Normal_CDF(0, 1). The
+// statistic is D = sup |F_n(x) - F(x)|. The p-value is exact for up to 1000
+// observations and otherwise comes from the Kolmogorov distribution with
+// Stephens' correction.
+func KSTest(x []float64, cdf func(float64) float64) (TestResult, error) {
+	if err := checkSample("KSTest", x, 1); err != nil {
+		return TestResult{}, err
+	}
+	s := sortedCopy(x)
+	n := float64(len(s))
+	var d float64
+	for i, xi := range s {
+		F := cdf(xi)
+		d = math.Max(d, math.Max(float64(i+1)/n-F, F-float64(i)/n))
+	}
+	var p float64
+	if len(s) <= 1000 {
+		p = 1 - KolmogorovSmirnov_CDF_At(len(s), d)
+	} else {
+		p = KolmogorovDist{}.Survival((sqrt(n) + 0.12 + 0.11/sqrt(n)) * d)
+	}
+	return TestResult{Statistic: d, PValue: clampProbability(p)}, nil
+}
+
+// KSTest2 is the two-sample Kolmogorov-Smirnov test that x and y come from
+// the same continuous distribution, with D = sup |F_x(t) - F_y(t)|. The
+// p-value is exact when there are no ties and the product of the sample
+// sizes is below 10000, and asymptotic otherwise.
+func KSTest2(x, y []float64) (TestResult, error) {
+	if err := checkSample("KSTest2", x, 1); err != nil {
+		return TestResult{}, err
+	}
+	if err := checkSample("KSTest2", y, 1); err != nil {
+		return TestResult{}, err
+	}
+	sx, sy := sortedCopy(x), sortedCopy(y)
+	m, n := len(sx), len(sy)
+	var d float64
+	ties := false
+	for i, j := 0, 0; i < m && j < n; {
+		t := math.Min(sx[i], sy[j])
+		if sx[i] == sy[j] {
+			ties = true
+		}
+		for i < m && sx[i] == t {
+			i++
+		}
+		for j < n && sy[j] == t {
+			j++
+		}
+		d = math.Max(d, abs(float64(i)/float64(m)-float64(j)/float64(n)))
+	}
+	for i := 1; i < m && !ties; i++ {
+		ties = sx[i] == sx[i-1]
+	}
+	for j := 1; j < n && !ties; j++ {
+		ties = sy[j] == sy[j-1]
+	}
+	var p float64
+	if !ties && m*n < 10000 {
+		p = 1 - smirnovCDF(m, n, d)
+	} else {
+		en := sqrt(float64(m) * float64(n) / float64(m+n))
+		p = KolmogorovDist{}.Survival((en + 0.12 + 0.11/en) * d)
+	}
+	return TestResult{Statistic: d, PValue: clampProbability(p)}, nil
+}
+
+// smirnovCDF returns P(D < d) for the two-sample statistic of samples of
+// sizes m and n without ties, by counting the lattice paths that stay
+// within d of the diagonal.
+func smirnovCDF(m, n int, d float64) float64 {
+	if m > n {
+		m, n = n, m
+	}
+	md, nd := float64(m), float64(n)
+	q := (0.5 + math.Floor(d*md*nd-1e-7)) / (md * nd)
+	u := make([]float64, n+1)
+	for j := range u {
+		if float64(j)/nd <= q {
+			u[j] = 1
+		}
+	}
+	for i := 1; i <= m; i++ {
+		w := float64(i) / float64(i+n)
+		if float64(i)/md > q {
+			u[0] = 0
+		} else {
+			u[0] *= w
+		}
+		for j := 1; j <= n; j++ {
+			if abs(float64(i)/md-float64(j)/nd) > q {
+				u[j] = 0
+			} else {
+				u[j] = w*u[j] + u[j-1]
+			}
+		}
+	}
+	return u[n]
+}
+
+// ADTest is the Anderson-Darling test of x against the continuous
+// distribution with the given CDF, with
+// A² = -n - Σ (2i-1)/n [log F(x_(i)) + log(1 - F(x_(n+1-i)))].
+// The p-value uses the finite-sample approximation of Marsaglia and
+// Marsaglia (2004).
+func ADTest(x []float64, cdf func(float64) float64) (TestResult, error) {
+	if err := checkSample("ADTest", x, 1); err != nil {
+		return TestResult{}, err
+	}
+	s := sortedCopy(x)
+	n := len(s)
+	a := -float64(n)
+	for i := range s {
+		a -= float64(2*i+1) / float64(n) * (log(cdf(s[i])) + math.Log1p(-cdf(s[n-1-i])))
+	}
+	if math.IsNaN(a) {
+		a = math.Inf(1)
+	}
+	return TestResult{Statistic: a, PValue: clampProbability(1 - andersonDarlingCDF(n, a))}, nil
+}
+
+// andersonDarlingCDF approximates P(A² < z) for n observations by adinf
+// and errfix of Marsaglia and Marsaglia (2004).
+func andersonDarlingCDF(n int, z float64) float64 {
+	x := andersonDarlingInf(z)
+	nf := float64(n)
+	if x > 0.8 {
+		return x + (-130.2137+(745.2337-(1705.091-(1950.646-(1116.360-255.7844*x)*x)*x)*x)*x)/nf
+	}
+	c := 0.01265 + 0.1757/nf
+	if x < c {
+		v := x / c
+		v = sqrt(v) * (1 - v) * (49*v - 102)
+		return x + v*(0.0037/(nf*nf)+0.00078/nf+0.00006)/nf
+	}
+	v := (x - c) / (0.8 - c)
+	v = -0.00022633 + (6.54034-(14.6538-(14.458-(8.259-1.91864*v)*v)*v)*v)*v
+	return x + v*(0.04213+0.01365/nf)/nf
+}
+
+// andersonDarlingInf approximates the limiting distribution of A².
+func andersonDarlingInf(z float64) float64 {
+	switch {
+	case z <= 0:
+		return 0
+	case math.IsInf(z, 1):
+		return 1
+	case z < 2:
+		return exp(-1.2337141/z) / sqrt(z) * (2.00012 + (0.247105-(0.0649821-(0.0347962-(0.011672-0.00168691*z)*z)*z)*z)*z)
+	}
+	return exp(-exp(1.0776 - (2.30695-(0.43424-(0.082433-(0.008056-0.0003146*z)*z)*z)*z)*z))
+}
+
+// ADTest2 is the two-sample Anderson-Darling test of Scholz and Stephens
+// (1987) that x and y come from the same distribution, in the version that
+// allows ties. The p-value is asymptotic, after matching the variance of
+// the statistic to that of its limit.
+func ADTest2(x, y []float64) (TestResult, error) {
+	if err := checkSample("ADTest2", x, 2); err != nil {
+		return TestResult{}, err
+	}
+	if err := checkSample("ADTest2", y, 2); err != nil {
+		return TestResult{}, err
+	}
+	samples := [][]float64{sortedCopy(x), sortedCopy(y)}
+	z := sortedCopy(append(append([]float64(nil), x...), y...))
+	N := float64(len(z))
+
+	var a float64
+	for _, s := range samples {
+		ni := float64(len(s))
+		var inner float64
+		for j := 0; j < len(z); {
+			zj := z[j]
+			k := j
+			for k < len(z) && z[k] == zj {
+				k++
+			}
+			l := float64(k - j)
+			b := float64(j) + l/2
+			lo := sort.SearchFloat64s(s, zj)
+			hi := lo
+			for hi < len(s) && s[hi] == zj {
+				hi++
+			}
+			M := float64(lo) + float64(hi-lo)/2
+			if den := b*(N-b) - N*l/4; den > 0 {
+				inner += l / N * (N*M - ni*b) * (N*M - ni*b) / den
+			}
+			j = k
+		}
+		a += inner / ni
+	}
+	a *= (N - 1) / N
+
+	// finite-sample variance of the statistic, whose mean is 1 for all N
+	var h, g, tail float64
+	for i := len(z) - 1; i >= 1; i-- {
+		h += 1 / float64(i)
+	}
+	for i := len(z) - 2; i >= 1; i-- {
+		tail += 1 / float64(i+1)
+		g += tail / (N - float64(i))
+	}
+	H := 1/float64(len(x)) + 1/float64(len(y))
+	const k = 2
+	ca := (4*g-6)*(k-1) + (10-6*g)*H
+	cb := (2*g-4)*k*k + 8*h*k + (2*g-14*h-4)*H - 8*h + 4*g - 6
+	cc := (6*h+2*g-2)*k*k + (4*h-4*g+6)*k + (2*h-6)*H + 4*h
+	cd := (2*h+6)*k*k - 4*h*k
+	σ2 := (ca*N*N*N + cb*N*N + cc*N + cd) / ((N - 1) * (N - 2) * (N - 3))
+	σ2inf := 2 * (π*π - 9) / 3
+	zs := a
+	if N > 3 && σ2 > 0 {
+		zs = 1 + (a-1)*sqrt(σ2inf/σ2)
+	}
+	return TestResult{Statistic: a, PValue: clampProbability(1 - andersonDarlingInf(zs))}, nil
+}
+
+// CvMTest is the Cramér-von Mises test of x against the continuous
+// distribution with the given CDF, with
+// W² = 1/(12n) + Σ (F(x_(i)) - (2i-1)/(2n))².
+// The p-value comes from the limiting distribution after Stephens'
+// modification (W² - 0.4/n + 0.6/n²)(1 + 1/n).
+func CvMTest(x []float64, cdf func(float64) float64) (TestResult, error) {
+	if err := checkSample("CvMTest", x, 1); err != nil {
+		return TestResult{}, err
+	}
+	s := sortedCopy(x)
+	n := float64(len(s))
+	w := 1 / (12 * n)
+	for i, xi := range s {
+		u := cdf(xi) - float64(2*i+1)/(2*n)
+		w += u * u
+	}
+	ws := (w - 0.4/n + 0.6/(n*n)) * (1 + 1/n)
+	return TestResult{Statistic: w, PValue: clampProbability(1 - cramérVonMisesCDF(ws))}, nil
+}
+
+// CvMTest2 is the two-sample Cramér-von Mises test of Anderson (1962) that
+// x and y come from the same distribution, using midranks for ties. The
+// p-value is asymptotic, after standardizing the statistic by its exact
+// mean and variance.
+func CvMTest2(x, y []float64) (TestResult, error) {
+	if err := checkSample("CvMTest2", x, 2); err != nil {
+		return TestResult{}, err
+	}
+	if err := checkSample("CvMTest2", y, 2); err != nil {
+		return TestResult{}, err
+	}
+	nx, ny := float64(len(x)), float64(len(y))
+	N := nx + ny
+	r := midranks(append(append([]float64(nil), x...), y...))
+	rx, ry := sortedCopy(r[:len(x)]), sortedCopy(r[len(x):])
+	var ux, uy float64
+	for i, ri := range rx {
+		ux += (ri - float64(i+1)) * (ri - float64(i+1))
+	}
+	for j, rj := range ry {
+		uy += (rj - float64(j+1)) * (rj - float64(j+1))
+	}
+	k := nx * ny
+	u := nx*ux + ny*uy
+	t := u/(k*N) - (4*k-1)/(6*N)
+
+	et, vt := cvm2Moments(nx, ny)
+	tn := 1.0/6 + (t-et)/sqrt(45*vt)
+	return TestResult{Statistic: t, PValue: clampProbability(1 - cramérVonMisesCDF(tn))}, nil
+}
+
+// cvm2Moments returns the exact mean and variance of Anderson's T under the
+// null hypothesis for samples of sizes nx and ny without ties.
+func cvm2Moments(nx, ny float64) (et, vt float64) {
+	N, k := nx+ny, nx*ny
+	et = (1 + 1/N) / 6
+	vt = (N + 1) * (4*k*N - 3*(nx*nx+ny*ny) - 2*k) / (45 * N * N * 4 * k)
+	return
+}
+
+// midranks returns the ranks of x from 1 to len(x), giving tied values the
+// mean of the ranks they span.
+func midranks(x []float64) []float64 {
+	idx := make([]int, len(x))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool { return x[idx[a]] < x[idx[b]] })
+	r := make([]float64, len(x))
+	for i := 0; i < len(idx); {
+		j := i
+		for j < len(idx) && x[idx[j]] == x[idx[i]] {
+			j++
+		}
+		for k := i; k < j; k++ {
+			r[idx[k]] = float64(i+j+1) / 2
+		}
+		i = j
+	}
+	return r
+}
+
+// cramérVonMisesCDF is the limiting distribution of W², by the series of
+// Csörgő and Faraway (1996),
+// V(x) = 1/(π√x) Σ Γ(j+½)/(Γ(½) j!) √(4j+1) exp(-q_j) K_¼(q_j),
+// with q_j = (4j+1)²/(16x).
+func cramérVonMisesCDF(x float64) float64 {
+	switch {
+	case x <= 0:
+		return 0
+	case x > 20:
+		return 1
+	}
+	var s float64
+	for j := 0; j < 200; j++ {
+		y := float64(4*j + 1)
+		q := y * y / (16 * x)
+		c := exp(LnΓ(float64(j)+0.5) - LnΓ(float64(j)+1) - 0.5*log(π))
+		t := c * sqrt(y) * besselKExp(0.25, q)
+		s += t
+		if t < 1e-16*s {
+			break
+		}
+	}
+	return math.Min(s/(π*sqrt(x)), 1)
+}
+
+// besselKExp returns exp(-z) K_ν(z), the scaled modified Bessel function of
+// the second kind, from K_ν(z) = ∫ exp(-z cosh t) cosh(νt) dt over t > 0.
+func besselKExp(ν, z float64) float64 {
+	v, _ := integrate(func(t float64) float64 {
+		// cosh t - 1 = 2 sinh²(t/2) keeps the exponent accurate near 0
+		sh := math.Sinh(t / 2)
+		return exp(-2*z*sh*sh + ν*t + math.Log1p(exp(-2*ν*t)) - math.Ln2)
+	}, 0, math.Inf(1), 1e-13)
+	return v * exp(-2*z)
+}
+
+// ChiSquareTest is Pearson's chi-square goodness-of-fit test of the binned
+// counts observed against the expected ones, X² = Σ (O - E)²/E. The
+// expected counts are rescaled to the observed total, and nil means equal
+// expected counts. The statistic has k - 1 - ddof degrees of freedom, ddof
+// being the number of parameters estimated from the data, and the p-value
+// is the upper tail of Xsquare_CDF.
+func ChiSquareTest(observed, expected []float64, ddof int) (TestResult, error) {
+	k := len(observed)
+	if expected == nil {
+		expected = make([]float64, k)
+		for i := range expected {
+			expected[i] = 1
+		}
+	}
+	if len(expected) != k {
+		return TestResult{}, dimensionMismatch("ChiSquareTest with %d observed and %d expected counts", k, len(expected))
+	}
+	df := k - 1 - ddof
+	if df < 1 || ddof < 0 {
+		return TestResult{}, invalidParameter("ChiSquareTest with %d bins and ddof = %d", k, ddof)
+	}
+	var no, ne float64
+	for i := range observed {
+		if !(observed[i] >= 0) || !(expected[i] > 0) || math.IsInf(observed[i], 0) || math.IsInf(expected[i], 0) {
+			return TestResult{}, invalidParameter("ChiSquareTest bin %d: observed %v, expected %v", i, observed[i], expected[i])
+		}
+		no += observed[i]
+		ne += expected[i]
+	}
+	if !(no > 0) {
+		return TestResult{}, invalidParameter("ChiSquareTest with no observations")
+	}
+	var x2 float64
+	for i := range observed {
+		e := expected[i] * no / ne
+		x2 += (observed[i] - e) * (observed[i] - e) / e
+	}
+	p := XsquareDist{float64(df)}.Survival(x2)
+	return TestResult{Statistic: x2, PValue: p, DF: float64(df)}, nil
+}
+
+// clampProbability keeps rounding in approximate p-values within [0, 1].
+func clampProbability(p float64) float64 {
+	return math.Max(0, math.Min(1, p))
+}
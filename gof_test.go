@@ -0,0 +1,266 @@
+package stat
+
+import (
+	"math"
+	"testing"
+)
+
+func TestKolmogorovDist(t *testing.T) {
+	d := KolmogorovDist{}
+	// the two series agree where they meet, and with the usual critical values
+	check(t, "CDF(1)", d.CDF(1-1e-12), d.CDF(1), 1e-11)
+	check(t, "Survival(1.3581)", d.Survival(1.3581), 0.05, 1e-4)
+	check(t, "Survival(1.6276)", d.Survival(1.6276), 0.01, 1e-4)
+	check(t, "mass", quad(d.PDF, 0, 10, 1), 1, 1e-10)
+	check(t, "CDF", d.CDF(0.7), quad(d.PDF, 0, 0.7), 1e-10)
+	check(t, "LogSurvival", d.LogSurvival(8), math.Ln2-128, 1e-12)
+	μ := quad(func(x float64) float64 { return x * d.PDF(x) }, 0, 10, 1)
+	check(t, "Mean", d.Mean(), μ, 1e-10)
+	v := quad(func(x float64) float64 { return (x - μ) * (x - μ) * d.PDF(x) }, 0, 10, 1)
+	check(t, "Variance", d.Variance(), v, 1e-10)
+	m3 := quad(func(x float64) float64 { return math.Pow(x-μ, 3) * d.PDF(x) }, 0, 10, 1)
+	check(t, "Skewness", d.Skewness(), m3/math.Pow(v, 1.5), 1e-9)
+	m4 := quad(func(x float64) float64 { return math.Pow(x-μ, 4) * d.PDF(x) }, 0, 10, 1)
+	check(t, "ExKurtosis", d.ExKurtosis(), m4/(v*v)-3, 1e-9)
+	for _, p := range []float64{0.01, 0.5, 0.999} {
+		check(t, "CDF(Quantile)", d.CDF(d.Quantile(p)), p, 1e-12)
+	}
+	if m := d.Mode(); d.PDF(m) < d.PDF(m-1e-4) || d.PDF(m) < d.PDF(m+1e-4) {
+		t.Errorf("Mode %v is not a local maximum", m)
+	}
+}
+
+func TestKolmogorovSmirnovCDF(t *testing.T) {
+	// one observation: P(D_1 < d) = 2d - 1
+	for _, d := range []float64{0.6, 0.75, 0.9} {
+		check(t, "n = 1", KolmogorovSmirnov_CDF_At(1, d), 2*d-1, 1e-14)
+	}
+	// Marsaglia, Tsang and Wang (2003), Table 1
+	check(t, "n = 10", KolmogorovSmirnov_CDF_At(10, 0.274), 0.6284796154565043, 1e-12)
+
+	// against simulation
+	rng := NewRNG(5)
+	const reps = 100000
+	u := make([]float64, 10)
+	var below int
+	for r := 0; r < reps; r++ {
+		for i := range u {
+			u[i] = rng.Float64()
+		}
+		res, _ := KSTest(u, func(x float64) float64 { return x })
+		if res.Statistic < 0.3 {
+			below++
+		}
+	}
+	if got, want := float64(below)/reps, KolmogorovSmirnov_CDF_At(10, 0.3); abs(got-want) > 0.005 {
+		t.Errorf("simulated P(D_10 < 0.3) = %v, want %v", got, want)
+	}
+}
+
+func TestSmirnovCDF(t *testing.T) {
+	// enumerate all C(11, 5) assignments of ranks to the first sample
+	const m, n = 5, 6
+	count := map[float64]int{}
+	total := 0
+	for mask := 0; mask < 1<<(m+n); mask++ {
+		if popcount(mask) != m {
+			continue
+		}
+		var d float64
+		var i, j int
+		for b := 0; b < m+n; b++ {
+			if mask&(1<<b) != 0 {
+				i++
+			} else {
+				j++
+			}
+			d = math.Max(d, abs(float64(i)/m-float64(j)/n))
+		}
+		count[math.Round(d*m*n)]++
+		total++
+	}
+	for k := range count {
+		var less int
+		for k2, c := range count {
+			if k2 < k {
+				less += c
+			}
+		}
+		check(t, "smirnovCDF", smirnovCDF(m, n, k/(m*n)), float64(less)/float64(total), 1e-13)
+	}
+}
+
+func popcount(x int) (n int) {
+	for ; x != 0; x &= x - 1 {
+		n++
+	}
+	return
+}
+
+// cvm2Exact enumerates Anderson's T over all assignments of the ranks 1 to
+// m+n to a first sample of size m, returning the statistic of each.
+func cvm2Exact(m, n int) (masks []int, ts []float64) {
+	N := float64(m + n)
+	for mask := 0; mask < 1<<(m+n); mask++ {
+		if popcount(mask) != m {
+			continue
+		}
+		var u float64
+		var i, j int
+		for b := 0; b < m+n; b++ {
+			r := float64(b + 1)
+			if mask&(1<<b) != 0 {
+				i++
+				u += float64(m) * (r - float64(i)) * (r - float64(i))
+			} else {
+				j++
+				u += float64(n) * (r - float64(j)) * (r - float64(j))
+			}
+		}
+		k := float64(m * n)
+		masks = append(masks, mask)
+		ts = append(ts, u/(k*N)-(4*k-1)/(6*N))
+	}
+	return
+}
+
+func TestCvMTest2Exact(t *testing.T) {
+	// the moments of T against its permutation distribution
+	for _, c := range [][2]int{{4, 7}, {10, 10}, {6, 12}} {
+		_, ts := cvm2Exact(c[0], c[1])
+		m, v := sampleMoments(ts)
+		et, vt := cvm2Moments(float64(c[0]), float64(c[1]))
+		check(t, "E[T]", et, m, 1e-12)
+		check(t, "Var[T]", vt, v, 1e-12)
+	}
+
+	// near the 5% point of the permutation distribution the asymptotic
+	// p-value is close to the exact one
+	const m, n = 10, 10
+	masks, ts := cvm2Exact(m, n)
+	tail := func(t0 float64) float64 {
+		var c int
+		for _, ti := range ts {
+			if ti >= t0-1e-12 {
+				c++
+			}
+		}
+		return float64(c) / float64(len(ts))
+	}
+	for i, mask := range masks {
+		if p := tail(ts[i]); p < 0.045 || p > 0.055 {
+			continue
+		}
+		var x, y []float64
+		for b := 0; b < m+n; b++ {
+			if mask&(1<<b) != 0 {
+				x = append(x, float64(b+1))
+			} else {
+				y = append(y, float64(b+1))
+			}
+		}
+		r, err := CvMTest2(x, y)
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, "T", r.Statistic, ts[i], 1e-12)
+		if p := tail(ts[i]); abs(r.PValue-p) > 0.01 {
+			t.Errorf("CvMTest2 p-value %v, exact %v", r.PValue, p)
+		}
+		break
+	}
+}
+
+func TestAsymptoticGOF(t *testing.T) {
+	// upper 5% and 1% points of the limiting distributions
+	check(t, "A² 5%", andersonDarlingInf(2.492), 0.95, 2e-4)
+	check(t, "A² 1%", andersonDarlingInf(3.8781), 0.99, 2e-4)
+	check(t, "W² 5%", cramérVonMisesCDF(0.46136), 0.95, 2e-4)
+	check(t, "W² 1%", cramérVonMisesCDF(0.74346), 0.99, 2e-4)
+	check(t, "W² 50%", cramérVonMisesCDF(0.11888), 0.5, 2e-4)
+	// K_½(z) = √(π/(2z)) exp(-z)
+	for _, z := range []float64{0.01, 1, 30} {
+		check(t, "besselKExp", besselKExp(0.5, z), sqrt(π/(2*z))*exp(-2*z), 1e-10)
+	}
+}
+
+func TestGOF(t *testing.T) {
+	rng := NewRNG(6)
+	x := make([]float64, 200)
+	y := make([]float64, 150)
+	for i := range x {
+		x[i] = rng.NormFloat64()
+	}
+	for i := range y {
+		y[i] = rng.NormFloat64()
+	}
+	z := Normal_CDF(0, 1)
+	shifted := Normal_CDF(0.5, 1)
+	for _, c := range []struct {
+		name string
+		test func([]float64, func(float64) float64) (TestResult, error)
+	}{{"KSTest", KSTest}, {"ADTest", ADTest}, {"CvMTest", CvMTest}} {
+		if r, err := c.test(x, z); err != nil || r.PValue < 0.01 {
+			t.Errorf("%s rejects a true null: %+v, %v", c.name, r, err)
+		}
+		if r, _ := c.test(x, shifted); r.PValue > 1e-4 {
+			t.Errorf("%s accepts a shifted null: %+v", c.name, r)
+		}
+	}
+	w := make([]float64, len(y))
+	for i := range w {
+		w[i] = y[i] + 0.7
+	}
+	for _, c := range []struct {
+		name string
+		test func(x, y []float64) (TestResult, error)
+	}{{"KSTest2", KSTest2}, {"ADTest2", ADTest2}, {"CvMTest2", CvMTest2}} {
+		if r, err := c.test(x, y); err != nil || r.PValue < 0.01 {
+			t.Errorf("%s rejects a true null: %+v, %v", c.name, r, err)
+		}
+		if r, _ := c.test(x, w); r.PValue > 1e-4 {
+			t.Errorf("%s accepts a shift: %+v", c.name, r)
+		}
+	}
+
+	// statistics by hand
+	u := []float64{0.1, 0.4, 0.7}
+	uniform := func(x float64) float64 { return x }
+	r, _ := KSTest(u, uniform)
+	check(t, "KS D", r.Statistic, 0.3, 1e-15)
+	r, _ = CvMTest(u, uniform)
+	check(t, "CvM W²", r.Statistic, 1.0/36+(1.0/225+0.01+4.0/225), 1e-15)
+	r, _ = ADTest(u, uniform)
+	a := -3 - (log(0.1)+log(0.3)+3*(log(0.4)+log(0.6))+5*(log(0.7)+log(0.9)))/3
+	check(t, "AD A²", r.Statistic, a, 1e-14)
+	r, _ = KSTest2([]float64{1, 2, 3}, []float64{2.5, 4, 5, 6})
+	check(t, "KS2 D", r.Statistic, 0.75, 1e-15)
+
+	if _, err := KSTest(nil, z); err == nil {
+		t.Error("empty sample accepted")
+	}
+}
+
+func TestChiSquareTest(t *testing.T) {
+	o := []float64{18, 22, 31, 29}
+	r, err := ChiSquareTest(o, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// chisq.test(c(18, 22, 31, 29)) in R
+	check(t, "X²", r.Statistic, 4.4, 1e-14)
+	check(t, "DF", r.DF, 3, 0)
+	check(t, "p", r.PValue, 1-Xsquare_CDF(3)(4.4), 1e-14)
+
+	// expected counts are rescaled, and ddof removes degrees of freedom
+	r2, _ := ChiSquareTest(o, []float64{0.25, 0.25, 0.25, 0.25}, 1)
+	check(t, "rescaled X²", r2.Statistic, 4.4, 1e-14)
+	check(t, "ddof p", r2.PValue, XsquareDist{2}.Survival(4.4), 1e-14)
+
+	if _, err := ChiSquareTest(o, []float64{1, 2}, 0); err == nil {
+		t.Error("mismatched lengths accepted")
+	}
+	if _, err := ChiSquareTest(o, nil, 3); err == nil {
+		t.Error("zero degrees of freedom accepted")
+	}
+}
@@ -0,0 +1,231 @@
+// Histograms
+
+package stat
+
+import (
+	"math"
+	"sort"
+)
+
+// BinRule chooses the number of bins of a histogram of a sample.
+type BinRule int
+
+const (
+	SturgesBins          BinRule = iota // ⌈log₂ n + 1⌉, R's nclass.Sturges
+	ScottBins                           // width 3.5 σ n^(-1/3), R's nclass.scott
+	FreedmanDiaconisBins                // width 2 IQR n^(-1/3), R's nclass.FD
+)
+
+// Bins returns the number of equal-width bins the rule gives for x, at
+// least 1. The width rules divide the range of x by the width and round
+// up; Freedman-Diaconis falls back on Scott when the interquartile range
+// vanishes. It returns 0 for an unknown rule.
+func (r BinRule) Bins(x []float64) int {
+	n := float64(len(x))
+	if n < 2 {
+		return 1
+	}
+	var h float64
+	switch r {
+	case SturgesBins:
+		return int(math.Ceil(math.Log2(n) + 1))
+	case ScottBins:
+		h = 3.5 * sampleSD(x) * pow(n, -1.0/3)
+	case FreedmanDiaconisBins:
+		e, _ := NewEmpiricalDist(x)
+		h = 2 * (e.QuantileType(0.75, 7) - e.QuantileType(0.25, 7)) * pow(n, -1.0/3)
+		if !(h > 0) {
+			return ScottBins.Bins(x)
+		}
+	default:
+		return 0
+	}
+	lo, hi := x[0], x[0]
+	for _, xi := range x {
+		lo, hi = math.Min(lo, xi), math.Max(hi, xi)
+	}
+	if !(h > 0) {
+		return 1
+	}
+	return int(math.Max(1, math.Ceil((hi-lo)/h)))
+}
+
+// Histogram counts observations in bins between increasing Edges: bin i
+// is [Edges[i], Edges[i+1]), except that the last bin also includes its
+// upper edge. Counts holds the weight in each bin, and Under and Over the
+// weight below the first edge and above the last. A Histogram should be
+// created by one of the constructors, which check the edges.
+type Histogram struct {
+	Edges       []float64
+	Counts      []float64
+	Under, Over float64
+}
+
+// NewHistogram returns an empty histogram with the given edges, which
+// must be finite and strictly increasing, at least two of them.
+func NewHistogram(edges []float64) (*Histogram, error) {
+	if len(edges) < 2 {
+		return nil, invalidParameter("histogram with %d edges", len(edges))
+	}
+	for i, e := range edges {
+		if math.IsNaN(e) || math.IsInf(e, 0) || i > 0 && !(e > edges[i-1]) {
+			return nil, invalidParameter("histogram edge %d = %v", i, e)
+		}
+	}
+	return &Histogram{
+		Edges:  append([]float64(nil), edges...),
+		Counts: make([]float64, len(edges)-1),
+	}, nil
+}
+
+// NewUniformHistogram returns an empty histogram of bins of equal width
+// from lo to hi.
+func NewUniformHistogram(lo, hi float64, bins int) (*Histogram, error) {
+	if bins < 1 || !(lo < hi) {
+		return nil, invalidParameter("histogram of %d bins from %v to %v", bins, lo, hi)
+	}
+	edges := make([]float64, bins+1)
+	for i := range edges {
+		edges[i] = lo + (hi-lo)*float64(i)/float64(bins)
+	}
+	edges[bins] = hi
+	return NewHistogram(edges)
+}
+
+// NewLogHistogram returns an empty histogram of bins of equal width on a
+// logarithmic scale from lo to hi, each edge a constant factor above the
+// last, as suits positive data spanning orders of magnitude.
+func NewLogHistogram(lo, hi float64, bins int) (*Histogram, error) {
+	if bins < 1 || !(lo > 0 && lo < hi) {
+		return nil, invalidParameter("logarithmic histogram of %d bins from %v to %v", bins, lo, hi)
+	}
+	edges := make([]float64, bins+1)
+	r := log(hi / lo)
+	for i := range edges {
+		edges[i] = lo * exp(r*float64(i)/float64(bins))
+	}
+	edges[0], edges[bins] = lo, hi
+	return NewHistogram(edges)
+}
+
+// HistogramOf returns the histogram of x in bins of equal width from its
+// minimum to its maximum, the number of bins chosen by rule. If all the
+// observations are equal, the single bin extends 0.5 either side of them.
+func HistogramOf(x []float64, rule BinRule) (*Histogram, error) {
+	if err := checkSample("HistogramOf", x, 1); err != nil {
+		return nil, err
+	}
+	bins := rule.Bins(x)
+	if bins < 1 {
+		return nil, invalidParameter("histogram bin rule %d", rule)
+	}
+	lo, hi := x[0], x[0]
+	for _, xi := range x {
+		lo, hi = math.Min(lo, xi), math.Max(hi, xi)
+	}
+	if lo == hi {
+		lo, hi, bins = lo-0.5, hi+0.5, 1
+	}
+	h, err := NewUniformHistogram(lo, hi, bins)
+	if err != nil {
+		return nil, err
+	}
+	for _, xi := range x {
+		h.Add(xi)
+	}
+	return h, nil
+}
+
+// Bins returns the number of bins.
+func (h *Histogram) Bins() int { return len(h.Counts) }
+
+// Add adds the observation x with weight 1.
+func (h *Histogram) Add(x float64) { h.AddWeighted(x, 1) }
+
+// AddWeighted adds the observation x with weight w. It panics if x is NaN
+// or w is negative or not finite.
+func (h *Histogram) AddWeighted(x, w float64) {
+	if !(w >= 0) || math.IsInf(w, 0) || math.IsNaN(x) {
+		panic(invalidParameter("histogram observation %v with weight %v", x, w))
+	}
+	k := len(h.Counts)
+	switch {
+	case x < h.Edges[0]:
+		h.Under += w
+	case x > h.Edges[k]:
+		h.Over += w
+	case x == h.Edges[k]:
+		h.Counts[k-1] += w
+	default:
+		// the first edge above x closes its bin
+		i := sort.Search(k+1, func(i int) bool { return h.Edges[i] > x })
+		h.Counts[i-1] += w
+	}
+}
+
+// Total returns the total weight, including Under and Over.
+func (h *Histogram) Total() float64 {
+	t := h.Under + h.Over
+	for _, c := range h.Counts {
+		t += c
+	}
+	return t
+}
+
+// Density returns the count of each bin divided by the total weight and
+// the width of the bin, so that it estimates the probability density. It
+// integrates to the fraction of the weight within the edges.
+func (h *Histogram) Density() []float64 {
+	t := h.Total()
+	d := make([]float64, len(h.Counts))
+	for i, c := range h.Counts {
+		d[i] = c / (t * (h.Edges[i+1] - h.Edges[i]))
+	}
+	return d
+}
+
+// Cumulative returns the weight at or below the upper edge of each bin,
+// including Under.
+func (h *Histogram) Cumulative() []float64 {
+	c := make([]float64, len(h.Counts))
+	s := h.Under
+	for i, ci := range h.Counts {
+		s += ci
+		c[i] = s
+	}
+	return c
+}
+
+// Expected returns the counts expected in the bins if the total weight
+// were drawn from the distribution with the given CDF, such as
+// NormalDist{0, 1}.CDF or Xsquare_CDF(3): the total times the probability
+// between the edges of each bin.
+func (h *Histogram) Expected(cdf func(float64) float64) []float64 {
+	t := h.Total()
+	e := make([]float64, len(h.Counts))
+	lo := cdf(h.Edges[0])
+	for i := range e {
+		hi := cdf(h.Edges[i+1])
+		e[i] = t * (hi - lo)
+		lo = hi
+	}
+	return e
+}
+
+// ChiSquareTest is the chi-square goodness-of-fit test of the histogram
+// against the distribution with the given CDF, ddof being the number of
+// its parameters estimated from the data; see the function ChiSquareTest.
+// The first and last bins are extended to cover the whole line, taking in
+// Under and Over and the tails of the distribution, so the expected counts
+// account for all the weight.
+func (h *Histogram) ChiSquareTest(cdf func(float64) float64, ddof int) (TestResult, error) {
+	k := len(h.Counts)
+	o := append([]float64(nil), h.Counts...)
+	o[0] += h.Under
+	o[k-1] += h.Over
+	e := h.Expected(cdf)
+	t := h.Total()
+	e[0] += t * cdf(h.Edges[0])
+	e[k-1] += t * (1 - cdf(h.Edges[k]))
+	return ChiSquareTest(o, e, ddof)
+}
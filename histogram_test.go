@@ -0,0 +1,121 @@
+package stat
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBinRules(t *testing.T) {
+	x := make([]float64, 100)
+	for i := range x {
+		x[i] = float64(i + 1)
+	}
+	// nclass.Sturges, nclass.scott and nclass.FD of 1:100 in R
+	for _, c := range []struct {
+		r    BinRule
+		bins int
+	}{{SturgesBins, 8}, {ScottBins, 5}, {FreedmanDiaconisBins, 5}} {
+		if b := c.r.Bins(x); b != c.bins {
+			t.Errorf("rule %d: %d bins, want %d", c.r, b, c.bins)
+		}
+	}
+	// the interquartile range of mostly ties vanishes
+	y := []float64{0, 1, 1, 1, 1, 1, 1, 1, 1, 2}
+	if b, s := FreedmanDiaconisBins.Bins(y), ScottBins.Bins(y); b != s {
+		t.Errorf("Freedman-Diaconis gave %d bins, Scott %d", b, s)
+	}
+	if b := BinRule(7).Bins(x); b != 0 {
+		t.Errorf("unknown rule gave %d bins", b)
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	h, err := NewUniformHistogram(0, 4, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, x := range []float64{-1, 0, 0.5, 1, 2.5, 3.9, 4, 4.1} {
+		h.Add(x)
+	}
+	h.AddWeighted(1.5, 2)
+	want := []float64{2, 3, 1, 2}
+	for i := range want {
+		check(t, "Counts", h.Counts[i], want[i], 0)
+	}
+	check(t, "Under", h.Under, 1, 0)
+	check(t, "Over", h.Over, 1, 0)
+	check(t, "Total", h.Total(), 10, 0)
+	check(t, "Cumulative", h.Cumulative()[1], 6, 0)
+	check(t, "Density", h.Density()[1], 0.3, 1e-15)
+
+	l, _ := NewLogHistogram(1, 1000, 3)
+	for i, e := range []float64{1, 10, 100, 1000} {
+		check(t, "log edge", l.Edges[i], e, 1e-14)
+	}
+
+	// a histogram of a sample integrates to 1
+	rng := NewRNG(9)
+	x := make([]float64, 1000)
+	for i := range x {
+		x[i] = NextGammaWith(rng, 3, 2)
+	}
+	for _, r := range []BinRule{SturgesBins, ScottBins, FreedmanDiaconisBins} {
+		h, err := HistogramOf(x, r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var mass float64
+		for i, d := range h.Density() {
+			mass += d * (h.Edges[i+1] - h.Edges[i])
+		}
+		check(t, "mass", mass, 1, 1e-14)
+		if c := h.Cumulative(); c[len(c)-1] != 1000 || h.Bins() != r.Bins(x) {
+			t.Errorf("rule %d: %d bins with cumulative count %v", r, h.Bins(), c[len(c)-1])
+		}
+	}
+	if h, _ := HistogramOf([]float64{2, 2}, SturgesBins); h.Bins() != 1 || h.Counts[0] != 2 {
+		t.Errorf("histogram of ties: %v", h)
+	}
+
+	for _, err := range []error{
+		func() error { _, err := NewHistogram([]float64{0, 1, 1}); return err }(),
+		func() error { _, err := NewHistogram([]float64{0}); return err }(),
+		func() error { _, err := NewUniformHistogram(0, 1, 0); return err }(),
+		func() error { _, err := NewLogHistogram(0, 1, 3); return err }(),
+		func() error { _, err := HistogramOf(nil, ScottBins); return err }(),
+	} {
+		if !errors.Is(err, ErrInvalidParameter) {
+			t.Errorf("expected ErrInvalidParameter, got %v", err)
+		}
+	}
+}
+
+func TestHistogramChiSquare(t *testing.T) {
+	h, _ := NewUniformHistogram(-2, 2, 8)
+	rng := NewRNG(10)
+	for i := 0; i < 2000; i++ {
+		h.Add(rng.NormFloat64())
+	}
+	d := NormalDist{0, 1}
+	e := h.Expected(d.CDF)
+	check(t, "Expected", e[3], 2000*(d.CDF(0)-d.CDF(-0.5)), 1e-12)
+
+	// the end bins take in the tails
+	o := append([]float64(nil), h.Counts...)
+	o[0] += h.Under
+	o[7] += h.Over
+	e[0] += 2000 * d.CDF(-2)
+	e[7] += 2000 * d.Survival(2)
+	want, _ := ChiSquareTest(o, e, 0)
+	r, err := h.ChiSquareTest(d.CDF, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, "X²", r.Statistic, want.Statistic, 1e-12)
+	if r.DF != 7 || r.PValue < 0.001 {
+		t.Errorf("Normal sample against the Normal: %+v", r)
+	}
+	if r, _ := h.ChiSquareTest(Normal_CDF(0.3, 1), 0); r.PValue > 1e-6 {
+		t.Errorf("Normal sample against a shifted Normal: %+v", r)
+	}
+}
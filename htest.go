@@ -0,0 +1,53 @@
+// Hypothesis tests
+
+package stat
+
+import (
+	"math"
+)
+
+// TestResult is the outcome of a hypothesis test: the test statistic, its
+// p-value under the null hypothesis and, for tests whose null distribution
+// has them, the degrees of freedom; DF is zero otherwise.
+type TestResult struct {
+	Statistic, PValue, DF float64
+}
+
+// Alternative is the alternative hypothesis of a test whose statistic has
+// a direction.
+type Alternative int
+
+const (
+	TwoSided Alternative = iota // the parameter differs from its null value
+	Less                        // the parameter is below its null value
+	Greater                     // the parameter is above its null value
+)
+
+func (a Alternative) valid() bool { return a >= TwoSided && a <= Greater }
+
+func checkConfidence(name string, conf float64) error {
+	if !(conf > 0 && conf < 1) {
+		return invalidParameter("%s confidence level %v", name, conf)
+	}
+	return nil
+}
+
+// pValue returns the p-value of the statistic s, distributed as d under the
+// null hypothesis, against the alternative a; d must be symmetric about 0
+// for a two-sided test.
+func pValue(d ContinuousDistribution, s float64, a Alternative) float64 {
+	switch a {
+	case Less:
+		return d.CDF(s)
+	case Greater:
+		return d.Survival(s)
+	}
+	return math.Min(1, 2*d.Survival(abs(s)))
+}
+
+// FTestResult is the outcome of a test whose statistic has an F
+// distribution with DF1 and DF2 degrees of freedom under the null
+// hypothesis.
+type FTestResult struct {
+	Statistic, PValue, DF1, DF2 float64
+}
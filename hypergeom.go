@@ -0,0 +1,170 @@
+// Hypergeometric distribution
+
+package stat
+
+import (
+	"math"
+
+	. "github.com/ematvey/go-fn/fn"
+)
+
+// Hypergeometric distribution: number of successes in Draws draws without
+// replacement from a population of N items of which K are successes
+type HypergeometricDist struct {
+	N, K, Draws int64
+}
+
+// NewHypergeometricDist returns a Hypergeometric distribution, checking
+// that 0 <= k <= N and 0 <= draws <= N.
+func NewHypergeometricDist(N, k, draws int64) (HypergeometricDist, error) {
+	if N < 0 || k < 0 || k > N || draws < 0 || draws > N {
+		return HypergeometricDist{}, invalidParameter("Hypergeometric N = %d, K = %d, draws = %d", N, k, draws)
+	}
+	return HypergeometricDist{N, k, draws}, nil
+}
+
+// support returns the smallest and largest possible numbers of successes.
+func (d HypergeometricDist) support() (lo, hi int64) {
+	lo = d.Draws + d.K - d.N
+	if lo < 0 {
+		lo = 0
+	}
+	hi = d.K
+	if d.Draws < hi {
+		hi = d.Draws
+	}
+	return
+}
+
+func (d HypergeometricDist) PMF(k int64) float64 {
+	if lo, hi := d.support(); k < lo || k > hi {
+		return 0
+	}
+	return exp(d.LogPMF(k))
+}
+
+func (d HypergeometricDist) LogPMF(k int64) float64 {
+	if lo, hi := d.support(); k < lo || k > hi {
+		return negInf
+	}
+	return lnChoose(d.K, k) + lnChoose(d.N-d.K, d.Draws-k) - lnChoose(d.N, d.Draws)
+}
+
+// lnChoose returns the logarithm of the binomial coefficient C(n, k).
+func lnChoose(n, k int64) float64 {
+	return LnΓ(float64(n+1)) - LnΓ(float64(k+1)) - LnΓ(float64(n-k+1))
+}
+
+func (d HypergeometricDist) CDF(k int64) float64      { return exp(d.LogCDF(k)) }
+func (d HypergeometricDist) Survival(k int64) float64 { return exp(d.LogSurvival(k)) }
+
+// The tails are summed term by term, which keeps them accurate when small.
+
+func (d HypergeometricDist) LogCDF(k int64) float64 {
+	lo, hi := d.support()
+	switch {
+	case k < lo:
+		return negInf
+	case k >= hi:
+		return 0
+	}
+	return d.lnSum(lo, k)
+}
+
+func (d HypergeometricDist) LogSurvival(k int64) float64 {
+	lo, hi := d.support()
+	switch {
+	case k < lo:
+		return 0
+	case k >= hi:
+		return negInf
+	}
+	return d.lnSum(k+1, hi)
+}
+
+// lnSum returns the logarithm of the probability of [a, b].
+func (d HypergeometricDist) lnSum(a, b int64) float64 {
+	lp := make([]float64, 0, b-a+1)
+	for k := a; k <= b; k++ {
+		lp = append(lp, d.LogPMF(k))
+	}
+	return math.Min(0, logSumExp(lp))
+}
+
+func (d HypergeometricDist) Quantile(p float64) int64 {
+	lo, hi := d.support()
+	guess := quantileGuess(d.Mean(), sqrt(d.Variance()), d.Skewness(), p, lo, hi)
+	return discreteQuantile(d.CDF, p, guess, lo, hi)
+}
+
+func (d HypergeometricDist) Rand() int64 { return d.RandWith(DefaultRNG) }
+func (d HypergeometricDist) RandWith(rng RNG) int64 {
+	return NextHypergeometricWith(rng, d.N, d.K, d.Draws)
+}
+
+func (d HypergeometricDist) Mean() float64 {
+	return float64(d.Draws) * float64(d.K) / float64(d.N)
+}
+
+func (d HypergeometricDist) Variance() float64 {
+	N, K, n := float64(d.N), float64(d.K), float64(d.Draws)
+	return n * K / N * (N - K) / N * (N - n) / (N - 1)
+}
+
+func (d HypergeometricDist) Mode() int64 {
+	return (d.Draws + 1) * (d.K + 1) / (d.N + 2)
+}
+
+func (d HypergeometricDist) Skewness() float64 {
+	N, K, n := float64(d.N), float64(d.K), float64(d.Draws)
+	return (N - 2*K) * sqrt(N-1) * (N - 2*n) / (sqrt(n*K*(N-K)*(N-n)) * (N - 2))
+}
+
+func (d HypergeometricDist) ExKurtosis() float64 {
+	N, K, n := float64(d.N), float64(d.K), float64(d.Draws)
+	a := (N-1)*N*N*(N*(N+1)-6*K*(N-K)-6*n*(N-n)) + 6*n*K*(N-K)*(N-n)*(5*N-6)
+	return a / (n * K * (N - K) * (N - n) * (N - 2) * (N - 3))
+}
+
+func (d HypergeometricDist) Entropy() float64 {
+	lo, hi := d.support()
+	return discreteEntropy(d.PMF, d.Mode(), lo, hi)
+}
+
+// Probability Mass Function for the Hypergeometric distribution
+func Hypergeometric_PMF(N, k, draws int64) func(i int64) float64 {
+	return HypergeometricDist{N, k, draws}.PMF
+}
+
+// Natural logarithm of Probability Mass Function for the Hypergeometric distribution
+func Hypergeometric_LnPMF(N, k, draws int64) func(i int64) float64 {
+	return HypergeometricDist{N, k, draws}.LogPMF
+}
+
+// Cumulative Distribution Function for the Hypergeometric distribution
+func Hypergeometric_CDF(N, k, draws int64) func(i int64) float64 {
+	return HypergeometricDist{N, k, draws}.CDF
+}
+
+// Inverse of the cumulative distribution function: the smallest i with P(X <= i) >= p
+func Hypergeometric_InvCDF(N, k, draws int64) func(p float64) int64 {
+	return HypergeometricDist{N, k, draws}.Quantile
+}
+
+// NextHypergeometric draws the items one at a time.
+func NextHypergeometric(N, k, draws int64) int64 {
+	return NextHypergeometricWith(DefaultRNG, N, k, draws)
+}
+
+func NextHypergeometricWith(rng RNG, N, k, draws int64) (result int64) {
+	for i := iZero; i < draws; i++ {
+		if rng.Float64()*float64(N-i) < float64(k-result) {
+			result++
+		}
+	}
+	return
+}
+
+func Hypergeometric(N, k, draws int64) func() int64 {
+	return HypergeometricDist{N, k, draws}.Rand
+}
@@ -8,32 +8,126 @@ import (
 	. "github.com/ematvey/go-fn/fn"
 )
 
+// Inverse Gamma distribution with shape A and scale B
+type InvGammaDist struct {
+	A, B float64
+}
+
+// NewInvGammaDist returns an Inverse Gamma distribution, checking that a > 0 and b > 0.
+func NewInvGammaDist(a, b float64) (InvGammaDist, error) {
+	if !(a > 0) || !(b > 0) || math.IsInf(a, 0) || math.IsInf(b, 0) {
+		return InvGammaDist{}, invalidParameter("InvGamma a = %v, b = %v", a, b)
+	}
+	return InvGammaDist{a, b}, nil
+}
+
+func (d InvGammaDist) PDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	return math.Exp(d.LogPDF(x))
+}
+
+func (d InvGammaDist) LogPDF(x float64) float64 {
+	if x <= 0 {
+		return negInf
+	}
+	return d.A*math.Log(d.B) - LnΓ(d.A) - (d.A+1)*math.Log(x) - d.B/x
+}
+
+func (d InvGammaDist) logPDFs(dst, x []float64) {
+	lnNorm := d.A*math.Log(d.B) - LnΓ(d.A)
+	for i, xi := range x {
+		if xi <= 0 {
+			dst[i] = negInf
+			continue
+		}
+		dst[i] = lnNorm - (d.A+1)*math.Log(xi) - d.B/xi
+	}
+}
+
+// If X ~ InvGamma(a, b) then 1/X ~ Gamma(a, 1/b)
+func (d InvGammaDist) CDF(x float64) float64      { return math.Exp(d.LogCDF(x)) }
+func (d InvGammaDist) Survival(x float64) float64 { return math.Exp(d.LogSurvival(x)) }
+
+func (d InvGammaDist) LogCDF(x float64) float64 {
+	if x <= 0 {
+		return negInf
+	}
+	_, lnQ := lnGammaIncReg(d.A, d.B/x)
+	return lnQ
+}
+
+func (d InvGammaDist) LogSurvival(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	lnP, _ := lnGammaIncReg(d.A, d.B/x)
+	return lnP
+}
+
+func (d InvGammaDist) Quantile(p float64) float64 {
+	return quantile(d, p, d.B/gammaQuantileGuess(d.A, 1-p), true)
+}
+
+func (d InvGammaDist) Rand() float64            { return d.RandWith(DefaultRNG) }
+func (d InvGammaDist) RandWith(rng RNG) float64 { return 1 / NextGammaWith(rng, d.A, d.B) }
+
+func (d InvGammaDist) Mean() float64 {
+	if d.A <= 1 {
+		return math.Inf(1)
+	}
+	return d.B / (d.A - 1)
+}
+
+func (d InvGammaDist) Variance() float64 {
+	switch {
+	case d.A <= 1:
+		return math.NaN()
+	case d.A <= 2:
+		return math.Inf(1)
+	}
+	return d.B * d.B / ((d.A - 1) * (d.A - 1) * (d.A - 2))
+}
+
+func (d InvGammaDist) Mode() float64 { return d.B / (d.A + 1) }
+
+func (d InvGammaDist) Skewness() float64 {
+	if d.A <= 3 {
+		return math.NaN()
+	}
+	return 4 * math.Sqrt(d.A-2) / (d.A - 3)
+}
+
+func (d InvGammaDist) ExKurtosis() float64 {
+	if d.A <= 4 {
+		return math.NaN()
+	}
+	return (30*d.A - 66) / ((d.A - 3) * (d.A - 4))
+}
+
+func (d InvGammaDist) Entropy() float64 {
+	return d.A + math.Log(d.B) + LnΓ(d.A) - (1+d.A)*digamma(d.A)
+}
+
 // Inverse Gamma distribution: probability density function
 func InvGamma_PDF(a, b float64) func(x float64) float64 {
-	return func(x float64) float64 {
-		return math.Exp(a*math.Log(b) - LnΓ(a) - (a+1)*math.Log(x) - b*1.0/x)
-	}
+	return InvGammaDist{a, b}.PDF
 }
 
 // Inverse Gamma distribution: natural logarithm of the probability density function
 func InvGamma_LnPDF(a, b float64) func(x float64) float64 {
-	return func(x float64) float64 {
-		return a*math.Log(b) - LnΓ(a) - (a+1)*math.Log(x) - b*1.0/x
-	}
+	return InvGammaDist{a, b}.LogPDF
 }
 
 // Inverse Gamma distribution: probability density function at x
 func InvGamma_PDF_At(a, b float64) func(x float64) float64 {
-	return func(x float64) float64 {
-		return math.Exp(a*math.Log(b) - LnΓ(a) - (a+1)*math.Log(x) - b*1.0/x)
-	}
+	return InvGammaDist{a, b}.PDF
 }
 
 // Inverse Gamma distribution: cumulative distribution function
 func InvGamma_CDF(a, b float64) func(x float64) float64 {
-	return func(x float64) float64 {
-		return 1 - IΓ(a, b*1.0/x)
-	}
+	return InvGammaDist{a, b}.CDF
 }
 
 // Inverse Gamma distribution: value of the cumulative distribution function at x
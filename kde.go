@@ -0,0 +1,405 @@
+// Kernel density estimation
+
+package stat
+
+import (
+	"math"
+	"sort"
+)
+
+// Kernel is a symmetric smoothing kernel for KDEDist. Every kernel is scaled
+// to unit variance, so that a bandwidth is the standard deviation of the
+// kernel whatever its shape, as in R's density.
+type Kernel int
+
+const (
+	GaussianKernel Kernel = iota // the Normal PDF, the default
+	EpanechnikovKernel
+	UniformKernel
+	TriangularKernel
+	BiweightKernel
+)
+
+// halfWidth is the support of the kernel on its natural scale [-1, 1]
+// expressed in standard deviations, i.e. 1/sqrt(var) of the unscaled kernel.
+func (k Kernel) halfWidth() float64 {
+	switch k {
+	case EpanechnikovKernel:
+		return math.Sqrt(5)
+	case UniformKernel:
+		return math.Sqrt(3)
+	case TriangularKernel:
+		return math.Sqrt(6)
+	case BiweightKernel:
+		return math.Sqrt(7)
+	}
+	return math.Inf(1)
+}
+
+// pdf returns the density of the unit variance kernel at u.
+func (k Kernel) pdf(u float64) float64 {
+	if k == GaussianKernel {
+		return exp(-u*u/2) / math.Sqrt(2*π)
+	}
+	a := k.halfWidth()
+	t := u / a
+	if t <= -1 || t >= 1 {
+		return 0
+	}
+	var f float64
+	switch k {
+	case EpanechnikovKernel:
+		f = 0.75 * (1 - t*t)
+	case UniformKernel:
+		f = 0.5
+	case TriangularKernel:
+		f = 1 - abs(t)
+	case BiweightKernel:
+		f = 15.0 / 16 * (1 - t*t) * (1 - t*t)
+	}
+	return f / a
+}
+
+// logCDF returns the log of the distribution function of the unit variance
+// kernel at u.
+func (k Kernel) logCDF(u float64) float64 {
+	if k == GaussianKernel {
+		return lnNormalCDF(u)
+	}
+	t := u / k.halfWidth()
+	switch {
+	case t <= -1:
+		return negInf
+	case t >= 1:
+		return 0
+	}
+	var F float64
+	switch k {
+	case EpanechnikovKernel:
+		F = (2 + 3*t - t*t*t) / 4
+	case UniformKernel:
+		F = (1 + t) / 2
+	case TriangularKernel:
+		if t < 0 {
+			F = (1 + t) * (1 + t) / 2
+		} else {
+			F = 1 - (1-t)*(1-t)/2
+		}
+	case BiweightKernel:
+		F = 0.5 + 15.0/16*(t-2*t*t*t/3+t*t*t*t*t/5)
+	}
+	return log(F)
+}
+
+// rand draws from the unit variance kernel.
+func (k Kernel) rand(rng RNG) float64 {
+	var t float64
+	switch k {
+	case GaussianKernel:
+		return rng.NormFloat64()
+	case EpanechnikovKernel:
+		t = 2*NextBetaWith(rng, 2, 2) - 1
+	case UniformKernel:
+		t = 2*rng.Float64() - 1
+	case TriangularKernel:
+		t = rng.Float64() + rng.Float64() - 1
+	case BiweightKernel:
+		t = 2*NextBetaWith(rng, 3, 3) - 1
+	}
+	return k.halfWidth() * t
+}
+
+// kurtosis returns the fourth moment of the unit variance kernel.
+func (k Kernel) kurtosis() float64 {
+	switch k {
+	case EpanechnikovKernel:
+		return 15.0 / 7
+	case UniformKernel:
+		return 9.0 / 5
+	case TriangularKernel:
+		return 12.0 / 5
+	case BiweightKernel:
+		return 7.0 / 3
+	}
+	return 3
+}
+
+// KDEDist is a kernel density estimate: the average of Kernel scaled by
+// Bandwidth and centred on each observation. It must be created by
+// NewKDEDist, which keeps a sorted copy of the data.
+type KDEDist struct {
+	Kernel    Kernel
+	Bandwidth float64
+	x         []float64
+}
+
+// NewKDEDist returns the kernel density estimate of x with kernel k and
+// bandwidth bw, the standard deviation of the kernel. A bandwidth of 0
+// selects SilvermanBandwidth(x).
+func NewKDEDist(x []float64, k Kernel, bw float64) (KDEDist, error) {
+	if k < GaussianKernel || k > BiweightKernel {
+		return KDEDist{}, invalidParameter("KDE kernel %d", k)
+	}
+	e, err := NewEmpiricalDist(x)
+	if err != nil {
+		return KDEDist{}, err
+	}
+	if bw == 0 {
+		bw = SilvermanBandwidth(x)
+	}
+	if !(bw > 0) || math.IsInf(bw, 0) {
+		return KDEDist{}, invalidParameter("KDE bandwidth %v", bw)
+	}
+	return KDEDist{k, bw, e.x}, nil
+}
+
+// window returns the range of observations within the kernel support of x.
+func (d KDEDist) window(x float64) (lo, hi int) {
+	r := d.Kernel.halfWidth() * d.Bandwidth
+	if math.IsInf(r, 1) {
+		return 0, len(d.x)
+	}
+	lo = sort.SearchFloat64s(d.x, x-r)
+	hi = sort.Search(len(d.x), func(i int) bool { return d.x[i] > x+r })
+	return
+}
+
+func (d KDEDist) PDF(x float64) float64 {
+	lo, hi := d.window(x)
+	var f float64
+	for _, xi := range d.x[lo:hi] {
+		f += d.Kernel.pdf((x - xi) / d.Bandwidth)
+	}
+	return f / (float64(len(d.x)) * d.Bandwidth)
+}
+
+func (d KDEDist) LogPDF(x float64) float64 {
+	if d.Kernel != GaussianKernel {
+		return log(d.PDF(x))
+	}
+	// in logs, so that the density does not underflow far from the data
+	l := make([]float64, len(d.x))
+	for i, xi := range d.x {
+		u := (x - xi) / d.Bandwidth
+		l[i] = -u * u / 2
+	}
+	return logSumExp(l) - 0.5*log(2*π) - log(float64(len(d.x))*d.Bandwidth)
+}
+
+func (d KDEDist) CDF(x float64) float64      { return exp(d.LogCDF(x)) }
+func (d KDEDist) Survival(x float64) float64 { return exp(d.LogSurvival(x)) }
+
+func (d KDEDist) LogCDF(x float64) float64 {
+	return d.logTail(func(xi float64) float64 { return (x - xi) / d.Bandwidth })
+}
+
+// by symmetry of the kernel, P(X > x) averages K((xi - x) / h)
+func (d KDEDist) LogSurvival(x float64) float64 {
+	return d.logTail(func(xi float64) float64 { return (xi - x) / d.Bandwidth })
+}
+
+func (d KDEDist) logTail(u func(xi float64) float64) float64 {
+	l := make([]float64, len(d.x))
+	for i, xi := range d.x {
+		l[i] = d.Kernel.logCDF(u(xi))
+	}
+	return logSumExp(l) - log(float64(len(d.x)))
+}
+
+// Quantile inverts the CDF numerically, starting from the empirical
+// quantile of the data.
+func (d KDEDist) Quantile(p float64) float64 {
+	return quantile(d, p, EmpiricalDist{d.x}.QuantileType(p, 7), false)
+}
+
+// Grid evaluates the density and distribution function at m equally spaced
+// points from lo to hi inclusive.
+func (d KDEDist) Grid(lo, hi float64, m int) (x, pdf, cdf []float64) {
+	x, pdf, cdf = make([]float64, m), make([]float64, m), make([]float64, m)
+	for i := range x {
+		if m == 1 {
+			x[i] = lo
+		} else {
+			x[i] = lo + (hi-lo)*float64(i)/float64(m-1)
+		}
+		pdf[i] = d.PDF(x[i])
+		cdf[i] = d.CDF(x[i])
+	}
+	return
+}
+
+func (d KDEDist) Rand() float64 { return d.RandWith(DefaultRNG) }
+
+// RandWith resamples an observation and adds kernel noise to it.
+func (d KDEDist) RandWith(rng RNG) float64 {
+	return d.x[NextRangeWith(rng, int64(len(d.x)))] + d.Bandwidth*d.Kernel.rand(rng)
+}
+
+// The moments are those of the data plus independent kernel noise.
+
+func (d KDEDist) Mean() float64 { return EmpiricalDist{d.x}.Mean() }
+
+func (d KDEDist) Variance() float64 {
+	return EmpiricalDist{d.x}.Variance() + d.Bandwidth*d.Bandwidth
+}
+
+func (d KDEDist) Skewness() float64 {
+	return EmpiricalDist{d.x}.centralMoment(3) / pow(d.Variance(), 1.5)
+}
+
+func (d KDEDist) ExKurtosis() float64 {
+	e := EmpiricalDist{d.x}
+	h2 := d.Bandwidth * d.Bandwidth
+	m4 := e.centralMoment(4) + 6*h2*e.Variance() + h2*h2*d.Kernel.kurtosis()
+	v := d.Variance()
+	return m4/(v*v) - 3
+}
+
+// Mode returns the highest point of the density, found by evaluating it at
+// the observations and refining the best by golden-section search.
+func (d KDEDist) Mode() float64 {
+	best := d.x[0]
+	fBest := d.PDF(best)
+	for _, xi := range d.x[1:] {
+		if f := d.PDF(xi); f > fBest {
+			best, fBest = xi, f
+		}
+	}
+	a, b := best-d.Bandwidth, best+d.Bandwidth
+	return goldenMax(d.PDF, a, b, 1e-10*d.Bandwidth)
+}
+
+// Entropy is computed by numerical integration.
+func (d KDEDist) Entropy() float64 {
+	return integratePieces(func(x float64) float64 {
+		l := d.LogPDF(x)
+		if math.IsInf(l, -1) {
+			return 0
+		}
+		return -exp(l) * l
+	}, d.cuts(), 1e-10)
+}
+
+// cuts returns the observations and the ends of the kernel supports around
+// them, where the density may have corners; for the unbounded Gaussian
+// kernel the ends are those of the real line.
+func (d KDEDist) cuts() []float64 {
+	r := d.Kernel.halfWidth() * d.Bandwidth
+	if math.IsInf(r, 1) {
+		return append([]float64{math.Inf(-1), math.Inf(1)}, d.x...)
+	}
+	c := make([]float64, 0, 3*len(d.x))
+	for _, xi := range d.x {
+		c = append(c, xi-r, xi, xi+r)
+	}
+	return c
+}
+
+// goldenMax returns a local maximum of f in [a, b] by golden-section search.
+func goldenMax(f func(float64) float64, a, b, tol float64) float64 {
+	const g = 0.6180339887498949
+	c, d := b-g*(b-a), a+g*(b-a)
+	fc, fd := f(c), f(d)
+	for b-a > tol {
+		if fc >= fd {
+			b, d, fd = d, c, fc
+			c = b - g*(b-a)
+			fc = f(c)
+		} else {
+			a, c, fc = c, d, fd
+			d = a + g*(b-a)
+			fd = f(d)
+		}
+	}
+	return (a + b) / 2
+}
+
+// SilvermanBandwidth returns Silverman's rule of thumb
+// 0.9 min(σ, IQR/1.34) n^(-1/5), R's bw.nrd0. It falls back on σ, or on the
+// magnitude of the data, when the spread measures vanish.
+func SilvermanBandwidth(x []float64) float64 {
+	return 0.9 * robustSpread(x) * pow(float64(len(x)), -0.2)
+}
+
+// ScottBandwidth returns Scott's rule 1.06 σ n^(-1/5), which is optimal for
+// Normal data.
+func ScottBandwidth(x []float64) float64 {
+	return 1.06 * sampleSD(x) * pow(float64(len(x)), -0.2)
+}
+
+// CVBandwidth returns the bandwidth that minimizes the least-squares
+// cross-validation estimate of the integrated squared error of a Gaussian
+// kernel estimate, R's bw.ucv. It costs O(n²) per trial bandwidth, and the
+// search is confined to [0.1, 1] times a Normal-reference upper bound.
+// There is nothing to cross-validate with fewer than two observations, and
+// it returns SilvermanBandwidth(x) instead.
+func CVBandwidth(x []float64) float64 {
+	if len(x) < 2 {
+		return SilvermanBandwidth(x)
+	}
+	n := float64(len(x))
+	hmax := 1.144 * robustSpread(x) * pow(n, -0.2)
+	var d []float64
+	for i := range x {
+		for j := 0; j < i; j++ {
+			d = append(d, x[i]-x[j])
+		}
+	}
+	lscv := func(lh float64) float64 {
+		h := exp(lh)
+		var s float64
+		for _, dij := range d {
+			u := dij / h
+			s += exp(-u*u/4)/math.Sqrt(2) - 2*n/(n-1)*exp(-u*u/2)
+		}
+		// ∫ f² - 2 mean of the leave-one-out densities, over 1/sqrt(2π)
+		return (1/(math.Sqrt(2)*n*h) + 2*s/(n*n*h)) / math.Sqrt(2*π)
+	}
+	// LSCV often has several local minima: scan, then refine the best
+	a, b := log(0.1*hmax), log(hmax)
+	const steps = 40
+	best, fBest := a, lscv(a)
+	for i := 1; i <= steps; i++ {
+		if lh := a + (b-a)*float64(i)/steps; lscv(lh) < fBest {
+			best, fBest = lh, lscv(lh)
+		}
+	}
+	step := (b - a) / steps
+	lh := goldenMax(func(lh float64) float64 { return -lscv(lh) },
+		math.Max(a, best-step), math.Min(b, best+step), 1e-8)
+	return exp(lh)
+}
+
+// robustSpread returns min(σ, IQR/1.34), falling back on whichever is
+// non-zero, and then on |x[0]|, or 1.
+func robustSpread(x []float64) float64 {
+	e, err := NewEmpiricalDist(x)
+	if err != nil {
+		return math.NaN()
+	}
+	σ := sampleSD(x)
+	s := math.Min(σ, (e.QuantileType(0.75, 7)-e.QuantileType(0.25, 7))/1.34)
+	switch {
+	case s > 0:
+		return s
+	case σ > 0:
+		return σ
+	case x[0] != 0:
+		return abs(x[0])
+	}
+	return 1
+}
+
+// sampleSD returns the standard deviation of x with divisor n-1.
+func sampleSD(x []float64) float64 {
+	n := float64(len(x))
+	var m, ss float64
+	for _, xi := range x {
+		m += xi
+	}
+	m /= n
+	for _, xi := range x {
+		ss += (xi - m) * (xi - m)
+	}
+	return sqrt(ss / (n - 1))
+}
@@ -0,0 +1,121 @@
+package stat
+
+import (
+	"math"
+	"testing"
+)
+
+var kernels = []Kernel{GaussianKernel, EpanechnikovKernel, UniformKernel, TriangularKernel, BiweightKernel}
+
+// quad integrates adaptively, breaking at the corners of the kernels.
+func quad(f func(float64) float64, a, b float64, cuts ...float64) float64 {
+	var c []float64
+	for _, x := range append(cuts, a, b) {
+		if x >= a && x <= b {
+			c = append(c, x)
+		}
+	}
+	return integratePieces(f, c, 1e-12)
+}
+
+func TestKernels(t *testing.T) {
+	rng := NewRNG(2)
+	for _, k := range kernels {
+		a := math.Min(k.halfWidth(), 40)
+		check(t, "kernel mass", quad(k.pdf, -a, a), 1, 1e-10)
+		v := quad(func(u float64) float64 { return u * u * k.pdf(u) }, -a, a)
+		check(t, "kernel variance", v, 1, 1e-10)
+		m4 := quad(func(u float64) float64 { return u * u * u * u * k.pdf(u) }, -a, a)
+		check(t, "kernel kurtosis", k.kurtosis(), m4, 1e-10)
+		for _, u := range []float64{-1.5, 0.3, 1} {
+			F := quad(k.pdf, -a, u)
+			check(t, "kernel CDF", exp(k.logCDF(u)), F, 1e-10)
+		}
+
+		const n = 100000
+		var s, s2 float64
+		for i := 0; i < n; i++ {
+			u := k.rand(rng)
+			if abs(u) > k.halfWidth() {
+				t.Fatalf("kernel %d drew %v outside its support", k, u)
+			}
+			s += u
+			s2 += u * u
+		}
+		if m, v := s/n, s2/n; abs(m) > 0.02 || abs(v-1) > 0.02 {
+			t.Errorf("kernel %d draws have mean %v and variance %v", k, m, v)
+		}
+	}
+}
+
+func TestKDE(t *testing.T) {
+	x := []float64{1.2, 1.9, 2.3, 4.8, 5, 5.1, 7.4}
+	for _, k := range kernels {
+		d, err := NewKDEDist(x, k, 0.8)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lo, hi := -10.0, 18.0
+		cuts := d.cuts()
+		check(t, "mass", quad(d.PDF, lo, hi, cuts...), 1, 1e-10)
+		check(t, "CDF", d.CDF(3), quad(d.PDF, lo, 3, cuts...), 1e-10)
+		check(t, "Survival", d.Survival(3)+d.CDF(3), 1, 1e-14)
+		μ := quad(func(x float64) float64 { return x * d.PDF(x) }, lo, hi, cuts...)
+		check(t, "Mean", d.Mean(), μ, 1e-10)
+		v := quad(func(x float64) float64 { return (x - μ) * (x - μ) * d.PDF(x) }, lo, hi, cuts...)
+		check(t, "Variance", d.Variance(), v, 1e-10)
+		m4 := quad(func(x float64) float64 { return math.Pow(x-μ, 4) * d.PDF(x) }, lo, hi, cuts...)
+		check(t, "ExKurtosis", d.ExKurtosis(), m4/(v*v)-3, 1e-9)
+		h := quad(func(x float64) float64 {
+			if f := d.PDF(x); f > 0 {
+				return -f * log(f)
+			}
+			return 0
+		}, lo, hi, cuts...)
+		check(t, "Entropy", d.Entropy(), h, 1e-9)
+		for _, p := range []float64{0.05, 0.5, 0.9} {
+			check(t, "CDF(Quantile)", d.CDF(d.Quantile(p)), p, 1e-12)
+		}
+		if m := d.Mode(); d.PDF(m) < d.PDF(m-1e-3) || d.PDF(m) < d.PDF(m+1e-3) {
+			t.Errorf("kernel %d: Mode %v is not a local maximum", k, m)
+		}
+		gx, pdf, cdf := d.Grid(0, 8, 5)
+		if gx[4] != 8 || pdf[2] != d.PDF(4) || cdf[1] != d.CDF(2) {
+			t.Errorf("kernel %d: Grid disagrees with PDF and CDF", k)
+		}
+	}
+
+	// one point and a Gaussian kernel give a Normal distribution
+	d, _ := NewKDEDist([]float64{2}, GaussianKernel, 1.5)
+	want := NormalDist{2, 1.5}
+	for _, x := range []float64{-40, 0, 3} {
+		check(t, "LogPDF", d.LogPDF(x), want.LogPDF(x), 1e-13)
+		check(t, "LogCDF", d.LogCDF(x), want.LogCDF(x), 1e-13)
+	}
+
+	if _, err := NewKDEDist(x, GaussianKernel, -1); err == nil {
+		t.Error("negative bandwidth accepted")
+	}
+}
+
+func TestBandwidth(t *testing.T) {
+	x := make([]float64, 10)
+	for i := range x {
+		x[i] = float64(i + 1)
+	}
+	// bw.nrd0(1:10) and bw.nrd(1:10) in R use the sample standard deviation
+	check(t, "Silverman", SilvermanBandwidth(x), 0.9*3.0276503540974917*math.Pow(10, -0.2), 1e-14)
+	check(t, "Scott", ScottBandwidth(x), 1.06*3.0276503540974917*math.Pow(10, -0.2), 1e-14)
+
+	rng := NewRNG(4)
+	y := make([]float64, 400)
+	for i := range y {
+		y[i] = rng.NormFloat64()
+	}
+	// for Normal data the cross-validated bandwidth is near the reference one
+	if h, ref := CVBandwidth(y), ScottBandwidth(y); h < 0.5*ref || h > 1.5*ref {
+		t.Errorf("CVBandwidth = %v, Scott = %v", h, ref)
+	}
+	// a single observation leaves nothing to cross-validate
+	check(t, "CVBandwidth of one point", CVBandwidth(y[:1]), SilvermanBandwidth(y[:1]), 0)
+}
@@ -0,0 +1,231 @@
+// Kolmogorov distribution
+
+package stat
+
+import (
+	"math"
+
+	. "github.com/ematvey/go-fn/fn"
+)
+
+// KolmogorovDist is the distribution of the supremum of the absolute value
+// of a Brownian bridge, the limit of √n D_n for the Kolmogorov-Smirnov
+// statistic D_n.
+type KolmogorovDist struct{}
+
+// For x < 1 the CDF is √(2π)/x Σ exp(-(2k-1)²π²/(8x²)) over k >= 1, and for
+// x >= 1 the survival function is 2 Σ (-1)^(k-1) exp(-2k²x²); each series
+// needs only a few terms on its side of 1.
+
+const kolmogorovTerms = 20
+
+func (KolmogorovDist) PDF(x float64) float64 {
+	switch {
+	case x <= 0:
+		return 0
+	case x < 1:
+		var s float64
+		for k := 1; k <= kolmogorovTerms; k++ {
+			a := float64((2*k-1)*(2*k-1)) * π * π / 8
+			s += exp(-a/(x*x)) * (2*a/(x*x) - 1)
+		}
+		return math.Sqrt(2*π) * s / (x * x)
+	}
+	var s float64
+	for k := 1; k <= kolmogorovTerms; k++ {
+		k2 := float64(k * k)
+		t := k2 * exp(-2*k2*x*x)
+		if k%2 == 0 {
+			t = -t
+		}
+		s += t
+	}
+	return 8 * x * s
+}
+
+func (d KolmogorovDist) LogPDF(x float64) float64 { return log(d.PDF(x)) }
+func (d KolmogorovDist) CDF(x float64) float64    { return exp(d.LogCDF(x)) }
+func (d KolmogorovDist) Survival(x float64) float64 {
+	return exp(d.LogSurvival(x))
+}
+
+func (KolmogorovDist) LogCDF(x float64) float64 {
+	switch {
+	case x <= 0:
+		return negInf
+	case x < 1:
+		// factor out the leading term so that it cannot underflow
+		a1 := π * π / 8
+		var s float64
+		for k := 1; k <= kolmogorovTerms; k++ {
+			a := float64((2*k-1)*(2*k-1)) * π * π / 8
+			s += exp(-(a - a1) / (x * x))
+		}
+		return 0.5*log(2*π) - log(x) - a1/(x*x) + log(s)
+	}
+	return log1mexp(kolmogorovLogSurvival(x))
+}
+
+func (d KolmogorovDist) LogSurvival(x float64) float64 {
+	if x < 1 {
+		return log1mexp(d.LogCDF(x))
+	}
+	return kolmogorovLogSurvival(x)
+}
+
+// kolmogorovLogSurvival is log P(K > x) for x >= 1, factoring out the
+// leading term 2 exp(-2x²).
+func kolmogorovLogSurvival(x float64) float64 {
+	if math.IsInf(x, 1) {
+		return negInf
+	}
+	var s float64
+	for k := 1; k <= kolmogorovTerms; k++ {
+		t := exp(-2 * float64(k*k-1) * x * x)
+		if k%2 == 0 {
+			t = -t
+		}
+		s += t
+	}
+	return math.Ln2 - 2*x*x + log(s)
+}
+
+func (d KolmogorovDist) Quantile(p float64) float64 { return quantile(d, p, 0.83, true) }
+func (d KolmogorovDist) Rand() float64              { return d.RandWith(DefaultRNG) }
+func (d KolmogorovDist) RandWith(rng RNG) float64   { return d.Quantile(rng.Float64()) }
+
+// The moments follow from E[K^s] = s Γ(s/2) η(s) / 2^(s/2), with η the
+// Dirichlet eta function.
+
+const (
+	kolmogorovMean = 0.86873116063615915 // √(π/2) log 2
+	apéry          = 1.2020569031595943  // ζ(3)
+)
+
+func (KolmogorovDist) Mean() float64 { return kolmogorovMean }
+
+func (KolmogorovDist) Variance() float64 {
+	return π*π/12 - kolmogorovMean*kolmogorovMean
+}
+
+func (d KolmogorovDist) Skewness() float64 {
+	μ, v := kolmogorovMean, d.Variance()
+	m3 := 3 * Γ(1.5) * 0.75 * apéry / math.Pow(2, 1.5)
+	return (m3 - 3*μ*v - μ*μ*μ) / math.Pow(v, 1.5)
+}
+
+func (d KolmogorovDist) ExKurtosis() float64 {
+	μ, v := kolmogorovMean, d.Variance()
+	m2 := π * π / 12
+	m3 := 3 * Γ(1.5) * 0.75 * apéry / math.Pow(2, 1.5)
+	m4 := 7 * math.Pow(π, 4) / 720
+	c4 := m4 - 4*μ*m3 + 6*μ*μ*m2 - 3*μ*μ*μ*μ
+	return c4/(v*v) - 3
+}
+
+// Mode is found by golden-section search.
+func (d KolmogorovDist) Mode() float64 { return goldenMax(d.PDF, 0.5, 1.2, 1e-12) }
+
+// Entropy is computed by numerical integration.
+func (d KolmogorovDist) Entropy() float64 {
+	return integratePieces(func(x float64) float64 {
+		f := d.PDF(x)
+		if f <= 0 {
+			return 0
+		}
+		return -f * log(f)
+	}, []float64{0, 1, math.Inf(1)}, 1e-12)
+}
+
+// Kolmogorov_CDF_At returns the limiting probability P(√n D_n <= x).
+func Kolmogorov_CDF_At(x float64) float64 { return KolmogorovDist{}.CDF(x) }
+
+// KolmogorovSmirnov_CDF_At returns P(D_n < d) for the one-sample
+// Kolmogorov-Smirnov statistic D_n of n observations from a continuous
+// distribution, by the method of Marsaglia, Tsang and Wang (2003). Far in
+// the upper tail, where the exact value is indistinguishable from 1, it uses
+// their asymptotic approximation, which keeps the complement accurate.
+func KolmogorovSmirnov_CDF_At(n int, d float64) float64 {
+	nf := float64(n)
+	switch {
+	case d <= 0.5/nf:
+		return 0
+	case d >= 1:
+		return 1
+	}
+	if s := d * d * nf; s > 7.24 || (s > 3.76 && n > 99) {
+		return 1 - 2*exp(-(2.000071+0.331/sqrt(nf)+1.409/nf)*s)
+	}
+	k := int(nf*d) + 1
+	m := 2*k - 1
+	h := float64(k) - nf*d
+	H := make([]float64, m*m)
+	for i := 0; i < m; i++ {
+		for j := 0; j < m; j++ {
+			if i-j+1 >= 0 {
+				H[i*m+j] = 1
+			}
+		}
+	}
+	for i := 0; i < m; i++ {
+		H[i*m] -= math.Pow(h, float64(i+1))
+		H[(m-1)*m+i] -= math.Pow(h, float64(m-i))
+	}
+	if 2*h-1 > 0 {
+		H[(m-1)*m] += math.Pow(2*h-1, float64(m))
+	}
+	for i := 0; i < m; i++ {
+		for j := 0; j < m; j++ {
+			if i-j+1 > 0 {
+				H[i*m+j] /= Γ(float64(i - j + 2))
+			}
+		}
+	}
+	Q, e := ksMatrixPower(H, m, n)
+	s := Q[(k-1)*m+k-1]
+	for i := 1; i <= n; i++ {
+		s = s * float64(i) / nf
+		if s < 1e-140 {
+			s *= 1e140
+			e -= 140
+		}
+	}
+	return s * math.Pow(10, float64(e))
+}
+
+// ksMatrixPower returns A^n as a matrix and a power of ten, rescaling as it
+// goes to avoid overflow.
+func ksMatrixPower(A []float64, m, n int) ([]float64, int) {
+	if n == 1 {
+		return append([]float64(nil), A...), 0
+	}
+	V, e := ksMatrixPower(A, m, n/2)
+	B := ksMatrixMultiply(V, V, m)
+	e *= 2
+	if n%2 == 1 {
+		B = ksMatrixMultiply(A, B, m)
+	}
+	if B[(m/2)*m+m/2] > 1e140 {
+		for i := range B {
+			B[i] *= 1e-140
+		}
+		e += 140
+	}
+	return B, e
+}
+
+func ksMatrixMultiply(A, B []float64, m int) []float64 {
+	C := make([]float64, m*m)
+	for i := 0; i < m; i++ {
+		for k := 0; k < m; k++ {
+			a := A[i*m+k]
+			if a == 0 {
+				continue
+			}
+			for j := 0; j < m; j++ {
+				C[i*m+j] += a * B[k*m+j]
+			}
+		}
+	}
+	return C
+}
@@ -1,33 +1,35 @@
 package stat
 
 import (
-	"fmt"
 	mx "github.com/skelterjohn/go.matrix"
 	"math"
 )
 
-func checkMatrixNormal(M, Omega, Sigma *mx.DenseMatrix) {
+func checkMatrixNormal(M, Omega, Sigma *mx.DenseMatrix) error {
 	p := M.Rows()
 	m := M.Cols()
 	if Omega.Rows() != p {
-		panic(fmt.Sprintf("Omega.Rows != M.Rows, %d != %d", Omega.Rows(), p))
+		return dimensionMismatch("Omega.Rows != M.Rows, %d != %d", Omega.Rows(), p)
 	}
 	if Omega.Cols() != Omega.Rows() {
-		panic("Omega is not square")
+		return dimensionMismatch("Omega is not square")
 	}
 	if Sigma.Rows() != m {
-		panic(fmt.Sprintf("Sigma.Cols != M.Cols, %d != %d", Sigma.Cols(), m))
+		return dimensionMismatch("Sigma.Cols != M.Cols, %d != %d", Sigma.Cols(), m)
 	}
 	if Sigma.Cols() != Sigma.Rows() {
-		panic("Sigma is not square")
+		return dimensionMismatch("Sigma is not square")
 	}
+	return nil
 }
 
 /*
- M is the mean, Omega is the row covariance, Sigma is the column covariance.
+M is the mean, Omega is the row covariance, Sigma is the column covariance.
 */
 func MatrixNormal_PDF(M, Omega, Sigma *mx.DenseMatrix) func(A *mx.DenseMatrix) float64 {
-	checkMatrixNormal(M, Omega, Sigma)
+	if err := checkMatrixNormal(M, Omega, Sigma); err != nil {
+		panic(err)
+	}
 	pf := float64(M.Rows())
 	mf := float64(M.Cols())
 
@@ -75,7 +77,9 @@ func MatrixNormal_PDF(M, Omega, Sigma *mx.DenseMatrix) func(A *mx.DenseMatrix) f
 	}
 }
 func MatrixNormal_LnPDF(M, Omega, Sigma *mx.DenseMatrix) func(A *mx.DenseMatrix) float64 {
-	checkMatrixNormal(M, Omega, Sigma)
+	if err := checkMatrixNormal(M, Omega, Sigma); err != nil {
+		panic(err)
+	}
 
 	pf := float64(M.Rows())
 	mf := float64(M.Cols())
@@ -124,11 +128,16 @@ func MatrixNormal_LnPDF(M, Omega, Sigma *mx.DenseMatrix) func(A *mx.DenseMatrix)
 	}
 }
 func MatrixNormal(M, Omega, Sigma *mx.DenseMatrix) func() (X *mx.DenseMatrix) {
-	checkMatrixNormal(M, Omega, Sigma)
+	return MatrixNormalWith(DefaultRNG, M, Omega, Sigma)
+}
+func MatrixNormalWith(rng RNG, M, Omega, Sigma *mx.DenseMatrix) func() (X *mx.DenseMatrix) {
+	if err := checkMatrixNormal(M, Omega, Sigma); err != nil {
+		panic(err)
+	}
 
 	Mv := mx.Vectorize(M)
 	Cov := mx.Kronecker(Omega, Sigma)
-	normal := MVNormal(Mv, Cov)
+	normal := MVNormalWith(rng, Mv, Cov)
 	return func() (X *mx.DenseMatrix) {
 		Xv := normal()
 		X = mx.Unvectorize(Xv, M.Rows(), M.Cols())
@@ -138,3 +147,88 @@ func MatrixNormal(M, Omega, Sigma *mx.DenseMatrix) func() (X *mx.DenseMatrix) {
 func NextMatrixNormal(M, Omega, Sigma *mx.DenseMatrix) (X *mx.DenseMatrix) {
 	return MatrixNormal(M, Omega, Sigma)()
 }
+func NextMatrixNormalWith(rng RNG, M, Omega, Sigma *mx.DenseMatrix) (X *mx.DenseMatrix) {
+	return MatrixNormalWith(rng, M, Omega, Sigma)()
+}
+
+// Matrix Normal distribution with mean M (p x m), row covariance Omega (p x p)
+// and column covariance Sigma (m x m)
+type MatrixNormalDist struct {
+	M, Omega, Sigma *mx.DenseMatrix
+
+	omegaChol, sigmaChol *mx.DenseMatrix
+	omegaInv, sigmaInv   *mx.DenseMatrix
+	lnNorm               float64
+}
+
+// NewMatrixNormalDist returns a Matrix Normal distribution, checking the
+// dimensions of M, Omega and Sigma and that both covariances are symmetric
+// positive definite.
+func NewMatrixNormalDist(M, Omega, Sigma *mx.DenseMatrix) (*MatrixNormalDist, error) {
+	if err := checkMatrixNormal(M, Omega, Sigma); err != nil {
+		return nil, err
+	}
+	Lo, err := checkCovariance("MatrixNormal Omega", Omega, M.Rows())
+	if err != nil {
+		return nil, err
+	}
+	Ls, err := checkCovariance("MatrixNormal Sigma", Sigma, M.Cols())
+	if err != nil {
+		return nil, err
+	}
+	oinv, err := Omega.Inverse()
+	if err != nil {
+		return nil, invalidParameter("MatrixNormal Omega: %v", err)
+	}
+	sinv, err := Sigma.Inverse()
+	if err != nil {
+		return nil, invalidParameter("MatrixNormal Sigma: %v", err)
+	}
+	pf := float64(M.Rows())
+	mf := float64(M.Cols())
+	return &MatrixNormalDist{
+		M: M, Omega: Omega, Sigma: Sigma,
+		omegaChol: Lo, sigmaChol: Ls,
+		omegaInv: oinv, sigmaInv: sinv,
+		lnNorm: -0.5*mf*pf*math.Log(2*math.Pi) - 0.5*mf*logDetChol(Lo) - 0.5*pf*logDetChol(Ls),
+	}, nil
+}
+
+func (d *MatrixNormalDist) PDF(X *mx.DenseMatrix) float64 { return math.Exp(d.LogPDF(X)) }
+
+// log p(X) = lnNorm - tr(Sigma^-1 (X-M)^T Omega^-1 (X-M)) / 2
+func (d *MatrixNormalDist) LogPDF(X *mx.DenseMatrix) float64 {
+	diff, err := X.MinusDense(d.M)
+	if err != nil {
+		return negInf
+	}
+	inner, _ := d.omegaInv.TimesDense(diff)
+	inner, _ = diff.Transpose().TimesDense(inner)
+	inner, _ = d.sigmaInv.TimesDense(inner)
+	return d.lnNorm - 0.5*inner.Trace()
+}
+
+func (d *MatrixNormalDist) Rand() *mx.DenseMatrix { return d.RandWith(DefaultRNG) }
+
+// X = M + A Z B^T, where A A^T = Omega, B B^T = Sigma and Z has iid N(0, 1) entries
+func (d *MatrixNormalDist) RandWith(rng RNG) *mx.DenseMatrix {
+	p, m := d.M.Rows(), d.M.Cols()
+	Z := mx.Zeros(p, m)
+	for i := 0; i < p; i++ {
+		for j := 0; j < m; j++ {
+			Z.Set(i, j, rng.NormFloat64())
+		}
+	}
+	X, _ := d.omegaChol.TimesDense(Z)
+	X, _ = X.TimesDense(d.sigmaChol.Transpose())
+	X.AddDense(d.M)
+	return X
+}
+
+func (d *MatrixNormalDist) Mean() *mx.DenseMatrix { return d.M.Copy() }
+
+// Covariance returns the covariance of vec(X), the columns of X stacked on
+// top of each other, which is Sigma ⊗ Omega.
+func (d *MatrixNormalDist) Covariance() *mx.DenseMatrix {
+	return mx.Kronecker(d.Sigma, d.Omega)
+}
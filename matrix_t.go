@@ -1,35 +1,26 @@
 package stat
 
 import (
-	"fmt"
 	"math"
 
 	. "github.com/ematvey/go-fn/fn"
 	mx "github.com/skelterjohn/go.matrix"
 )
 
-func checkMatrixT(M, Omega, Sigma *mx.DenseMatrix, n int) {
-	p := M.Rows()
-	m := M.Cols()
-	if Omega.Rows() != p {
-		panic(fmt.Sprintf("Omega.Rows != M.Rows, %d != %d", Omega.Rows(), p))
-	}
-	if Omega.Cols() != Omega.Rows() {
-		panic("Omega is not square")
-	}
-	if Sigma.Rows() != m {
-		panic(fmt.Sprintf("Sigma.Cols != M.Cols, %d != %d", Sigma.Cols(), m))
-	}
-	if Sigma.Cols() != Sigma.Rows() {
-		panic("Sigma is not square")
+func checkMatrixT(M, Omega, Sigma *mx.DenseMatrix, n int) error {
+	if err := checkMatrixNormal(M, Omega, Sigma); err != nil {
+		return err
 	}
 	if n <= 0 {
-		panic("n <= 0")
+		return invalidParameter("MatrixT n = %d <= 0", n)
 	}
+	return nil
 }
 
 func MatrixT_PDF(M, Omega, Sigma *mx.DenseMatrix, n int) func(T *mx.DenseMatrix) (l float64) {
-	checkMatrixT(M, Omega, Sigma, n)
+	if err := checkMatrixT(M, Omega, Sigma, n); err != nil {
+		panic(err)
+	}
 
 	nf := float64(n)
 	p := M.Rows()
@@ -72,7 +63,9 @@ func MatrixT_PDF(M, Omega, Sigma *mx.DenseMatrix, n int) func(T *mx.DenseMatrix)
 }
 
 func MatrixT_LnPDF(M, Omega, Sigma *mx.DenseMatrix, n int) func(T *mx.DenseMatrix) (ll float64) {
-	checkMatrixT(M, Omega, Sigma, n)
+	if err := checkMatrixT(M, Omega, Sigma, n); err != nil {
+		panic(err)
+	}
 
 	nf := float64(n)
 	p := M.Rows()
@@ -115,11 +108,12 @@ func MatrixT_LnPDF(M, Omega, Sigma *mx.DenseMatrix, n int) func(T *mx.DenseMatri
 }
 
 func MatrixT(M, Omega, Sigma *mx.DenseMatrix, n int) func() (T *mx.DenseMatrix) {
-	checkMatrixT(M, Omega, Sigma, n)
-
-	fmt.Println("M:", M)
-	fmt.Println("Sigma:", Sigma)
-	fmt.Println("Omega:", Omega)
+	return MatrixTWith(DefaultRNG, M, Omega, Sigma, n)
+}
+func MatrixTWith(rng RNG, M, Omega, Sigma *mx.DenseMatrix, n int) func() (T *mx.DenseMatrix) {
+	if err := checkMatrixT(M, Omega, Sigma, n); err != nil {
+		panic(err)
+	}
 
 	p := M.Rows()
 	m := M.Cols()
@@ -129,9 +123,9 @@ func MatrixT(M, Omega, Sigma *mx.DenseMatrix, n int) func() (T *mx.DenseMatrix)
 		panic(err)
 	}
 
-	Sdist := Wishart(n+p-1, OmegaInv)
+	Sdist := WishartWith(rng, n+p-1, OmegaInv)
 
-	Xdist := MatrixNormal(mx.Zeros(p, m), mx.Eye(p), Sigma)
+	Xdist := MatrixNormalWith(rng, mx.Zeros(p, m), mx.Eye(p), Sigma)
 
 	return func() (T *mx.DenseMatrix) {
 		S := Sdist()
@@ -144,8 +138,6 @@ func MatrixT(M, Omega, Sigma *mx.DenseMatrix, n int) func() (T *mx.DenseMatrix)
 			panic(err)
 		}
 		X := Xdist()
-		fmt.Println("Sinvc:", Sinvc)
-		fmt.Println("X:", X)
 		T, err = Sinvc.Transpose().TimesDense(X)
 		if err != nil {
 			panic(err)
@@ -161,3 +153,110 @@ func MatrixT(M, Omega, Sigma *mx.DenseMatrix, n int) func() (T *mx.DenseMatrix)
 func NextMatrixT(M, Omega, Sigma *mx.DenseMatrix, n int) (T *mx.DenseMatrix) {
 	return MatrixT(M, Omega, Sigma, n)()
 }
+func NextMatrixTWith(rng RNG, M, Omega, Sigma *mx.DenseMatrix, n int) (T *mx.DenseMatrix) {
+	return MatrixTWith(rng, M, Omega, Sigma, n)()
+}
+
+// Matrix T distribution with n degrees of freedom, mean M (p x m), row scale
+// Omega (p x p) and column scale Sigma (m x m)
+type MatrixTDist struct {
+	M, Omega, Sigma *mx.DenseMatrix
+	N               int
+
+	omegaInv, sigmaInv *mx.DenseMatrix
+	wishart            *WishartDist
+	normal             *MatrixNormalDist
+	lnNorm             float64
+}
+
+// NewMatrixTDist returns a Matrix T distribution, checking the dimensions of
+// M, Omega and Sigma, that both scales are symmetric positive definite and
+// that n > 0.
+func NewMatrixTDist(M, Omega, Sigma *mx.DenseMatrix, n int) (*MatrixTDist, error) {
+	if err := checkMatrixT(M, Omega, Sigma, n); err != nil {
+		return nil, err
+	}
+	p, m := M.Rows(), M.Cols()
+	Lo, err := checkCovariance("MatrixT Omega", Omega, p)
+	if err != nil {
+		return nil, err
+	}
+	Ls, err := checkCovariance("MatrixT Sigma", Sigma, m)
+	if err != nil {
+		return nil, err
+	}
+	oinv, err := Omega.Inverse()
+	if err != nil {
+		return nil, invalidParameter("MatrixT Omega: %v", err)
+	}
+	sinv, err := Sigma.Inverse()
+	if err != nil {
+		return nil, invalidParameter("MatrixT Sigma: %v", err)
+	}
+	wishart, err := NewWishartDist(n+p-1, oinv)
+	if err != nil {
+		return nil, err
+	}
+	normal, err := NewMatrixNormalDist(mx.Zeros(p, m), mx.Eye(p), Sigma)
+	if err != nil {
+		return nil, err
+	}
+	nf, pf, mf := float64(n), float64(p), float64(m)
+	lnNorm := lnMvΓ(m, 0.5*(nf+pf+mf-1)) - lnMvΓ(m, 0.5*(nf+mf-1))
+	lnNorm -= 0.5 * mf * pf * math.Log(math.Pi)
+	lnNorm -= 0.5*mf*logDetChol(Lo) + 0.5*pf*logDetChol(Ls)
+	return &MatrixTDist{
+		M: M, Omega: Omega, Sigma: Sigma, N: n,
+		omegaInv: oinv, sigmaInv: sinv,
+		wishart: wishart, normal: normal,
+		lnNorm: lnNorm,
+	}, nil
+}
+
+func (d *MatrixTDist) PDF(T *mx.DenseMatrix) float64 { return math.Exp(d.LogPDF(T)) }
+
+// log p(T) = lnNorm - (n+p+m-1)/2 log|I + Omega^-1 (T-M) Sigma^-1 (T-M)^T|
+func (d *MatrixTDist) LogPDF(T *mx.DenseMatrix) float64 {
+	diff, err := T.MinusDense(d.M)
+	if err != nil {
+		return negInf
+	}
+	p, m := d.M.Rows(), d.M.Cols()
+	inner, _ := d.sigmaInv.TimesDense(diff.Transpose())
+	inner, _ = diff.TimesDense(inner)
+	inner, _ = d.omegaInv.TimesDense(inner)
+	inner.AddDense(mx.Eye(p))
+	return d.lnNorm - 0.5*float64(d.N+p+m-1)*math.Log(inner.Det())
+}
+
+func (d *MatrixTDist) Rand() *mx.DenseMatrix { return d.RandWith(DefaultRNG) }
+
+// T = M + L X, where L L^T = S^-1, S ~ Wishart(n+p-1, Omega^-1) and X ~ MatrixNormal(0, I, Sigma)
+func (d *MatrixTDist) RandWith(rng RNG) *mx.DenseMatrix {
+	Sinv, _ := d.wishart.RandWith(rng).Inverse()
+	L, _ := Sinv.Cholesky()
+	T, _ := L.TimesDense(d.normal.RandWith(rng))
+	T.AddDense(d.M)
+	return T
+}
+
+// The mean is only defined for n > 1; otherwise every entry is NaN.
+func (d *MatrixTDist) Mean() *mx.DenseMatrix {
+	μ := d.M.Copy()
+	if d.N <= 1 {
+		fillMatrix(μ, math.NaN())
+	}
+	return μ
+}
+
+// Covariance returns the covariance of vec(T), (Sigma ⊗ Omega) / (n - 2),
+// which is only finite for n > 2; otherwise every entry is NaN.
+func (d *MatrixTDist) Covariance() *mx.DenseMatrix {
+	C := mx.Kronecker(d.Sigma, d.Omega)
+	if d.N <= 2 {
+		fillMatrix(C, math.NaN())
+		return C
+	}
+	C.Scale(1 / float64(d.N-2))
+	return C
+}
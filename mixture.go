@@ -0,0 +1,256 @@
+// Finite mixture distributions
+
+package stat
+
+import (
+	"math"
+
+	. "github.com/skelterjohn/go.matrix"
+)
+
+// MixtureDist is a finite mixture of continuous distributions: a draw comes
+// from Components[i] with probability Weights[i].
+type MixtureDist struct {
+	Weights    []float64
+	Components []ContinuousDistribution
+}
+
+// NewMixtureDist returns a mixture, checking that there is one weight per
+// component and that the weights are non-negative and sum to one.
+func NewMixtureDist(w []float64, c []ContinuousDistribution) (MixtureDist, error) {
+	if err := checkMixtureWeights(w, len(c)); err != nil {
+		return MixtureDist{}, err
+	}
+	return MixtureDist{w, c}, nil
+}
+
+func checkMixtureWeights(w []float64, n int) error {
+	if len(w) != n {
+		return dimensionMismatch("mixture has %d weights for %d components", len(w), n)
+	}
+	if _, err := NewChoiceDist(w); err != nil {
+		return invalidParameter("mixture weights: %v", err)
+	}
+	return nil
+}
+
+func (d MixtureDist) PDF(x float64) float64 { return exp(d.LogPDF(x)) }
+
+func (d MixtureDist) LogPDF(x float64) float64 {
+	return d.logSum(func(c ContinuousDistribution) float64 { return c.LogPDF(x) })
+}
+
+func (d MixtureDist) CDF(x float64) (p float64) {
+	for i, c := range d.Components {
+		p += d.Weights[i] * c.CDF(x)
+	}
+	return
+}
+
+func (d MixtureDist) Survival(x float64) (p float64) {
+	for i, c := range d.Components {
+		p += d.Weights[i] * c.Survival(x)
+	}
+	return
+}
+
+func (d MixtureDist) LogCDF(x float64) float64 {
+	return d.logSum(func(c ContinuousDistribution) float64 { return c.LogCDF(x) })
+}
+
+func (d MixtureDist) LogSurvival(x float64) float64 {
+	return d.logSum(func(c ContinuousDistribution) float64 { return c.LogSurvival(x) })
+}
+
+// logSum returns log Σ w_i exp(f(component i)).
+func (d MixtureDist) logSum(f func(c ContinuousDistribution) float64) float64 {
+	l := make([]float64, len(d.Components))
+	for i, c := range d.Components {
+		l[i] = log(d.Weights[i]) + f(c)
+	}
+	return logSumExp(l)
+}
+
+// Responsibilities returns the posterior probability that x was drawn from
+// each component.
+func (d MixtureDist) Responsibilities(x float64) []float64 {
+	l := make([]float64, len(d.Components))
+	for i, c := range d.Components {
+		l[i] = log(d.Weights[i]) + c.LogPDF(x)
+	}
+	return logWeights(l)
+}
+
+// Quantile inverts the mixture CDF, starting from the weighted average of
+// the component quantiles.
+func (d MixtureDist) Quantile(p float64) float64 {
+	if p == 0 || p == 1 {
+		// the lowest or highest end of the component supports
+		x := d.Components[0].Quantile(p)
+		for _, c := range d.Components[1:] {
+			if q := c.Quantile(p); p == 0 && q < x || p == 1 && q > x {
+				x = q
+			}
+		}
+		return x
+	}
+	var x0 float64
+	for i, c := range d.Components {
+		if q := c.Quantile(p); isFinite(q) {
+			x0 += d.Weights[i] * q
+		}
+	}
+	return quantile(d, p, x0, false)
+}
+
+func (d MixtureDist) Rand() float64 { return d.RandWith(DefaultRNG) }
+
+func (d MixtureDist) RandWith(rng RNG) float64 {
+	return d.Components[NextChoiceWith(rng, d.Weights)].RandWith(rng)
+}
+
+func (d MixtureDist) Mean() (μ float64) {
+	for i, c := range d.Components {
+		μ += d.Weights[i] * c.Mean()
+	}
+	return
+}
+
+func (d MixtureDist) Variance() float64 { return d.centralMoment(2) }
+
+func (d MixtureDist) Skewness() float64 {
+	return d.centralMoment(3) / pow(d.Variance(), 1.5)
+}
+
+func (d MixtureDist) ExKurtosis() float64 {
+	v := d.Variance()
+	return d.centralMoment(4)/(v*v) - 3
+}
+
+// centralMoment combines the component moments about the mixture mean:
+// E[(X-μ)^n] = Σ w_i E[(X_i - μ_i + δ_i)^n] with δ_i = μ_i - μ.
+func (d MixtureDist) centralMoment(n int) (m float64) {
+	μ := d.Mean()
+	for i, c := range d.Components {
+		δ := c.Mean() - μ
+		v := c.Variance()
+		m2 := v
+		m3 := c.Skewness() * pow(v, 1.5)
+		m4 := (c.ExKurtosis() + 3) * v * v
+		var t float64
+		switch n {
+		case 2:
+			t = m2 + δ*δ
+		case 3:
+			t = m3 + 3*m2*δ + δ*δ*δ
+		case 4:
+			t = m4 + 4*m3*δ + 6*m2*δ*δ + δ*δ*δ*δ
+		}
+		m += d.Weights[i] * t
+	}
+	return
+}
+
+// Mode returns the component mode at which the mixture density is highest,
+// which is the mode of the mixture when the components are well separated.
+func (d MixtureDist) Mode() float64 {
+	best, bestL := math.NaN(), negInf
+	for _, c := range d.Components {
+		x := c.Mode()
+		if l := d.LogPDF(x); l > bestL {
+			best, bestL = x, l
+		}
+	}
+	return best
+}
+
+// Entropy is computed by numerical integration, split at the ends of the
+// component supports and at the component modes.
+func (d MixtureDist) Entropy() float64 {
+	cuts := []float64{math.Inf(-1), math.Inf(1)}
+	for _, c := range d.Components {
+		cuts = append(cuts, c.Quantile(0), c.Mode(), c.Quantile(1))
+	}
+	return integratePieces(func(x float64) float64 {
+		l := d.LogPDF(x)
+		if math.IsInf(l, -1) {
+			return 0
+		}
+		return -exp(l) * l
+	}, cuts, 1e-12)
+}
+
+// MVNormalMixtureDist is a finite mixture of multivariate Normal
+// distributions of the same dimension.
+type MVNormalMixtureDist struct {
+	Weights    []float64
+	Components []*MVNormalDist
+}
+
+// NewMVNormalMixtureDist returns a mixture of multivariate Normals, checking
+// the weights as NewMixtureDist does and that the components have the same
+// dimension.
+func NewMVNormalMixtureDist(w []float64, c []*MVNormalDist) (*MVNormalMixtureDist, error) {
+	if err := checkMixtureWeights(w, len(c)); err != nil {
+		return nil, err
+	}
+	for i := range c {
+		if c[i].Mu.Rows() != c[0].Mu.Rows() {
+			return nil, dimensionMismatch("mixture component %d has dimension %d, component 0 %d", i, c[i].Mu.Rows(), c[0].Mu.Rows())
+		}
+	}
+	return &MVNormalMixtureDist{w, c}, nil
+}
+
+func (d *MVNormalMixtureDist) PDF(x *DenseMatrix) float64 { return exp(d.LogPDF(x)) }
+
+func (d *MVNormalMixtureDist) LogPDF(x *DenseMatrix) float64 {
+	return logSumExp(d.logJoint(x))
+}
+
+// Responsibilities returns the posterior probability that x was drawn from
+// each component.
+func (d *MVNormalMixtureDist) Responsibilities(x *DenseMatrix) []float64 {
+	return logWeights(d.logJoint(x))
+}
+
+// logJoint returns log w_i + log f_i(x) for each component.
+func (d *MVNormalMixtureDist) logJoint(x *DenseMatrix) []float64 {
+	l := make([]float64, len(d.Components))
+	for i, c := range d.Components {
+		l[i] = log(d.Weights[i]) + c.LogPDF(x)
+	}
+	return l
+}
+
+func (d *MVNormalMixtureDist) Rand() *DenseMatrix { return d.RandWith(DefaultRNG) }
+
+func (d *MVNormalMixtureDist) RandWith(rng RNG) *DenseMatrix {
+	return d.Components[NextChoiceWith(rng, d.Weights)].RandWith(rng)
+}
+
+func (d *MVNormalMixtureDist) Mean() *DenseMatrix {
+	μ := Zeros(d.Components[0].Mu.Rows(), 1)
+	for i, c := range d.Components {
+		m := c.Mu.Copy()
+		m.Scale(d.Weights[i])
+		μ.AddDense(m)
+	}
+	return μ
+}
+
+// Covariance returns Σ w_i (Σ_i + δ_i δ_i') with δ_i = μ_i - μ.
+func (d *MVNormalMixtureDist) Covariance() *DenseMatrix {
+	μ := d.Mean()
+	p := μ.Rows()
+	S := Zeros(p, p)
+	for k, c := range d.Components {
+		for i := 0; i < p; i++ {
+			for j := 0; j < p; j++ {
+				δi, δj := c.Mu.Get(i, 0)-μ.Get(i, 0), c.Mu.Get(j, 0)-μ.Get(j, 0)
+				S.Set(i, j, S.Get(i, j)+d.Weights[k]*(c.Sigma.Get(i, j)+δi*δj))
+			}
+		}
+	}
+	return S
+}
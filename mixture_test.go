@@ -0,0 +1,152 @@
+package stat
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	mx "github.com/skelterjohn/go.matrix"
+)
+
+func TestMixture(t *testing.T) {
+	a, b := NormalDist{0, 1}, GammaDist{3, 2}
+	d, err := NewMixtureDist([]float64{0.3, 0.7}, []ContinuousDistribution{a, b})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, x := range []float64{-2, 0.5, 4, 12} {
+		check(t, "PDF", d.PDF(x), 0.3*a.PDF(x)+0.7*b.PDF(x), 1e-14)
+		check(t, "CDF", d.CDF(x), 0.3*a.CDF(x)+0.7*b.CDF(x), 1e-14)
+		r := d.Responsibilities(x)
+		check(t, "Responsibilities", r[0], 0.3*a.PDF(x)/d.PDF(x), 1e-12)
+		check(t, "Responsibilities sum", r[0]+r[1], 1, 1e-15)
+	}
+	if l := d.LogPDF(-60); !(l > -1900 && l < -1700) {
+		t.Errorf("LogPDF(-60) = %v", l)
+	}
+	for _, p := range []float64{1e-8, 0.2, 0.5, 0.95} {
+		check(t, "CDF(Quantile)", d.CDF(d.Quantile(p)), p, 1e-12)
+	}
+	if q := d.Quantile(0); !math.IsInf(q, -1) {
+		t.Errorf("Quantile(0) = %v", q)
+	}
+
+	lo, hi := -12.0, 80.0
+	μ := simpson(func(x float64) float64 { return x * d.PDF(x) }, lo, hi, 200000)
+	check(t, "Mean", d.Mean(), μ, 1e-9)
+	moment := func(n float64) float64 {
+		return simpson(func(x float64) float64 { return math.Pow(x-μ, n) * d.PDF(x) }, lo, hi, 200000)
+	}
+	v := moment(2)
+	check(t, "Variance", d.Variance(), v, 1e-9)
+	check(t, "Skewness", d.Skewness(), moment(3)/math.Pow(v, 1.5), 1e-8)
+	check(t, "ExKurtosis", d.ExKurtosis(), moment(4)/(v*v)-3, 1e-8)
+	h := simpson(func(x float64) float64 { return -d.PDF(x) * d.LogPDF(x) }, lo, hi, 200000)
+	check(t, "Entropy", d.Entropy(), h, 1e-9)
+	// the Normal peak is higher than the Gamma one
+	check(t, "Mode", d.Mode(), a.Mode(), 0)
+
+	if _, err := NewMixtureDist([]float64{1}, []ContinuousDistribution{a, b}); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("expected ErrDimensionMismatch, got %v", err)
+	}
+	if _, err := NewMixtureDist([]float64{0.5, 0.6}, []ContinuousDistribution{a, b}); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("expected ErrInvalidParameter, got %v", err)
+	}
+}
+
+func TestFitNormalMixture(t *testing.T) {
+	truth := MixtureDist{
+		[]float64{0.25, 0.75},
+		[]ContinuousDistribution{NormalDist{-3, 0.5}, NormalDist{2, 1.5}},
+	}
+	rng := NewRNG(3)
+	x := make([]float64, 5000)
+	for i := range x {
+		x[i] = truth.RandWith(rng)
+	}
+	d, err := FitNormalMixtureWith(rng, x, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for j, c := range d.Components {
+		want := truth.Components[j].(NormalDist)
+		got := c.(NormalDist)
+		if abs(d.Weights[j]-truth.Weights[j]) > 0.03 || abs(got.Mu-want.Mu) > 0.1 || abs(got.Sigma-want.Sigma) > 0.1 {
+			t.Errorf("component %d: weight %v, %+v; want %v, %+v", j, d.Weights[j], got, truth.Weights[j], want)
+		}
+	}
+	// one component is the maximum-likelihood Normal, with no floor added
+	m, v := sampleMoments(x)
+	d1, _ := FitNormalMixtureWith(rng, x, 1)
+	check(t, "single Mu", d1.Components[0].(NormalDist).Mu, m, 1e-12)
+	check(t, "single variance", d1.Components[0].Variance(), v, 1e-12)
+
+	// with surplus components on two distinct values the weights remain a
+	// distribution
+	y := make([]float64, 100)
+	for i := range y {
+		y[i] = float64(10 * (i % 2))
+	}
+	d3, err := FitNormalMixtureWith(rng, y, 4)
+	if err == nil || errors.Is(err, ErrNoConvergence) {
+		if _, err := NewMixtureDist(d3.Weights, d3.Components); err != nil {
+			t.Errorf("fitted mixture rejected: %v", err)
+		}
+	} else {
+		t.Errorf("fit of two values: %v", err)
+	}
+
+	if _, err := FitNormalMixture([]float64{1, 1, 1}, 2); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("constant data: expected ErrInvalidParameter, got %v", err)
+	}
+}
+
+func TestFitMVNormalMixture(t *testing.T) {
+	c0, _ := NewMVNormalDist(mx.MakeDenseMatrix([]float64{0, 0}, 2, 1), mx.MakeDenseMatrixStacked([][]float64{{1, 0.5}, {0.5, 1}}))
+	c1, _ := NewMVNormalDist(mx.MakeDenseMatrix([]float64{6, -4}, 2, 1), mx.MakeDenseMatrixStacked([][]float64{{2, 0}, {0, 0.5}}))
+	truth, err := NewMVNormalMixtureDist([]float64{0.4, 0.6}, []*MVNormalDist{c0, c1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rng := NewRNG(5)
+	const n = 3000
+	X := mx.Zeros(n, 2)
+	for i := 0; i < n; i++ {
+		x := truth.RandWith(rng)
+		X.Set(i, 0, x.Get(0, 0))
+		X.Set(i, 1, x.Get(1, 0))
+	}
+	d, err := FitMVNormalMixtureWith(rng, X, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Components[0].Mu.Get(0, 0) > d.Components[1].Mu.Get(0, 0) {
+		d.Weights[0], d.Weights[1] = d.Weights[1], d.Weights[0]
+		d.Components[0], d.Components[1] = d.Components[1], d.Components[0]
+	}
+	for j, c := range d.Components {
+		want := truth.Components[j]
+		if abs(d.Weights[j]-truth.Weights[j]) > 0.03 {
+			t.Errorf("component %d: weight %v, want %v", j, d.Weights[j], truth.Weights[j])
+		}
+		for a := 0; a < 2; a++ {
+			if abs(c.Mu.Get(a, 0)-want.Mu.Get(a, 0)) > 0.1 {
+				t.Errorf("component %d: mean %v, want %v", j, c.Mu, want.Mu)
+			}
+			for b := 0; b < 2; b++ {
+				if abs(c.Sigma.Get(a, b)-want.Sigma.Get(a, b)) > 0.15 {
+					t.Errorf("component %d: covariance %v, want %v", j, c.Sigma, want.Sigma)
+				}
+			}
+		}
+	}
+
+	// the mixture moments agree with the sample
+	μ, S := truth.Mean(), truth.Covariance()
+	check(t, "Mean", μ.Get(0, 0), 0.6*6, 1e-14)
+	check(t, "Covariance", S.Get(0, 0), 0.4*1+0.6*2+0.4*3.6*3.6+0.6*2.4*2.4, 1e-13)
+	r := truth.Responsibilities(mx.MakeDenseMatrix([]float64{6, -4}, 2, 1))
+	if r[1] < 0.999 {
+		t.Errorf("Responsibilities at the second mean = %v", r)
+	}
+}
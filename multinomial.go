@@ -4,49 +4,76 @@ import (
 	. "github.com/ematvey/go-fn/fn"
 )
 
-func Multinomial_PMF(θ []float64, n int64) func(x []int64) float64 {
-	return func(x []int64) float64 {
-		if len(x) != len(θ) {
-			return 0
-		}
-		l := fOne
-		totalx := iZero
-		for i := 0; i < len(x); i++ {
-			l *= pow(θ[i], float64(x[i]))
-			l /= Γ(float64(x[i] + 1))
-			totalx += x[i]
-		}
-		if totalx != n {
-			return 0
-		}
-		l *= Γ(float64(totalx + 1))
-		return l
+// Multinomial distribution of N draws over categories with probabilities Theta
+type MultinomialDist struct {
+	Theta []float64
+	N     int64
+}
+
+// NewMultinomialDist returns a Multinomial distribution, checking that θ is a
+// probability vector and n >= 0.
+func NewMultinomialDist(θ []float64, n int64) (MultinomialDist, error) {
+	if _, err := NewChoiceDist(θ); err != nil {
+		return MultinomialDist{}, err
 	}
+	if n < 0 {
+		return MultinomialDist{}, invalidParameter("Multinomial n = %d", n)
+	}
+	return MultinomialDist{θ, n}, nil
 }
-func Multinomial_LnPMF(θ []float64, n int64) func(x []int64) float64 {
-	return func(x []int64) float64 {
-		if len(x) != len(θ) {
-			return negInf
-		}
-		l := fZero
-		totalx := iZero
-		for i := 0; i < len(x); i++ {
-			l += log(θ[i]) * float64(x[i])
-			l -= LnΓ(float64(x[i] + 1))
-			totalx += x[i]
-		}
-		if totalx != n {
+
+func (d MultinomialDist) PMF(x []int64) float64 {
+	return exp(d.LogPMF(x))
+}
+
+func (d MultinomialDist) LogPMF(x []int64) float64 {
+	if len(x) != len(d.Theta) {
+		return negInf
+	}
+	l := fZero
+	totalx := iZero
+	for i := 0; i < len(x); i++ {
+		if x[i] < 0 {
 			return negInf
 		}
-		l += LnΓ(float64(totalx + 1))
-		return l
+		l += xlogy(float64(x[i]), d.Theta[i])
+		l -= LnΓ(float64(x[i] + 1))
+		totalx += x[i]
+	}
+	if totalx != d.N {
+		return negInf
 	}
+	l += LnΓ(float64(totalx + 1))
+	return l
 }
-func NextMultinomial(θ []float64, n int64) []int64 {
+
+func (d MultinomialDist) Rand() []int64            { return d.RandWith(DefaultRNG) }
+func (d MultinomialDist) RandWith(rng RNG) []int64 { return NextMultinomialWith(rng, d.Theta, d.N) }
+
+func (d MultinomialDist) Mean() []float64 {
+	μ := make([]float64, len(d.Theta))
+	for i, θ := range d.Theta {
+		μ[i] = float64(d.N) * θ
+	}
+	return μ
+}
+
+// Cov(xi, xj) = n θi (δij - θj)
+func (d MultinomialDist) Covariance() [][]float64 {
+	return categoricalCovariance(d.Theta, float64(d.N))
+}
+
+func Multinomial_PMF(θ []float64, n int64) func(x []int64) float64 {
+	return MultinomialDist{θ, n}.PMF
+}
+func Multinomial_LnPMF(θ []float64, n int64) func(x []int64) float64 {
+	return MultinomialDist{θ, n}.LogPMF
+}
+func NextMultinomial(θ []float64, n int64) []int64 { return NextMultinomialWith(DefaultRNG, θ, n) }
+func NextMultinomialWith(rng RNG, θ []float64, n int64) []int64 {
 	x := make([]int64, len(θ))
-	chooser := Choice(θ)
 	for i := iZero; i < n; i++ {
-		x[chooser()]++
+		x[NextChoiceWith(rng, θ)]++
 	}
 	return x
 }
@@ -55,3 +82,16 @@ func Multinomial(θ []float64, n int64) func() []int64 {
 		return NextMultinomial(θ, n)
 	}
 }
+
+// categoricalCovariance returns the matrix s θi (δij - θj).
+func categoricalCovariance(θ []float64, s float64) [][]float64 {
+	C := make([][]float64, len(θ))
+	for i := range θ {
+		C[i] = make([]float64, len(θ))
+		for j := range θ {
+			C[i][j] = -s * θ[i] * θ[j]
+		}
+		C[i][i] += s * θ[i]
+	}
+	return C
+}
@@ -0,0 +1,85 @@
+package stat
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	mx "github.com/skelterjohn/go.matrix"
+)
+
+func TestMatrixConstructorErrors(t *testing.T) {
+	I2 := mx.Eye(2)
+	notPD := mx.MakeDenseMatrixStacked([][]float64{{1, 2}, {2, 1}})
+	if _, err := NewMVNormalDist(mx.Zeros(3, 1), I2); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("MVNormal: expected ErrDimensionMismatch, got %v", err)
+	}
+	if _, err := NewMVNormalDist(mx.Zeros(2, 1), notPD); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("MVNormal: expected ErrInvalidParameter, got %v", err)
+	}
+	if _, err := NewWishartDist(1, I2); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("Wishart: expected ErrInvalidParameter, got %v", err)
+	}
+	if _, err := NewMatrixNormalDist(mx.Zeros(2, 3), I2, I2); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("MatrixNormal: expected ErrDimensionMismatch, got %v", err)
+	}
+	if _, err := NewMatrixTDist(mx.Zeros(2, 2), I2, I2, 0); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("MatrixT: expected ErrInvalidParameter, got %v", err)
+	}
+}
+
+func TestMatrixNormalMatchesMVNormal(t *testing.T) {
+	M := mx.MakeDenseMatrixStacked([][]float64{{1, 2, 3}, {4, 5, 6}})
+	Omega := mx.MakeDenseMatrixStacked([][]float64{{2, 0.5}, {0.5, 1}})
+	Sigma := mx.MakeDenseMatrixStacked([][]float64{{1, 0.2, 0}, {0.2, 2, 0.3}, {0, 0.3, 1.5}})
+	X := mx.MakeDenseMatrixStacked([][]float64{{0, 2.5, 3}, {4.5, 4, 7}})
+	mn, err := NewMatrixNormalDist(M, Omega, Sigma)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// vec(X) ~ N(vec(M), Sigma ⊗ Omega) with column-stacking vec
+	mv, err := NewMVNormalDist(mx.Vectorize(M), mx.Kronecker(Sigma, Omega))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a, b := mn.LogPDF(X), mv.LogPDF(mx.Vectorize(X)); math.Abs(a-b) > 1e-9 {
+		t.Errorf("MatrixNormal LogPDF %v, vectorized MVNormal LogPDF %v", a, b)
+	}
+}
+
+func TestWishartOneDimensional(t *testing.T) {
+	// For p = 1, Wishart(n, v) is Gamma(n/2, 2v)
+	w, err := NewWishartDist(5, mx.MakeDenseMatrix([]float64{1.5}, 1, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	iw, err := NewInverseWishartDist(5, mx.MakeDenseMatrix([]float64{1.5}, 1, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, x := range []float64{0.5, 3, 10} {
+		X := mx.MakeDenseMatrix([]float64{x}, 1, 1)
+		if a, b := w.LogPDF(X), (GammaDist{2.5, 3}).LogPDF(x); math.Abs(a-b) > 1e-12 {
+			t.Errorf("Wishart LogPDF(%v) = %v, Gamma gives %v", x, a, b)
+		}
+		if a, b := iw.LogPDF(X), (InvGammaDist{2.5, 0.75}).LogPDF(x); math.Abs(a-b) > 1e-12 {
+			t.Errorf("InverseWishart LogPDF(%v) = %v, InvGamma gives %v", x, a, b)
+		}
+	}
+}
+
+func TestMatrixTOneDimensional(t *testing.T) {
+	// For p = m = 1, MatrixT(n, μ, ω, σ) is a Student's t with n degrees of
+	// freedom, location μ and scale sqrt(ωσ/n)
+	one := func(x float64) *mx.DenseMatrix { return mx.MakeDenseMatrix([]float64{x}, 1, 1) }
+	d, err := NewMatrixTDist(one(1), one(2), one(0.5), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := math.Sqrt(2 * 0.5 / 4)
+	for _, x := range []float64{-2, 1, 3.5} {
+		if a, b := d.LogPDF(one(x)), (StudentsTDist{4}).LogPDF((x-1)/s)-math.Log(s); math.Abs(a-b) > 1e-12 {
+			t.Errorf("MatrixT LogPDF(%v) = %v, StudentsT gives %v", x, a, b)
+		}
+	}
+}
@@ -25,10 +25,13 @@ func MVNormal_PDF(μ *DenseMatrix, Σ *DenseMatrix) func(x *DenseMatrix) float64
 	}
 }
 func NextMVNormal(μ *DenseMatrix, Σ *DenseMatrix) *DenseMatrix {
+	return NextMVNormalWith(DefaultRNG, μ, Σ)
+}
+func NextMVNormalWith(rng RNG, μ *DenseMatrix, Σ *DenseMatrix) *DenseMatrix {
 	n := μ.Rows()
 	x := Zeros(n, 1)
 	for i := 0; i < n; i++ {
-		x.Set(i, 0, NextNormal(0, 1))
+		x.Set(i, 0, rng.NormFloat64())
 	}
 	C, err := Σ.Cholesky()
 	Cx, err := C.TimesDense(x)
@@ -40,15 +43,121 @@ func NextMVNormal(μ *DenseMatrix, Σ *DenseMatrix) *DenseMatrix {
 }
 
 func MVNormal(μ *DenseMatrix, Σ *DenseMatrix) func() *DenseMatrix {
+	return MVNormalWith(DefaultRNG, μ, Σ)
+}
+func MVNormalWith(rng RNG, μ *DenseMatrix, Σ *DenseMatrix) func() *DenseMatrix {
 	C, _ := Σ.Cholesky()
 	n := μ.Rows()
 	return func() *DenseMatrix {
 		x := Zeros(n, 1)
 		for i := 0; i < n; i++ {
-			x.Set(i, 0, NextNormal(0, 1))
+			x.Set(i, 0, rng.NormFloat64())
 		}
 		Cx, _ := C.TimesDense(x)
 		MCx, _ := μ.PlusDense(Cx)
 		return MCx
 	}
 }
+
+// Multivariate Normal distribution with mean Mu (p x 1) and covariance Sigma (p x p)
+type MVNormalDist struct {
+	Mu, Sigma *DenseMatrix
+
+	chol, inv *DenseMatrix
+	lnNorm    float64
+}
+
+// NewMVNormalDist returns a multivariate Normal distribution, checking that μ
+// is a column vector and Σ a matching symmetric positive definite matrix.
+func NewMVNormalDist(μ, Σ *DenseMatrix) (*MVNormalDist, error) {
+	if μ.Cols() != 1 {
+		return nil, dimensionMismatch("MVNormal μ is %dx%d, not a column vector", μ.Rows(), μ.Cols())
+	}
+	C, err := checkCovariance("MVNormal Σ", Σ, μ.Rows())
+	if err != nil {
+		return nil, err
+	}
+	Σinv, err := Σ.Inverse()
+	if err != nil {
+		return nil, invalidParameter("MVNormal Σ: %v", err)
+	}
+	p := float64(μ.Rows())
+	return &MVNormalDist{
+		Mu:     μ,
+		Sigma:  Σ,
+		chol:   C,
+		inv:    Σinv,
+		lnNorm: -p/2*log(2*π) - logDetChol(C)/2,
+	}, nil
+}
+
+func (d *MVNormalDist) PDF(x *DenseMatrix) float64 { return exp(d.LogPDF(x)) }
+
+func (d *MVNormalDist) LogPDF(x *DenseMatrix) float64 {
+	δ, err := x.MinusDense(d.Mu)
+	if err != nil {
+		return negInf
+	}
+	tmp, _ := d.inv.TimesDense(δ)
+	tmp, _ = δ.Transpose().TimesDense(tmp)
+	return d.lnNorm - tmp.Get(0, 0)/2
+}
+
+func (d *MVNormalDist) Rand() *DenseMatrix { return d.RandWith(DefaultRNG) }
+
+func (d *MVNormalDist) RandWith(rng RNG) *DenseMatrix {
+	n := d.Mu.Rows()
+	x := Zeros(n, 1)
+	for i := 0; i < n; i++ {
+		x.Set(i, 0, rng.NormFloat64())
+	}
+	Cx, _ := d.chol.TimesDense(x)
+	μCx, _ := d.Mu.PlusDense(Cx)
+	return μCx
+}
+
+func (d *MVNormalDist) Mean() *DenseMatrix       { return d.Mu.Copy() }
+func (d *MVNormalDist) Covariance() *DenseMatrix { return d.Sigma.Copy() }
+
+// checkCovariance verifies that Σ is a symmetric positive definite p x p
+// matrix and returns its Cholesky factor.
+func checkCovariance(name string, Σ *DenseMatrix, p int) (*DenseMatrix, error) {
+	if Σ.Rows() != p || Σ.Cols() != p {
+		return nil, dimensionMismatch("%s is %dx%d, expected %dx%d", name, Σ.Rows(), Σ.Cols(), p, p)
+	}
+	for i := 0; i < p; i++ {
+		for j := 0; j < i; j++ {
+			a, b := Σ.Get(i, j), Σ.Get(j, i)
+			if !(abs(a-b) <= 1e-9*(abs(a)+abs(b))) {
+				return nil, invalidParameter("%s is not symmetric", name)
+			}
+		}
+	}
+	C, err := Σ.Cholesky()
+	if err != nil {
+		return nil, invalidParameter("%s is not positive definite", name)
+	}
+	for i := 0; i < p; i++ {
+		if !(C.Get(i, i) > 0) {
+			return nil, invalidParameter("%s is not positive definite", name)
+		}
+	}
+	return C, nil
+}
+
+// logDetChol returns log|Σ| given the Cholesky factor of Σ.
+func logDetChol(C *DenseMatrix) (l float64) {
+	for i := 0; i < C.Rows(); i++ {
+		l += 2 * log(C.Get(i, i))
+	}
+	return
+}
+
+// fillMatrix sets every entry of A to v.
+func fillMatrix(A *DenseMatrix, v float64) {
+	for i := 0; i < A.Rows(); i++ {
+		for j := 0; j < A.Cols(); j++ {
+			A.Set(i, j, v)
+		}
+	}
+}
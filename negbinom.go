@@ -17,28 +17,128 @@ func NegativeBinomial_PMF(ρ float64, r int64) func(i int64) float64 {
 }
 */
 
-func NegativeBinomial_PMF(ρ float64, r int64) func(k int64) float64 {
-	return func(k int64) float64 {
-		return BinomCoeff(k+r-1, k) * math.Pow(1-ρ, float64(r)) * math.Pow(ρ, float64(k))
+// Negative Binomial distribution: number of failures before the R-th success,
+// each trial succeeding with probability Rho
+type NegativeBinomialDist struct {
+	Rho, R float64
+}
+
+// NewNegativeBinomialDist returns a Negative Binomial distribution, checking
+// that 0 < ρ <= 1 and r > 0.
+func NewNegativeBinomialDist(ρ, r float64) (NegativeBinomialDist, error) {
+	if !isProbability(ρ) || ρ == 0 || !(r > 0) || math.IsInf(r, 0) {
+		return NegativeBinomialDist{}, invalidParameter("NegativeBinomial ρ = %v, r = %v", ρ, r)
+	}
+	return NegativeBinomialDist{ρ, r}, nil
+}
+
+func (d NegativeBinomialDist) PMF(k int64) float64 {
+	if k < 0 {
+		return 0
+	}
+	return math.Exp(d.LogPMF(k))
+}
+
+func (d NegativeBinomialDist) LogPMF(k int64) float64 {
+	if k < 0 {
+		return negInf
+	}
+	i := float64(k)
+	return LnΓ(i+d.R) - LnΓ(i+1) - LnΓ(d.R) + d.R*log(d.Rho) + xlogy(i, 1-d.Rho)
+}
+
+func (d NegativeBinomialDist) logPMFs(dst []float64, k []int64) {
+	lnNorm := d.R*log(d.Rho) - LnΓ(d.R)
+	for i, ki := range k {
+		if ki < 0 {
+			dst[i] = negInf
+			continue
+		}
+		j := float64(ki)
+		dst[i] = LnΓ(j+d.R) - LnΓ(j+1) + lnNorm + xlogy(j, 1-d.Rho)
 	}
 }
 
+func (d NegativeBinomialDist) CDF(k int64) float64      { return math.Exp(d.LogCDF(k)) }
+func (d NegativeBinomialDist) Survival(k int64) float64 { return math.Exp(d.LogSurvival(k)) }
+
+func (d NegativeBinomialDist) LogCDF(k int64) float64 {
+	lnI, _ := d.lnBetaIncReg(k)
+	return lnI
+}
+
+func (d NegativeBinomialDist) LogSurvival(k int64) float64 {
+	_, lnIc := d.lnBetaIncReg(k)
+	return lnIc
+}
+
+// P(X <= k) = I_ρ(r, k+1)
+func (d NegativeBinomialDist) lnBetaIncReg(k int64) (lnI, lnIc float64) {
+	if k < 0 {
+		return negInf, 0
+	}
+	return lnBetaIncReg(d.R, float64(k+1), d.Rho, 1-d.Rho)
+}
+
+func (d NegativeBinomialDist) Quantile(p float64) int64 {
+	guess := quantileGuess(d.Mean(), sqrt(d.Variance()), d.Skewness(), p, 0, math.MaxInt64)
+	return discreteQuantile(d.CDF, p, guess, 0, math.MaxInt64)
+}
+
+func (d NegativeBinomialDist) Rand() int64 { return d.RandWith(DefaultRNG) }
+
+// For integral R this counts Bernoulli trials, otherwise it draws from the
+// Gamma-Poisson mixture.
+func (d NegativeBinomialDist) RandWith(rng RNG) int64 {
+	if d.R == math.Floor(d.R) {
+		return NextNegativeBinomialWith(rng, d.Rho, int64(d.R))
+	}
+	return NextPoissonWith(rng, NextGammaWith(rng, d.R, d.Rho/(1-d.Rho)))
+}
+
+func (d NegativeBinomialDist) Mean() float64     { return d.R * (1 - d.Rho) / d.Rho }
+func (d NegativeBinomialDist) Variance() float64 { return d.R * (1 - d.Rho) / (d.Rho * d.Rho) }
+
+func (d NegativeBinomialDist) Mode() int64 {
+	if d.R <= 1 {
+		return 0
+	}
+	return int64(math.Floor((d.R - 1) * (1 - d.Rho) / d.Rho))
+}
+
+func (d NegativeBinomialDist) Skewness() float64 {
+	return (2 - d.Rho) / sqrt(d.R*(1-d.Rho))
+}
+
+func (d NegativeBinomialDist) ExKurtosis() float64 {
+	return 6/d.R + d.Rho*d.Rho/(d.R*(1-d.Rho))
+}
+
+func (d NegativeBinomialDist) Entropy() float64 {
+	return discreteEntropy(d.PMF, d.Mode(), 0, math.MaxInt64)
+}
+
+func NegativeBinomial_PMF(ρ float64, r int64) func(k int64) float64 {
+	return NegativeBinomialDist{ρ, float64(r)}.PMF
+}
+
 func NegativeBinomial_PMF_At(ρ float64, r, k int64) float64 {
 	pmf := NegativeBinomial_PMF(ρ, r)
 	return pmf(k)
 }
 
 func NegativeBinomial_LnPMF(ρ float64, r int64) func(i int64) float64 {
-	return func(k int64) float64 {
-		return LnChoose(k+r-1, r-1) + log(ρ)*float64(r) + log(1-ρ)*float64(k)
-	}
+	return NegativeBinomialDist{ρ, float64(r)}.LogPMF
 }
 
-//NegativeBinomial(ρ, r) => number of NextBernoulli(ρ) failures before r successes
+// NegativeBinomial(ρ, r) => number of NextBernoulli(ρ) failures before r successes
 func NextNegativeBinomial(ρ float64, r int64) int64 {
+	return NextNegativeBinomialWith(DefaultRNG, ρ, r)
+}
+func NextNegativeBinomialWith(rng RNG, ρ float64, r int64) int64 {
 	k := iZero
-	for r >= 0 {
-		i := NextBernoulli(ρ)
+	for r > 0 {
+		i := NextBernoulliWith(rng, ρ)
 		r -= i
 		k += (1 - i)
 	}
@@ -51,13 +151,19 @@ func NegativeBinomial(ρ float64, r int64) func() int64 {
 }
 
 func NegativeBinomial_CDF(ρ float64, r int64) func(k int64) float64 {
-	return func(k int64) float64 {
-		Ip := Beta_CDF_At(float64(k+1), float64(r), ρ)
-		return 1 - Ip
-	}
+	return NegativeBinomialDist{ρ, float64(r)}.CDF
 }
 
 func NegativeBinomial_CDF_At(ρ float64, r, k int64) float64 {
 	cdf := NegativeBinomial_CDF(ρ, r)
 	return cdf(k)
 }
+
+// Inverse of the cumulative distribution function: the smallest k with P(X <= k) >= p
+func NegativeBinomial_InvCDF(ρ float64, r int64) func(p float64) int64 {
+	return NegativeBinomialDist{ρ, float64(r)}.Quantile
+}
+
+func NegativeBinomial_InvCDF_For(ρ float64, r int64, p float64) int64 {
+	return NegativeBinomialDist{ρ, float64(r)}.Quantile(p)
+}
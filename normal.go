@@ -2,7 +2,6 @@ package stat
 
 import (
 	"math"
-	"math/rand"
 )
 
 func rat_eval(a []float64, na int64, b []float64, nb int64, x float64) float64 {
@@ -71,32 +70,106 @@ func tail(r float64) float64 {
 	return x
 }
 
+// Normal distribution with mean Mu and standard deviation Sigma
+type NormalDist struct {
+	Mu, Sigma float64
+}
+
+// NewNormalDist returns a Normal distribution, checking that σ > 0.
+func NewNormalDist(μ, σ float64) (NormalDist, error) {
+	if !(σ > 0) || math.IsInf(σ, 0) || math.IsNaN(μ) || math.IsInf(μ, 0) {
+		return NormalDist{}, invalidParameter("Normal μ = %v, σ = %v", μ, σ)
+	}
+	return NormalDist{μ, σ}, nil
+}
+
+func (d NormalDist) PDF(x float64) float64 {
+	z := (x - d.Mu) / d.Sigma
+	return 0.3989422804014327 / d.Sigma * exp(-z*z/2)
+}
+
+func (d NormalDist) LogPDF(x float64) float64 {
+	z := (x - d.Mu) / d.Sigma
+	return -0.91893853320467267 - log(d.Sigma) - z*z/2
+}
+
+func (d NormalDist) logPDFs(dst, x []float64) {
+	lnNorm := -0.91893853320467267 - log(d.Sigma)
+	s := 1 / d.Sigma
+	for i, xi := range x {
+		z := (xi - d.Mu) * s
+		dst[i] = lnNorm - z*z/2
+	}
+}
+
+func (d NormalDist) CDF(x float64) float64 {
+	return 0.5 * math.Erfc((d.Mu-x)/(d.Sigma*math.Sqrt2))
+}
+
+func (d NormalDist) Survival(x float64) float64 {
+	return 0.5 * math.Erfc((x-d.Mu)/(d.Sigma*math.Sqrt2))
+}
+
+func (d NormalDist) LogCDF(x float64) float64      { return lnNormalCDF((x - d.Mu) / d.Sigma) }
+func (d NormalDist) LogSurvival(x float64) float64 { return lnNormalCDF((d.Mu - x) / d.Sigma) }
+
+func (d NormalDist) Quantile(p float64) float64 {
+	switch p {
+	case 0:
+		return math.Inf(-1)
+	case 1:
+		return math.Inf(1)
+	}
+	return d.Mu + d.Sigma*Z_InvCDF_For(p)
+}
+
+func (d NormalDist) Rand() float64            { return d.RandWith(DefaultRNG) }
+func (d NormalDist) RandWith(rng RNG) float64 { return NextNormalWith(rng, d.Mu, d.Sigma) }
+func (d NormalDist) Mean() float64            { return d.Mu }
+func (d NormalDist) Variance() float64        { return d.Sigma * d.Sigma }
+
+func (d NormalDist) Mode() float64       { return d.Mu }
+func (d NormalDist) Skewness() float64   { return 0 }
+func (d NormalDist) ExKurtosis() float64 { return 0 }
+func (d NormalDist) Entropy() float64    { return 0.5 * log(2*π*math.E*d.Sigma*d.Sigma) }
+
 func Normal_PDF(μ float64, σ float64) func(x float64) float64 {
-	normal_normalizer := 0.3989422804014327 / σ
-	return func(x float64) float64 { return normal_normalizer * exp(-1*(x-μ)*(x-μ)/(2*σ*σ)) }
+	return NormalDist{μ, σ}.PDF
 }
 
 func Normal_LnPDF(μ float64, σ float64) func(x float64) float64 {
-	ln_normal_normalizer := -0.91893853320467267 - log(σ)
-	return func(x float64) float64 { return ln_normal_normalizer - (x-μ)*(x-μ)/(2*σ*σ) }
+	return NormalDist{μ, σ}.LogPDF
 }
 
-func NextNormal(μ float64, σ float64) float64 { return rand.NormFloat64()*σ + μ }
+func NextNormal(μ float64, σ float64) float64 { return NextNormalWith(DefaultRNG, μ, σ) }
+func NextNormalWith(rng RNG, μ float64, σ float64) float64 {
+	return rng.NormFloat64()*σ + μ
+}
 
 func Normal(μ, σ float64) func() float64 {
-	return func() float64 { return NextNormal(μ, σ) }
+	return NormalDist{μ, σ}.Rand
 }
 
 // Cumulative Distribution Function for the Normal distribution
 func Normal_CDF(μ, σ float64) func(x float64) float64 {
-	return func(x float64) float64 { return ((1.0 / 2.0) * (1 + math.Erf((x-μ)/(σ*math.Sqrt2)))) }
+	return NormalDist{μ, σ}.CDF
 }
 
-// Inverse CDF of Normal distribution for probability p //// ??? only *sigma? from GSL
+// Inverse CDF of the zero-mean Normal distribution with standard deviation
+// sigma for probability p, as in GSL; see Normal_InvCDF_For for a mean μ
 func NormalInv_CDF_For(p, sigma float64) float64 {
 	return sigma * Z_InvCDF_For(p)
 }
 
+// Inverse CDF of the Normal distribution
+func Normal_InvCDF(μ, σ float64) func(p float64) float64 {
+	return NormalDist{μ, σ}.Quantile
+}
+
+func Normal_InvCDF_For(μ, σ, p float64) float64 {
+	return NormalDist{μ, σ}.Quantile(p)
+}
+
 // Probability Density Function for the Standard Normal distribution
 func Z_PDF() func(float64) float64 {
 	return Normal_PDF(0, 1)
@@ -119,6 +192,11 @@ func Z_CDF_At(x float64) float64 {
 	return cdf(x)
 }
 
+// Upper tail probability P(Z > x) of the Standard Normal distribution
+func Z_Survival_At(x float64) float64 {
+	return 0.5 * math.Erfc(x/math.Sqrt2)
+}
+
 // Inverse CDF of Standard Normal distribution for probability p
 func Z_InvCDF_For(p float64) float64 {
 
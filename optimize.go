@@ -0,0 +1,97 @@
+// Numerical minimization
+
+package stat
+
+import (
+	"math"
+	"sort"
+)
+
+const maxNelderMeadIter = 20000
+
+// nelderMead minimizes f from x0 by the Nelder-Mead simplex method, starting
+// with a simplex of edge step. It stops when the simplex spans less than tol
+// in every coordinate, restarting once from the best vertex to guard against
+// a simplex that has collapsed early. f may return +Inf outside its domain.
+func nelderMead(f func([]float64) float64, x0 []float64, step, tol float64) ([]float64, float64, error) {
+	x, fx, err := nelderMeadOnce(f, x0, step, tol)
+	if err != nil {
+		return x, fx, err
+	}
+	return nelderMeadOnce(f, x, step/10, tol)
+}
+
+func nelderMeadOnce(f func([]float64) float64, x0 []float64, step, tol float64) ([]float64, float64, error) {
+	n := len(x0)
+	type vertex struct {
+		x  []float64
+		fx float64
+	}
+	s := make([]vertex, n+1)
+	for i := range s {
+		x := append([]float64(nil), x0...)
+		if i > 0 {
+			x[i-1] += step
+		}
+		s[i] = vertex{x, f(x)}
+	}
+	// point returns c + t (x - c)
+	point := func(c, x []float64, t float64) []float64 {
+		y := make([]float64, n)
+		for j := range y {
+			y[j] = c[j] + t*(x[j]-c[j])
+		}
+		return y
+	}
+	for iter := 0; iter < maxNelderMeadIter; iter++ {
+		sort.Slice(s, func(a, b int) bool { return s[a].fx < s[b].fx })
+		done := true
+		for j := 0; j < n && done; j++ {
+			lo, hi := s[0].x[j], s[0].x[j]
+			for _, v := range s[1:] {
+				lo, hi = math.Min(lo, v.x[j]), math.Max(hi, v.x[j])
+			}
+			done = hi-lo <= tol*math.Max(1, abs(s[0].x[j]))
+		}
+		if done {
+			return s[0].x, s[0].fx, nil
+		}
+
+		c := make([]float64, n) // centroid of all but the worst
+		for _, v := range s[:n] {
+			for j := range c {
+				c[j] += v.x[j] / float64(n)
+			}
+		}
+		worst := &s[n]
+		r := point(c, worst.x, -1)
+		fr := f(r)
+		switch {
+		case fr < s[0].fx:
+			e := point(c, worst.x, -2)
+			if fe := f(e); fe < fr {
+				*worst = vertex{e, fe}
+			} else {
+				*worst = vertex{r, fr}
+			}
+		case fr < s[n-1].fx:
+			*worst = vertex{r, fr}
+		default:
+			t := 0.5 // inside contraction
+			if fr < worst.fx {
+				t = -0.5 // outside contraction
+			}
+			k := point(c, worst.x, t)
+			if fk := f(k); fk < math.Min(fr, worst.fx) {
+				*worst = vertex{k, fk}
+				break
+			}
+			for i := 1; i <= n; i++ {
+				s[i].x = point(s[0].x, s[i].x, 0.5)
+				s[i].fx = f(s[i].x)
+			}
+		}
+	}
+	sort.Slice(s, func(a, b int) bool { return s[a].fx < s[b].fx })
+	return s[0].x, s[0].fx, noConvergence("Nelder-Mead after %d iterations", maxNelderMeadIter)
+}
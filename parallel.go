@@ -0,0 +1,68 @@
+// Parallel sampling
+
+package stat
+
+import (
+	"runtime"
+	"sync"
+)
+
+// ParallelBlock is the number of consecutive indices that ParallelFor hands to
+// a worker at a time. Each block draws from its own stream, so the block
+// size, not the number of workers, determines the random numbers used.
+const ParallelBlock = 256
+
+// ParallelFor calls fn(i, rng) for every i in [0, n) from workers goroutines,
+// or runtime.GOMAXPROCS(0) of them if workers <= 0. Indices are processed in
+// blocks of ParallelBlock, and block b uses NewStreamRNG(seed, b), so for a
+// given seed the results do not depend on the number of workers or on
+// scheduling, provided fn only writes to state owned by index i.
+func ParallelFor(n, workers int, seed int64, fn func(i int, rng RNG)) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	blocks := (n + ParallelBlock - 1) / ParallelBlock
+	if workers > blocks {
+		workers = blocks
+	}
+	next := make(chan int, blocks)
+	for b := 0; b < blocks; b++ {
+		next <- b
+	}
+	close(next)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for b := range next {
+				rng := NewStreamRNG(seed, b)
+				end := (b + 1) * ParallelBlock
+				if end > n {
+					end = n
+				}
+				for i := b * ParallelBlock; i < end; i++ {
+					fn(i, rng)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// SampleParallel fills dst with draws from d across workers goroutines; see
+// ParallelFor for how the result depends on seed.
+func SampleParallel(d ContinuousDistribution, seed int64, workers int, dst []float64) {
+	ParallelFor(len(dst), workers, seed, func(i int, rng RNG) {
+		dst[i] = d.RandWith(rng)
+	})
+}
+
+// SampleDiscreteParallel fills dst with draws from d across workers
+// goroutines; see ParallelFor for how the result depends on seed.
+func SampleDiscreteParallel(d DiscreteDistribution, seed int64, workers int, dst []int64) {
+	ParallelFor(len(dst), workers, seed, func(i int, rng RNG) {
+		dst[i] = d.RandWith(rng)
+	})
+}
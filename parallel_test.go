@@ -0,0 +1,62 @@
+package stat
+
+import (
+	"testing"
+)
+
+func TestParallelReproducible(t *testing.T) {
+	const n = 3*ParallelBlock + 17
+	d := GammaDist{2.5, 1}
+	ref := make([]float64, n)
+	SampleParallel(d, 7, 1, ref)
+	for _, workers := range []int{0, 2, 3, 8, 100} {
+		x := make([]float64, n)
+		SampleParallel(d, 7, workers, x)
+		for i := range x {
+			if x[i] != ref[i] {
+				t.Fatalf("workers = %d: draw %d is %v, want %v", workers, i, x[i], ref[i])
+			}
+		}
+	}
+	other := make([]float64, n)
+	SampleParallel(d, 8, 4, other)
+	same := 0
+	for i := range other {
+		if other[i] == ref[i] {
+			same++
+		}
+	}
+	if same > 0 {
+		t.Errorf("seeds 7 and 8 share %d draws", same)
+	}
+
+	k := make([]int64, n)
+	k1 := make([]int64, n)
+	SampleDiscreteParallel(PoissonDist{4}, 3, 1, k1)
+	SampleDiscreteParallel(PoissonDist{4}, 3, 5, k)
+	for i := range k {
+		if k[i] != k1[i] {
+			t.Fatalf("discrete draw %d is %d, want %d", i, k[i], k1[i])
+		}
+	}
+}
+
+func TestParallelForMultivariate(t *testing.T) {
+	const n = 1000
+	α := []float64{1, 2, 3}
+	draw := func(workers int) [][]float64 {
+		x := make([][]float64, n)
+		ParallelFor(n, workers, 11, func(i int, rng RNG) {
+			x[i] = NextDirichletWith(rng, α)
+		})
+		return x
+	}
+	a, b := draw(1), draw(6)
+	for i := range a {
+		for j := range a[i] {
+			if a[i][j] != b[i][j] {
+				t.Fatalf("draw %d differs: %v vs %v", i, a[i], b[i])
+			}
+		}
+	}
+}
@@ -8,27 +8,101 @@ import (
 	. "github.com/ematvey/go-fn/fn"
 )
 
-/*
-func Poisson_LnPMF(λ float64) (foo func(i int64) float64) {
-	pmf := Poisson_PMF(λ)
-	return func(i int64) (p float64) {
-		return log(pmf(i))
-		//p = -λ +log(λ)*float64(i)
-		//x := log(Γ(float64(i)+1))
-		//_ = x
-		//p -= LnΓ(float64(i)+1)
-		//return p
+// Poisson distribution with rate Lambda
+type PoissonDist struct {
+	Lambda float64
+}
+
+// NewPoissonDist returns a Poisson distribution, checking that λ >= 0.
+func NewPoissonDist(λ float64) (PoissonDist, error) {
+	if !(λ >= 0) || math.IsInf(λ, 0) {
+		return PoissonDist{}, invalidParameter("Poisson λ = %v", λ)
+	}
+	return PoissonDist{λ}, nil
+}
+
+func (d PoissonDist) PMF(k int64) float64 {
+	if k < 0 {
+		return 0
+	}
+	return math.Exp(d.LogPMF(k))
+}
+
+func (d PoissonDist) LogPMF(k int64) float64 {
+	if k < 0 {
+		return negInf
+	}
+	i := float64(k)
+	return xlogy(i, d.Lambda) - LnΓ(i+1) - d.Lambda
+}
+
+func (d PoissonDist) logPMFs(dst []float64, k []int64) {
+	lnλ := log(d.Lambda)
+	for i, ki := range k {
+		switch {
+		case ki < 0:
+			dst[i] = negInf
+		case ki == 0:
+			dst[i] = -d.Lambda
+		default:
+			dst[i] = float64(ki)*lnλ - LnΓ(float64(ki)+1) - d.Lambda
+		}
 	}
 }
+
+func (d PoissonDist) CDF(k int64) float64      { return math.Exp(d.LogCDF(k)) }
+func (d PoissonDist) Survival(k int64) float64 { return math.Exp(d.LogSurvival(k)) }
+
+// P(X <= k) = Q(k+1, λ), the regularized upper incomplete gamma function
+func (d PoissonDist) LogCDF(k int64) float64 {
+	if k < 0 {
+		return negInf
+	}
+	_, lnQ := lnGammaIncReg(float64(k+1), d.Lambda)
+	return lnQ
+}
+
+func (d PoissonDist) LogSurvival(k int64) float64 {
+	if k < 0 {
+		return 0
+	}
+	lnP, _ := lnGammaIncReg(float64(k+1), d.Lambda)
+	return lnP
+}
+
+func (d PoissonDist) Quantile(p float64) int64 {
+	guess := quantileGuess(d.Lambda, sqrt(d.Lambda), d.Skewness(), p, 0, math.MaxInt64)
+	return discreteQuantile(d.CDF, p, guess, 0, math.MaxInt64)
+}
+
+func (d PoissonDist) Rand() int64            { return d.RandWith(DefaultRNG) }
+func (d PoissonDist) RandWith(rng RNG) int64 { return NextPoissonWith(rng, d.Lambda) }
+func (d PoissonDist) Mean() float64          { return d.Lambda }
+func (d PoissonDist) Variance() float64      { return d.Lambda }
+
+func (d PoissonDist) Mode() int64         { return int64(math.Floor(d.Lambda)) }
+func (d PoissonDist) Skewness() float64   { return 1 / sqrt(d.Lambda) }
+func (d PoissonDist) ExKurtosis() float64 { return 1 / d.Lambda }
+
+func (d PoissonDist) Entropy() float64 {
+	return discreteEntropy(d.PMF, d.Mode(), 0, math.MaxInt64)
+}
+
+/*
+	func Poisson_LnPMF(λ float64) (foo func(i int64) float64) {
+		pmf := Poisson_PMF(λ)
+		return func(i int64) (p float64) {
+			return log(pmf(i))
+			//p = -λ +log(λ)*float64(i)
+			//x := log(Γ(float64(i)+1))
+			//_ = x
+			//p -= LnΓ(float64(i)+1)
+			//return p
+		}
+	}
 */
 func Poisson_LnPMF(λ float64) func(k int64) float64 {
-	return func(k int64) (p float64) {
-		i := float64(k)
-		a := log(λ) * i
-		b := log(Γ(i + 1))
-		p = a - b - λ
-		return p
-	}
+	return PoissonDist{λ}.LogPMF
 }
 
 /*
@@ -48,11 +122,7 @@ func Poisson_PMF(λ float64) func(k int64) float64 {
 */
 
 func Poisson_PMF(λ float64) func(k int64) float64 {
-	pmf := Poisson_LnPMF(λ)
-	return func(k int64) float64 {
-		p := math.Exp(pmf(k))
-		return p
-	}
+	return PoissonDist{λ}.PMF
 }
 
 func Poisson_PMF_At(λ float64, k int64) float64 {
@@ -60,32 +130,51 @@ func Poisson_PMF_At(λ float64, k int64) float64 {
 	return pmf(k)
 }
 
-func NextPoisson(λ float64) int64 {
-	// this can be improved upon
+func NextPoisson(λ float64) int64 { return NextPoissonWith(DefaultRNG, λ) }
+func NextPoissonWith(rng RNG, λ float64) int64 {
+	if λ >= 10 {
+		return nextPoissonPTRS(rng, λ)
+	}
+	// multiply uniforms until the product falls below exp(-λ)
 	i := iZero
 	t := exp(-λ)
-	p := fOne
-	for ; p > t; p *= NextUniform() {
+	for p := rng.Float64(); p > t; p *= rng.Float64() {
 		i++
 	}
 	return i
 }
-func Poisson(λ float64) func() int64 {
-	return func() int64 {
-		return NextPoisson(λ)
+
+// nextPoissonPTRS draws from a Poisson distribution with λ >= 10 by the
+// transformed rejection with squeeze of Hörmann (1993), in constant expected
+// time.
+func nextPoissonPTRS(rng RNG, λ float64) int64 {
+	lnλ := log(λ)
+	b := 0.931 + 2.53*sqrt(λ)
+	a := -0.059 + 0.02483*b
+	lnInvα := log(1.1239 + 1.1328/(b-3.4))
+	vr := 0.9277 - 3.6224/(b-2)
+	for {
+		u := rng.Float64() - 0.5
+		v := rng.Float64()
+		us := 0.5 - abs(u)
+		k := math.Floor((2*a/us+b)*u + λ + 0.43)
+		if us >= 0.07 && v <= vr {
+			return int64(k)
+		}
+		if k < 0 || us < 0.013 && v > us {
+			continue
+		}
+		if log(v)+lnInvα-log(a/(us*us)+b) <= -λ+k*lnλ-LnΓ(k+1) {
+			return int64(k)
+		}
 	}
 }
+func Poisson(λ float64) func() int64 {
+	return PoissonDist{λ}.Rand
+}
 
 func Poisson_CDF(λ float64) func(k int64) float64 {
-	return func(k int64) float64 {
-		var p float64 = 0
-		var i int64
-		pmf := Poisson_PMF(λ)
-		for i = 0; i <= k; i++ {
-			p += pmf(i)
-		}
-		return p
-	}
+	return PoissonDist{λ}.CDF
 }
 
 func Poisson_CDF_a(λ float64) func(k int64) float64 { // analytic solution, less precision
@@ -106,3 +195,12 @@ func LnPoisson_CDF_a(λ float64) func(k int64) float64 { // analytic solution, l
 		return log(IΓ(k1, λ)) - LnFact(float64(k))
 	}
 }
+
+// Inverse of the cumulative distribution function: the smallest k with P(X <= k) >= p
+func Poisson_InvCDF(λ float64) func(p float64) int64 {
+	return PoissonDist{λ}.Quantile
+}
+
+func Poisson_InvCDF_For(λ, p float64) int64 {
+	return PoissonDist{λ}.Quantile(p)
+}
@@ -0,0 +1,127 @@
+// Numerical integration
+
+package stat
+
+import (
+	"math"
+	"sort"
+)
+
+const maxQuadIntervals = 1000
+
+// 15-point Kronrod nodes on [-1, 1] (the positive half), with weights for
+// the Kronrod rule and for the embedded 7-point Gauss rule at xgk[1], xgk[3], ...
+var (
+	xgk = [8]float64{
+		0.991455371120812639, 0.949107912342758525, 0.864864423359769073, 0.741531185599394440,
+		0.586087235467691130, 0.405845151377397167, 0.207784955007898468, 0,
+	}
+	wgk = [8]float64{
+		0.022935322010529225, 0.063092092629978553, 0.104790010322250184, 0.140653259715525919,
+		0.169004726639267903, 0.190350578064785410, 0.204432940075298892, 0.209482141084727828,
+	}
+	wg = [4]float64{
+		0.129484966168869693, 0.279705391489276668, 0.381830050505118945, 0.417959183673469388,
+	}
+)
+
+// integrate returns the integral of f over [a, b], either of which may be
+// infinite, and an estimate of its absolute error. It uses globally adaptive
+// 15-point Gauss-Kronrod quadrature, bisecting the interval with the largest
+// error until the total is below tol relative to the result or
+// maxQuadIntervals is reached. f is never evaluated at the limits, so
+// integrable singularities there are handled.
+func integrate(f func(float64) float64, a, b, tol float64) (float64, float64) {
+	switch {
+	case a == b:
+		return 0, 0
+	case a > b:
+		v, e := integrate(f, b, a, tol)
+		return -v, e
+	case math.IsInf(a, -1) && math.IsInf(b, 1):
+		// x = t / (1 - t²)
+		return integrateFinite(func(t float64) float64 {
+			u := 1 / (1 - t*t)
+			return f(t*u) * (1 + t*t) * u * u
+		}, -1, 1, tol)
+	case math.IsInf(b, 1):
+		// x = a + t / (1 - t)
+		return integrateFinite(func(t float64) float64 {
+			u := 1 / (1 - t)
+			return f(a+t*u) * u * u
+		}, 0, 1, tol)
+	case math.IsInf(a, -1):
+		// x = b - (1 - t) / t
+		return integrateFinite(func(t float64) float64 {
+			return f(b-(1-t)/t) / (t * t)
+		}, 0, 1, tol)
+	}
+	return integrateFinite(f, a, b, tol)
+}
+
+// integratePieces integrates f over [min(cuts), max(cuts)] piece by piece
+// between the sorted cuts, which should include any points where f jumps or
+// has a kink, since the error estimate of a single rule can miss them.
+func integratePieces(f func(float64) float64, cuts []float64, tol float64) (v float64) {
+	c := append([]float64(nil), cuts...)
+	sort.Float64s(c)
+	for i := 1; i < len(c); i++ {
+		if c[i] > c[i-1] {
+			vi, _ := integrate(f, c[i-1], c[i], tol)
+			v += vi
+		}
+	}
+	return
+}
+
+type quadInterval struct {
+	a, b, v, err float64
+}
+
+func integrateFinite(f func(float64) float64, a, b, tol float64) (float64, float64) {
+	iv := []quadInterval{gaussKronrod(f, a, b)}
+	for len(iv) < maxQuadIntervals {
+		var v, e float64
+		worst := 0
+		for i, q := range iv {
+			v += q.v
+			e += q.err
+			if q.err > iv[worst].err {
+				worst = i
+			}
+		}
+		if e <= tol*abs(v) || e == 0 || math.IsNaN(e) {
+			break
+		}
+		q := iv[worst]
+		m := q.a + (q.b-q.a)/2
+		if m <= q.a || m >= q.b {
+			break // the interval cannot be split further
+		}
+		iv[worst] = gaussKronrod(f, q.a, m)
+		iv = append(iv, gaussKronrod(f, m, q.b))
+	}
+	var v, e float64
+	for _, q := range iv {
+		v += q.v
+		e += q.err
+	}
+	return v, e
+}
+
+// gaussKronrod applies the 15-point Kronrod rule to [a, b], estimating the
+// error by its difference from the embedded Gauss rule.
+func gaussKronrod(f func(float64) float64, a, b float64) quadInterval {
+	c, h := (a+b)/2, (b-a)/2
+	fc := f(c)
+	k := wgk[7] * fc
+	g := wg[3] * fc
+	for i := 0; i < 7; i++ {
+		s := f(c-h*xgk[i]) + f(c+h*xgk[i])
+		k += wgk[i] * s
+		if i%2 == 1 {
+			g += wg[i/2] * s
+		}
+	}
+	return quadInterval{a, b, k * h, abs((k - g) * h)}
+}
@@ -0,0 +1,28 @@
+package stat
+
+import (
+	"math"
+	"testing"
+)
+
+func TestIntegrate(t *testing.T) {
+	cases := []struct {
+		name string
+		f    func(float64) float64
+		a, b float64
+		want float64
+	}{
+		{"sin", math.Sin, 0, π, 2},
+		{"sqrt singularity", func(x float64) float64 { return 1 / math.Sqrt(x) }, 0, 1, 2},
+		{"gaussian", func(x float64) float64 { return math.Exp(-x * x / 2) }, math.Inf(-1), math.Inf(1), math.Sqrt(2 * π)},
+		{"upper half line", func(x float64) float64 { return math.Exp(-x) }, 1, math.Inf(1), math.Exp(-1)},
+		{"lower half line", func(x float64) float64 { return 1 / (1 + x*x) }, math.Inf(-1), 0, π / 2},
+		{"reversed", math.Cos, π / 2, 0, -1},
+	}
+	for _, c := range cases {
+		got, _ := integrate(c.f, c.a, c.b, 1e-12)
+		if abs(got-c.want) > 1e-10*abs(c.want) {
+			t.Errorf("%s: got %v, want %v", c.name, got, c.want)
+		}
+	}
+}
@@ -1,24 +1,101 @@
 package stat
 
 import (
-	"math/rand"
+	"math"
 )
 
-func Range_PMF(n int64) func(i int64) float64 {
-	return func(i int64) float64 {
-		return fOne / float64(n)
+// Discrete uniform distribution on 0, 1, ..., N-1
+type RangeDist struct {
+	N int64
+}
+
+// NewRangeDist returns a discrete uniform distribution, checking that n >= 1.
+func NewRangeDist(n int64) (RangeDist, error) {
+	if n < 1 {
+		return RangeDist{}, invalidParameter("Range n = %d", n)
 	}
+	return RangeDist{n}, nil
 }
-func LnRange_PMF(n int64) func(i int64) float64 {
-	return func(i int64) float64 {
-		return -log(float64(n))
+
+func (d RangeDist) PMF(k int64) float64 {
+	if k < 0 || k >= d.N {
+		return 0
 	}
+	return fOne / float64(d.N)
+}
+
+func (d RangeDist) LogPMF(k int64) float64 {
+	if k < 0 || k >= d.N {
+		return negInf
+	}
+	return -log(float64(d.N))
+}
+
+func (d RangeDist) CDF(k int64) float64 {
+	switch {
+	case k < 0:
+		return 0
+	case k >= d.N:
+		return 1
+	}
+	return float64(k+1) / float64(d.N)
+}
+
+func (d RangeDist) Survival(k int64) float64 {
+	switch {
+	case k < 0:
+		return 1
+	case k >= d.N:
+		return 0
+	}
+	return float64(d.N-k-1) / float64(d.N)
+}
+
+func (d RangeDist) LogCDF(k int64) float64      { return log(d.CDF(k)) }
+func (d RangeDist) LogSurvival(k int64) float64 { return log(d.Survival(k)) }
+
+func (d RangeDist) Quantile(p float64) int64 {
+	// the closed form ceil(pN) - 1 may be off by one after rounding
+	guess := int64(math.Ceil(p*float64(d.N))) - 1
+	return discreteQuantile(d.CDF, p, guess, 0, d.N-1)
+}
+
+func (d RangeDist) Rand() int64            { return d.RandWith(DefaultRNG) }
+func (d RangeDist) RandWith(rng RNG) int64 { return NextRangeWith(rng, d.N) }
+
+func (d RangeDist) Mean() float64 { return float64(d.N-1) / 2 }
+
+func (d RangeDist) Variance() float64 {
+	n := float64(d.N)
+	return (n*n - 1) / 12
+}
+
+// Every outcome is a mode; the smallest is returned.
+func (d RangeDist) Mode() int64       { return 0 }
+func (d RangeDist) Skewness() float64 { return 0 }
+
+func (d RangeDist) ExKurtosis() float64 {
+	n2 := float64(d.N) * float64(d.N)
+	return -6 * (n2 + 1) / (5 * (n2 - 1))
+}
+
+func (d RangeDist) Entropy() float64 { return log(float64(d.N)) }
+
+func Range_PMF(n int64) func(i int64) float64 {
+	return RangeDist{n}.PMF
+}
+func LnRange_PMF(n int64) func(i int64) float64 {
+	return RangeDist{n}.LogPMF
 }
 func NextRange(n int64) int64 {
-	return rand.Int63n(n)
+	return NextRangeWith(DefaultRNG, n)
+}
+func NextRangeWith(rng RNG, n int64) int64 {
+	return rng.Int63n(n)
 }
 func Range(n int64) func() int64 {
-	return func() int64 {
-		return NextRange(n)
-	}
+	return RangeDist{n}.Rand
+}
+func Range_InvCDF(n int64) func(p float64) int64 {
+	return RangeDist{n}.Quantile
 }
@@ -0,0 +1,289 @@
+// Rank-based nonparametric tests
+
+package stat
+
+import (
+	"math"
+)
+
+// maxExactRank is the sample size from which the rank-sum and signed-rank
+// tests switch from their exact distributions to Normal approximations, as
+// in R.
+const maxExactRank = 50
+
+// tieSum returns Σ (t³ - t) over the groups of t tied values in x.
+func tieSum(x []float64) float64 {
+	s := sortedCopy(x)
+	var ts float64
+	for i := 0; i < len(s); {
+		j := i
+		for j < len(s) && s[j] == s[i] {
+			j++
+		}
+		t := float64(j - i)
+		ts += t*t*t - t
+		i = j
+	}
+	return ts
+}
+
+// normalRankP returns the p-value of a rank statistic s with mean μ and
+// standard deviation σ under the null hypothesis, by the Normal
+// approximation with continuity correction.
+func normalRankP(s, μ, σ float64, alt Alternative) float64 {
+	z := s - μ
+	switch alt {
+	case Less:
+		return Z_CDF_At((z + 0.5) / σ)
+	case Greater:
+		return Z_Survival_At((z - 0.5) / σ)
+	}
+	c := 0.5
+	if z < 0 {
+		c = -0.5
+	} else if z == 0 {
+		c = 0
+	}
+	return math.Min(1, 2*Z_Survival_At(abs(z-c)/σ))
+}
+
+// exactRankP returns the p-value of the integer statistic s whose null
+// distribution is pmf over 0, 1, ..., len(pmf)-1, symmetric about its mean.
+func exactRankP(pmf []float64, s int, alt Alternative) float64 {
+	var lower, upper float64 // P(S <= s), P(S >= s)
+	for k, p := range pmf {
+		if k <= s {
+			lower += p
+		}
+		if k >= s {
+			upper += p
+		}
+	}
+	switch alt {
+	case Less:
+		return math.Min(1, lower)
+	case Greater:
+		return math.Min(1, upper)
+	}
+	return math.Min(1, 2*math.Min(lower, upper))
+}
+
+// rankSumPMF returns the null distribution of the Mann-Whitney statistic U
+// for samples of sizes m and n without ties, by the recurrence
+// P_{m,n}(u) = m/(m+n) P_{m-1,n}(u-n) + n/(m+n) P_{m,n-1}(u).
+func rankSumPMF(m, n int) []float64 {
+	// prev[j] is P_{i-1,j} and cur[j] is P_{i,j}, over u = 0 ... m n
+	size := m*n + 1
+	prev := make([][]float64, n+1)
+	for j := range prev {
+		prev[j] = make([]float64, size)
+		prev[j][0] = 1
+	}
+	for i := 1; i <= m; i++ {
+		cur := make([][]float64, n+1)
+		cur[0] = make([]float64, size)
+		cur[0][0] = 1
+		for j := 1; j <= n; j++ {
+			c := make([]float64, size)
+			a, b := float64(i)/float64(i+j), float64(j)/float64(i+j)
+			for u := 0; u <= i*j; u++ {
+				if u >= j {
+					c[u] += a * prev[j][u-j]
+				}
+				c[u] += b * cur[j-1][u]
+			}
+			cur[j] = c
+		}
+		prev = cur
+	}
+	return prev[n]
+}
+
+// signedRankPMF returns the null distribution of the Wilcoxon signed-rank
+// statistic V for n untied, non-zero differences: the subset sums of
+// 1 ... n, each subset having probability 2^-n.
+func signedRankPMF(n int) []float64 {
+	size := n*(n+1)/2 + 1
+	p := make([]float64, size)
+	p[0] = 1
+	for k := 1; k <= n; k++ {
+		for v := k * (k + 1) / 2; v >= k; v-- {
+			p[v] = (p[v] + p[v-k]) / 2
+		}
+		for v := k - 1; v >= 0; v-- {
+			p[v] /= 2
+		}
+	}
+	return p
+}
+
+// MannWhitneyTest is the Mann-Whitney U test, or Wilcoxon rank-sum test,
+// that x and y come from the same distribution, against a shift of x by
+// the alternative. The statistic is U = Σ ranks of x - n_x(n_x+1)/2,
+// using midranks for ties, and equals the number of pairs with x > y, ties
+// counting one half. The p-value is exact for samples smaller than 50
+// without ties, and otherwise comes from the Normal approximation with
+// continuity correction and the variance corrected for ties.
+func MannWhitneyTest(x, y []float64, alt Alternative) (TestResult, error) {
+	if !alt.valid() {
+		return TestResult{}, invalidParameter("MannWhitneyTest alternative %d", alt)
+	}
+	if err := checkSample("MannWhitneyTest", x, 1); err != nil {
+		return TestResult{}, err
+	}
+	if err := checkSample("MannWhitneyTest", y, 1); err != nil {
+		return TestResult{}, err
+	}
+	all := append(append([]float64(nil), x...), y...)
+	r := midranks(all)
+	m, n := len(x), len(y)
+	var u float64
+	for _, ri := range r[:m] {
+		u += ri
+	}
+	u -= float64(m*(m+1)) / 2
+	ts := tieSum(all)
+	var p float64
+	if ts == 0 && m < maxExactRank && n < maxExactRank {
+		p = exactRankP(rankSumPMF(m, n), int(u), alt)
+	} else {
+		N := float64(m + n)
+		σ := sqrt(float64(m) * float64(n) / 12 * (N + 1 - ts/(N*(N-1))))
+		p = normalRankP(u, float64(m)*float64(n)/2, σ, alt)
+	}
+	return TestResult{Statistic: u, PValue: p}, nil
+}
+
+// WilcoxonTest is the Wilcoxon signed-rank test that x is symmetric about
+// μ0, against a shift by the alternative. The statistic V is the sum of the
+// ranks of |x - μ0| over the positive differences, using midranks for ties
+// and dropping zero differences. The p-value is exact for fewer than 50
+// differences without ties or zeros, and otherwise comes from the Normal
+// approximation with continuity correction and the variance corrected for
+// ties.
+func WilcoxonTest(x []float64, μ0 float64, alt Alternative) (TestResult, error) {
+	if !alt.valid() {
+		return TestResult{}, invalidParameter("WilcoxonTest alternative %d", alt)
+	}
+	if err := checkSample("WilcoxonTest", x, 1); err != nil {
+		return TestResult{}, err
+	}
+	var d, ad []float64
+	for _, xi := range x {
+		if di := xi - μ0; di != 0 {
+			d = append(d, di)
+			ad = append(ad, abs(di))
+		}
+	}
+	if len(d) == 0 {
+		return TestResult{}, invalidParameter("WilcoxonTest: every difference is zero")
+	}
+	r := midranks(ad)
+	var v float64
+	for i, di := range d {
+		if di > 0 {
+			v += r[i]
+		}
+	}
+	n := len(d)
+	ts := tieSum(ad)
+	var p float64
+	if ts == 0 && n == len(x) && n < maxExactRank {
+		p = exactRankP(signedRankPMF(n), int(v), alt)
+	} else {
+		nf := float64(n)
+		σ := sqrt(nf*(nf+1)*(2*nf+1)/24 - ts/48)
+		p = normalRankP(v, nf*(nf+1)/4, σ, alt)
+	}
+	return TestResult{Statistic: v, PValue: p}, nil
+}
+
+// PairedWilcoxonTest is the Wilcoxon signed-rank test on the differences
+// x[i] - y[i], that is WilcoxonTest on them with μ0 = 0.
+func PairedWilcoxonTest(x, y []float64, alt Alternative) (TestResult, error) {
+	if len(x) != len(y) {
+		return TestResult{}, dimensionMismatch("PairedWilcoxonTest with %d and %d observations", len(x), len(y))
+	}
+	d := make([]float64, len(x))
+	for i := range d {
+		d[i] = x[i] - y[i]
+	}
+	return WilcoxonTest(d, 0, alt)
+}
+
+// KruskalWallisTest is the Kruskal-Wallis test that the groups come from
+// the same distribution, the rank analogue of OneWayAnova. The statistic
+// H = 12/(N(N+1)) Σ R_i²/n_i - 3(N+1), R_i being the rank sum of group i,
+// is divided by 1 - Σ(t³-t)/(N³-N) to correct for ties, and the p-value
+// comes from its chi-square approximation on k - 1 degrees of freedom.
+func KruskalWallisTest(groups [][]float64) (TestResult, error) {
+	if err := checkGroups("KruskalWallisTest", groups, 1); err != nil {
+		return TestResult{}, err
+	}
+	var all []float64
+	for _, g := range groups {
+		all = append(all, g...)
+	}
+	r := midranks(all)
+	N := float64(len(all))
+	var h float64
+	off := 0
+	for _, g := range groups {
+		var ri float64
+		for _, x := range r[off : off+len(g)] {
+			ri += x
+		}
+		h += ri * ri / float64(len(g))
+		off += len(g)
+	}
+	h = 12/(N*(N+1))*h - 3*(N+1)
+	c := 1 - tieSum(all)/(N*N*N-N)
+	if !(c > 0) {
+		return TestResult{}, invalidParameter("KruskalWallisTest: every observation is tied")
+	}
+	h /= c
+	df := float64(len(groups) - 1)
+	return TestResult{Statistic: h, PValue: XsquareDist{df}.Survival(h), DF: df}, nil
+}
+
+// FriedmanTest is the Friedman test for an unreplicated complete block
+// design, the rank analogue of a two-way analysis of variance: y[i][j] is
+// the observation of treatment j in block i. The observations are ranked
+// within blocks, using midranks for ties, and the statistic
+// 12 Σ (R_j - n(k+1)/2)² / (n k (k+1) - Σ(t³-t)/(k-1)), R_j being the rank
+// sum of treatment j, has a chi-square approximation on k - 1 degrees of
+// freedom.
+func FriedmanTest(y [][]float64) (TestResult, error) {
+	n := len(y)
+	if n < 1 || len(y[0]) < 2 {
+		return TestResult{}, invalidParameter("FriedmanTest needs at least 1 block and 2 treatments")
+	}
+	k := len(y[0])
+	R := make([]float64, k)
+	var ts float64
+	for i, b := range y {
+		if len(b) != k {
+			return TestResult{}, dimensionMismatch("FriedmanTest block %d has %d treatments, want %d", i, len(b), k)
+		}
+		if err := checkSample("FriedmanTest", b, k); err != nil {
+			return TestResult{}, err
+		}
+		for j, r := range midranks(b) {
+			R[j] += r
+		}
+		ts += tieSum(b)
+	}
+	nf, kf := float64(n), float64(k)
+	var ss float64
+	for _, rj := range R {
+		d := rj - nf*(kf+1)/2
+		ss += d * d
+	}
+	den := nf*kf*(kf+1) - ts/(kf-1)
+	if !(den > 0) {
+		return TestResult{}, invalidParameter("FriedmanTest: every block is tied")
+	}
+	q := 12 * ss / den
+	df := kf - 1
+	return TestResult{Statistic: q, PValue: XsquareDist{df}.Survival(q), DF: df}, nil
+}
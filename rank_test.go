@@ -0,0 +1,130 @@
+package stat
+
+import (
+	"testing"
+)
+
+func TestRankPMF(t *testing.T) {
+	// enumerate the rank assignments of small samples
+	const m, n = 4, 5
+	counts := make([]float64, m*n+1)
+	var total float64
+	for mask := 0; mask < 1<<(m+n); mask++ {
+		if popcount(mask) != m {
+			continue
+		}
+		u := 0
+		for b := 0; b < m+n; b++ {
+			if mask&(1<<b) != 0 {
+				u += b // rank b+1, minus its position among the x's below
+			}
+		}
+		counts[u-m*(m-1)/2]++
+		total++
+	}
+	for u, p := range rankSumPMF(m, n) {
+		check(t, "rankSumPMF", p, counts[u]/total, 1e-14)
+	}
+
+	const k = 8
+	counts = make([]float64, k*(k+1)/2+1)
+	for mask := 0; mask < 1<<k; mask++ {
+		v := 0
+		for b := 0; b < k; b++ {
+			if mask&(1<<b) != 0 {
+				v += b + 1
+			}
+		}
+		counts[v]++
+	}
+	for v, p := range signedRankPMF(k) {
+		check(t, "signedRankPMF", p, counts[v]/(1<<k), 1e-14)
+	}
+}
+
+func TestMannWhitneyTest(t *testing.T) {
+	// the examples of R's wilcox.test
+	x := []float64{0.80, 0.83, 1.89, 1.04, 1.45, 1.38, 1.91, 1.64, 0.73, 1.46}
+	y := []float64{1.15, 0.88, 0.90, 0.74, 1.21}
+	r, err := MannWhitneyTest(x, y, Greater)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, "W", r.Statistic, 35, 0)
+	check(t, "p", r.PValue, 0.1272061, 1e-7)
+	r2, _ := MannWhitneyTest(x, y, TwoSided)
+	check(t, "two-sided", r2.PValue, 2*r.PValue, 1e-14)
+	r3, _ := MannWhitneyTest(y, x, Less)
+	check(t, "swapped", r3.PValue, r.PValue, 1e-14)
+
+	// with ties: wilcox.test(c(1, 2, 2, 3, 5), c(2, 4, 4, 6, 7), exact = FALSE)
+	r, _ = MannWhitneyTest([]float64{1, 2, 2, 3, 5}, []float64{2, 4, 4, 6, 7}, TwoSided)
+	check(t, "W ties", r.Statistic, 5, 0)
+	σ := sqrt(25.0 / 12 * (11 - (24+6)/(10.0*9)))
+	check(t, "p ties", r.PValue, 2*Z_CDF_At((5-12.5+0.5)/σ), 1e-14)
+}
+
+func TestWilcoxonTest(t *testing.T) {
+	x := []float64{1.83, 0.50, 1.62, 2.48, 1.68, 1.88, 1.55, 3.06, 1.30}
+	y := []float64{0.878, 0.647, 0.598, 2.05, 1.06, 1.29, 1.06, 3.14, 1.29}
+	r, err := PairedWilcoxonTest(x, y, Greater)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, "V", r.Statistic, 40, 0)
+	check(t, "p", r.PValue, 0.01953125, 1e-12)
+
+	// with a zero and ties: wilcox.test(c(0, 1, -1, 2, 2, 3, -4, 5), exact = FALSE)
+	r, _ = WilcoxonTest([]float64{0, 1, -1, 2, 2, 3, -4, 5}, 0, TwoSided)
+	check(t, "V ties", r.Statistic, 1.5+3.5+3.5+5+7, 1e-14)
+	σ := sqrt(7.0*8*15/24 - (6+6)/48.0)
+	check(t, "p ties", r.PValue, 2*Z_Survival_At((20.5-14-0.5)/σ), 1e-14)
+
+	if _, err := WilcoxonTest([]float64{1, 1}, 1, TwoSided); err == nil {
+		t.Error("zero differences accepted")
+	}
+}
+
+func TestKruskalWallisTest(t *testing.T) {
+	// the Hollander and Wolfe example of R's kruskal.test
+	r, err := KruskalWallisTest([][]float64{
+		{2.9, 3.0, 2.5, 2.6, 3.2},
+		{3.8, 2.7, 4.0, 2.4},
+		{2.8, 3.4, 3.7, 2.2, 2.0},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, "H", r.Statistic, 0.7714286, 1e-7)
+	check(t, "DF", r.DF, 2, 0)
+	check(t, "p", r.PValue, 0.6799648, 1e-7)
+
+	// with two groups H is the square of the uncorrected Normal score of U
+	x, y := []float64{1, 2, 2, 3, 5}, []float64{2, 4, 4, 6, 7}
+	h, _ := KruskalWallisTest([][]float64{x, y})
+	σ2 := 25.0 / 12 * (11 - 30/90.0)
+	check(t, "H = z²", h.Statistic, (5-12.5)*(5-12.5)/σ2, 1e-12)
+}
+
+func TestFriedmanTest(t *testing.T) {
+	// the rounding first base example of R's friedman.test
+	y := [][]float64{
+		{5.40, 5.50, 5.55}, {5.85, 5.70, 5.75}, {5.20, 5.60, 5.50}, {5.55, 5.50, 5.40},
+		{5.90, 5.85, 5.70}, {5.45, 5.55, 5.60}, {5.40, 5.40, 5.35}, {5.45, 5.50, 5.35},
+		{5.25, 5.15, 5.00}, {5.85, 5.80, 5.70}, {5.25, 5.20, 5.10}, {5.65, 5.55, 5.45},
+		{5.60, 5.35, 5.45}, {5.05, 5.00, 4.95}, {5.50, 5.50, 5.40}, {5.45, 5.55, 5.50},
+		{5.55, 5.55, 5.35}, {5.45, 5.50, 5.55}, {5.50, 5.45, 5.25}, {5.65, 5.60, 5.40},
+		{5.70, 5.65, 5.55}, {6.30, 6.30, 6.25},
+	}
+	r, err := FriedmanTest(y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, "Q", r.Statistic, 11.14286, 1e-6)
+	check(t, "DF", r.DF, 2, 0)
+	check(t, "p", r.PValue, 0.003805041, 1e-8)
+
+	if _, err := FriedmanTest([][]float64{{1, 2}, {3}}); err == nil {
+		t.Error("ragged design accepted")
+	}
+}
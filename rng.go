@@ -0,0 +1,65 @@
+// Random number sources
+
+package stat
+
+import (
+	"math/rand"
+)
+
+// RNG is the source of randomness used by the samplers of this package.
+// A *rand.Rand satisfies it, so each simulation can own an isolated,
+// reproducible stream.
+type RNG interface {
+	Seed(seed int64)
+	Float64() float64
+	NormFloat64() float64
+	ExpFloat64() float64
+	Int63n(n int64) int64
+}
+
+// NewRNG returns an RNG with its own state, seeded with seed.
+func NewRNG(seed int64) RNG {
+	return rand.New(rand.NewSource(seed))
+}
+
+// globalRNG draws from NextUniform and the top-level math/rand functions.
+type globalRNG struct{}
+
+func (globalRNG) Seed(seed int64)      { rand.Seed(seed) }
+func (globalRNG) Float64() float64     { return NextUniform() }
+func (globalRNG) NormFloat64() float64 { return rand.NormFloat64() }
+func (globalRNG) ExpFloat64() float64  { return rand.ExpFloat64() }
+func (globalRNG) Int63n(n int64) int64 { return rand.Int63n(n) }
+
+// DefaultRNG is the source used by samplers that are not given an RNG,
+// e.g. NextGamma as opposed to NextGammaWith.
+var DefaultRNG RNG = globalRNG{}
+
+// NewStreamRNG returns the RNG for the given stream of seed. Streams of one
+// seed start from distinct 64-bit states, found by hashing (seed, stream)
+// with SplitMix64, and keep the whole state rather than folding it into the
+// 31-bit seed of rand.NewSource, so work split into numbered pieces can give
+// each piece its own reproducible source.
+func NewStreamRNG(seed int64, stream int) RNG {
+	// the SplitMix64 output at (seed, stream) is a bijection of the sum,
+	// so the states of the streams differ, and they are scattered so
+	// widely that the sequences do not overlap in practice
+	s := splitMix64(uint64(seed) + uint64(stream)*0x9e3779b97f4a7c15)
+	s = splitMix64(s.Uint64())
+	return rand.New(&s)
+}
+
+// splitMix64 is the SplitMix64 generator of Steele, Lea and Flood (2014),
+// a rand.Source64 whose state is the whole 64-bit seed.
+type splitMix64 uint64
+
+func (s *splitMix64) Seed(seed int64) { *s = splitMix64(seed) }
+func (s *splitMix64) Int63() int64    { return int64(s.Uint64() >> 1) }
+
+func (s *splitMix64) Uint64() uint64 {
+	*s += 0x9e3779b97f4a7c15
+	z := uint64(*s)
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
@@ -0,0 +1,59 @@
+package stat
+
+import (
+	"math"
+	"testing"
+
+	mx "github.com/skelterjohn/go.matrix"
+)
+
+func TestRNGReproducible(t *testing.T) {
+	draw := func(rng RNG) []float64 {
+		x := []float64{
+			NextGammaWith(rng, 0.4, 2),
+			NextGammaWith(rng, 7.5, 1),
+			NextBetaWith(rng, 2, 3),
+			float64(NextChoiceWith(rng, []float64{0.2, 0.3, 0.5})),
+			float64(NextPoissonWith(rng, 3)),
+			NormalDist{0, 1}.RandWith(rng),
+		}
+		x = append(x, NextDirichletWith(rng, []float64{1, 2, 3})...)
+		x = append(x, NextMVNormalWith(rng, mx.Zeros(2, 1), mx.Eye(2)).Array()...)
+		y := []float64{1, 2, 3, 4, 5}
+		ShuffleFloat64With(rng, y)
+		return append(x, y...)
+	}
+	a, b := NewRNG(42), NewRNG(42)
+	xa := draw(a)
+	NextGamma(3, 1) // the global source must not interfere
+	xb := draw(b)
+	for i := range xa {
+		if xa[i] != xb[i] {
+			t.Fatalf("draw %d differs: %v vs %v", i, xa[i], xb[i])
+		}
+	}
+	a.Seed(42)
+	if x := draw(a); x[0] != xa[0] {
+		t.Errorf("reseeding did not restart the stream: %v vs %v", x[0], xa[0])
+	}
+}
+
+func TestStreamRNGDistinct(t *testing.T) {
+	// rand.NewSource reduces its seed modulo 2³¹-1, which made streams
+	// 30054 and 51978 of seed 0 identical
+	seen := map[[2]int64]int{}
+	for stream := 0; stream <= 100000; stream++ {
+		rng := NewStreamRNG(0, stream)
+		key := [2]int64{rng.Int63n(math.MaxInt64), rng.Int63n(math.MaxInt64)}
+		if prev, ok := seen[key]; ok {
+			t.Fatalf("streams %d and %d start alike", prev, stream)
+		}
+		seen[key] = stream
+	}
+	a, b := NewStreamRNG(3, 7), NewStreamRNG(3, 7)
+	for i := 0; i < 10; i++ {
+		if x, y := a.Float64(), b.Float64(); x != y {
+			t.Fatalf("draw %d of one stream differs: %v vs %v", i, x, y)
+		}
+	}
+}
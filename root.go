@@ -0,0 +1,173 @@
+// Root finding and numerical inversion of distribution functions
+
+package stat
+
+import (
+	"math"
+)
+
+const (
+	maxRootIter = 200
+	epsilon     = 2.220446049250313e-16
+)
+
+// FindRoot returns x in [a, b] with f(x) = 0 by Brent's method, which
+// combines inverse quadratic interpolation with bisection so that the
+// bracket is guaranteed to shrink. f(a) and f(b) must not have the same sign.
+// The result is accurate to within tol plus a few ulps of x; an error
+// wrapping ErrNoConvergence is returned with the best estimate if the
+// tolerance is not met.
+func FindRoot(f func(float64) float64, a, b, tol float64) (float64, error) {
+	fa, fb := f(a), f(b)
+	switch {
+	case fa == 0:
+		return a, nil
+	case fb == 0:
+		return b, nil
+	case math.IsNaN(fa) || math.IsNaN(fb) || (fa > 0) == (fb > 0):
+		return math.NaN(), invalidParameter("FindRoot: f(%v) = %v and f(%v) = %v do not bracket a root", a, fa, b, fb)
+	}
+	c, fc := a, fa
+	d := b - a
+	e := d
+	for i := 0; i < maxRootIter; i++ {
+		if (fb > 0) == (fc > 0) {
+			c, fc = a, fa
+			d = b - a
+			e = d
+		}
+		if abs(fc) < abs(fb) {
+			a, b, c = b, c, b
+			fa, fb, fc = fb, fc, fb
+		}
+		tol1 := 2*epsilon*abs(b) + tol/2
+		m := (c - b) / 2
+		if abs(m) <= tol1 || fb == 0 {
+			return b, nil
+		}
+		if abs(e) >= tol1 && abs(fa) > abs(fb) && !math.IsInf(fa, 0) && !math.IsInf(fc, 0) {
+			// interpolate: secant when a == c, inverse quadratic otherwise
+			var p, q float64
+			s := fb / fa
+			if a == c {
+				p = 2 * m * s
+				q = 1 - s
+			} else {
+				q0, r := fa/fc, fb/fc
+				p = s * (2*m*q0*(q0-r) - (b-a)*(r-1))
+				q = (q0 - 1) * (r - 1) * (s - 1)
+			}
+			if p > 0 {
+				q = -q
+			} else {
+				p = -p
+			}
+			if 2*p < math.Min(3*m*q-abs(tol1*q), abs(e*q)) {
+				e = d
+				d = p / q
+			} else {
+				d = m
+				e = m
+			}
+		} else {
+			d = m
+			e = m
+		}
+		a, fa = b, fb
+		if abs(d) > tol1 {
+			b += d
+		} else if m > 0 {
+			b += tol1
+		} else {
+			b -= tol1
+		}
+		fb = f(b)
+	}
+	return b, noConvergence("FindRoot: bracket [%v, %v] after %d iterations", b, c, maxRootIter)
+}
+
+// tails is the part of a continuous distribution that invertCDF needs.
+type tails interface {
+	LogCDF(x float64) float64
+	LogSurvival(x float64) float64
+}
+
+// invertCDF returns the p-quantile of d, searching from the initial guess x0.
+// The lower half is found by solving log CDF(x) = log p and the upper half by
+// solving log Survival(x) = log(1-p), so the result keeps its relative
+// accuracy deep in either tail. If positive is set the support is taken to be
+// (0, ∞) and the search runs over log x, otherwise over the whole real line.
+func invertCDF(d tails, p, x0 float64, positive bool) (float64, error) {
+	var lo, hi float64
+	if positive {
+		lo = 0
+	} else {
+		lo = math.Inf(-1)
+	}
+	hi = math.Inf(1)
+	switch {
+	case math.IsNaN(p) || p < 0 || p > 1:
+		return math.NaN(), invalidParameter("probability p = %v", p)
+	case p == 0:
+		return lo, nil
+	case p == 1:
+		return hi, nil
+	}
+
+	// h is increasing in x and zero at the quantile
+	var h func(x float64) float64
+	if p <= 0.5 {
+		lp := log(p)
+		h = func(x float64) float64 { return d.LogCDF(x) - lp }
+	} else {
+		lq := math.Log1p(-p)
+		h = func(x float64) float64 { return lq - d.LogSurvival(x) }
+	}
+	// FindRoot adds a relative tolerance of a few ulps to the absolute one
+	f, u0, tol := h, x0, 1e-300
+	if positive {
+		// over u = log x an absolute tolerance is a relative one in x
+		f = func(u float64) float64 { return h(math.Exp(u)) }
+		u0, tol = math.Log(x0), 4*epsilon
+	}
+	if math.IsNaN(u0) || math.IsInf(u0, 0) {
+		u0 = 0
+	}
+
+	// expand a bracket [a, b] around u0 in doubling steps
+	a, b := u0, u0
+	fa := f(a)
+	fb := fa
+	for step := 1.0; fa > 0; step *= 2 {
+		b, fb = a, fa
+		if a -= step; positive && a < -750 || !positive && math.IsInf(a, -1) {
+			return lo, nil // the quantile underflows
+		}
+		fa = f(a)
+	}
+	for step := 1.0; fb < 0; step *= 2 {
+		a, fa = b, fb
+		if b += step; positive && b > 710 || !positive && math.IsInf(b, 1) {
+			return hi, nil // the quantile overflows
+		}
+		fb = f(b)
+	}
+	if math.IsNaN(fa) || math.IsNaN(fb) {
+		return math.NaN(), noConvergence("invertCDF: distribution function is NaN near %v", x0)
+	}
+	u, err := FindRoot(f, a, b, tol)
+	if positive {
+		return math.Exp(u), err
+	}
+	return u, err
+}
+
+// quantile is invertCDF for methods that cannot return an error: it returns
+// NaN if the inversion fails.
+func quantile(d tails, p, x0 float64, positive bool) float64 {
+	x, err := invertCDF(d, p, x0, positive)
+	if err != nil {
+		return math.NaN()
+	}
+	return x
+}
@@ -0,0 +1,59 @@
+package stat
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestFindRoot(t *testing.T) {
+	f := func(x float64) float64 { return math.Cos(x) - x }
+	x, err := FindRoot(f, 0, 1, 0)
+	if err != nil || math.Abs(x-0.7390851332151607) > 1e-15 {
+		t.Errorf("FindRoot(cos(x) - x) = %v, %v", x, err)
+	}
+	if _, err := FindRoot(f, 1, 2, 0); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("FindRoot without a bracket: err = %v", err)
+	}
+}
+
+// Every quantile should invert its CDF to near machine precision, including
+// shapes below one and probabilities far in either tail.
+func TestQuantileInversion(t *testing.T) {
+	ds := append([]ContinuousDistribution{
+		GammaDist{0.05, 1},
+		GammaDist{1e4, 0.5},
+		InvGammaDist{0.3, 2},
+		BetaDist{0.1, 0.2},
+		BetaDist{500, 3},
+		XsquareDist{0.5},
+		XsquareDist{1000},
+		FDist{0.7, 3},
+		FDist{50, 200},
+		StudentsTDist{0.6},
+	}, continuousDists...)
+	for _, d := range ds {
+		for _, p := range []float64{1e-200, 1e-12, 0.001, 0.2, 0.5, 0.8, 0.999, 1 - 1e-12} {
+			// Either the log probability at x matches, or the quantile lies
+			// within a few ulps of x where the CDF is too steep for that.
+			x := d.Quantile(p)
+			var got, want float64
+			if p <= 0.5 {
+				got, want = d.LogCDF(x), math.Log(p)
+			} else {
+				got, want = d.LogSurvival(x), math.Log1p(-p)
+			}
+			if math.Abs(got-want) <= 1e-13*math.Max(1, math.Abs(want)) {
+				continue
+			}
+			δ := 16*2.2e-16*math.Abs(x) + 1e-300
+			if p <= 0.5 && (d.CDF(x-δ) > p || d.CDF(x+δ) < p) ||
+				p > 0.5 && (d.Survival(x-δ) < 1-p || d.Survival(x+δ) > 1-p) {
+				t.Errorf("%#v: Quantile(%v) = %v, log probability %v, expected %v", d, p, x, got, want)
+			}
+		}
+	}
+	if x := Gamma_InvCDF_For(0.5, 2, 0.1); math.Abs(x-0.015790774093431218) > 1e-15 {
+		t.Errorf("Gamma_InvCDF_For(0.5, 2, 0.1) = %v", x)
+	}
+}
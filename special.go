@@ -0,0 +1,215 @@
+// Special functions not provided by go-fn
+
+package stat
+
+import (
+	"math"
+
+	. "github.com/ematvey/go-fn/fn"
+)
+
+// digamma returns ψ(x), the logarithmic derivative of Γ(x).
+func digamma(x float64) float64 {
+	switch {
+	case math.IsNaN(x) || math.IsInf(x, -1):
+		return math.NaN()
+	case x <= 0 && x == math.Floor(x):
+		return math.NaN()
+	case x < 0:
+		// reflection: ψ(1-x) - ψ(x) = π cot(πx)
+		return digamma(1-x) - π/math.Tan(π*x)
+	}
+	var r float64
+	for ; x < 10; x++ {
+		r -= 1 / x
+	}
+	f := 1 / (x * x)
+	t := f * (1.0/12 - f*(1.0/120-f*(1.0/252-f*(1.0/240-f*(1.0/132)))))
+	return r + log(x) - 0.5/x - t
+}
+
+// trigamma returns ψ'(x), the derivative of the digamma function.
+func trigamma(x float64) float64 {
+	switch {
+	case math.IsNaN(x) || math.IsInf(x, -1):
+		return math.NaN()
+	case x <= 0 && x == math.Floor(x):
+		return math.Inf(1)
+	case x < 0:
+		// reflection: ψ'(1-x) + ψ'(x) = π² / sin²(πx)
+		s := math.Sin(π * x)
+		return -trigamma(1-x) + π*π/(s*s)
+	}
+	var r float64
+	for ; x < 10; x++ {
+		r += 1 / (x * x)
+	}
+	f := 1 / (x * x)
+	t := f * (1.0/6 - f*(1.0/30-f*(1.0/42-f*(1.0/30-f*(5.0/66-f*(691.0/2730-f*7.0/6))))))
+	return r + 1/x + f/2 + t/x
+}
+
+// stirlingError returns log Γ(x) - ((x-1/2) log x - x + log(2π)/2), the
+// remainder of Stirling's series, for x >= 10.
+func stirlingError(x float64) float64 {
+	w := 1 / (x * x)
+	return (1.0/12 - w*(1.0/360-w*(1.0/1260-w*(1.0/1680-w*(1.0/1188-w*(691.0/360360-w*(1.0/156-w*3617.0/122400))))))) / x
+}
+
+// lnBeta returns log B(a, b). For large arguments it cancels the leading
+// terms of Stirling's series analytically, where LnΓ(a) + LnΓ(b) - LnΓ(a+b)
+// would lose most of its digits.
+func lnBeta(a, b float64) float64 {
+	p, q := math.Min(a, b), math.Max(a, b)
+	switch {
+	case p >= 10:
+		corr := stirlingError(p) + stirlingError(q) - stirlingError(p+q)
+		return -0.5*log(q) + 0.91893853320467267 + corr + (p-0.5)*log(p/(p+q)) + q*math.Log1p(-p/(p+q))
+	case q >= 10:
+		corr := stirlingError(q) - stirlingError(p+q)
+		return LnΓ(p) + corr + p - p*log(p+q) + (q-0.5)*math.Log1p(-p/(p+q))
+	}
+	return LnB(a, b)
+}
+
+// lnGammaPrefix returns log(x^a e^-x / Γ(a)), the common factor of the
+// incomplete gamma function and its derivative, without the cancellation of
+// a log x - x - LnΓ(a) when a is large and x is close to a.
+func lnGammaPrefix(a, x float64) float64 {
+	if a < 10 {
+		return a*log(x) - x - LnΓ(a)
+	}
+	// a log(x/a) + a - x = a (log1p(d) - d) with d = (x-a)/a
+	d := (x - a) / a
+	var t float64
+	if abs(d) < 0.5 {
+		t = log1pmx(d)
+	} else {
+		t = math.Log1p(d) - d
+	}
+	return a*t + 0.5*log(a) - 0.91893853320467267 - stirlingError(a)
+}
+
+// log1pmx returns log(1+d) - d for |d| < 1/2 by its Taylor series.
+func log1pmx(d float64) float64 {
+	var s float64
+	p := -d
+	for k := 2.0; ; k++ {
+		p *= -d
+		t := p / k
+		s -= t
+		if abs(t) <= 1e-17*abs(s) {
+			return s
+		}
+	}
+}
+
+// log1mexp returns log(1 - exp(x)) for x <= 0.
+func log1mexp(x float64) float64 {
+	if x > -math.Ln2 {
+		return math.Log(-math.Expm1(x))
+	}
+	return math.Log1p(-math.Exp(x))
+}
+
+// lnNormalCDF returns log Φ(z) for the standard Normal distribution, using the
+// asymptotic expansion of the Mills ratio once Φ(z) underflows.
+func lnNormalCDF(z float64) float64 {
+	switch {
+	case z > 0:
+		return math.Log1p(-0.5 * math.Erfc(z/math.Sqrt2))
+	case z > -37:
+		return math.Log(0.5 * math.Erfc(-z/math.Sqrt2))
+	}
+	// Φ(z) = φ(z)/|z| (1 - 1/z^2 + 3/z^4 - 15/z^6 + ...)
+	w := 1 / (z * z)
+	s := 1 - w*(1-w*(3-w*(15-w*(105-w*945))))
+	return -z*z/2 - 0.91893853320467267 - log(-z) + log(s)
+}
+
+// lnGammaIncReg returns log P(a, x) and log Q(a, x), the regularized lower and
+// upper incomplete gamma functions. Whichever is smaller is computed directly,
+// by the power series for P when x < a+1 and by the continued fraction for Q
+// otherwise, so both keep their relative accuracy far into the tails.
+func lnGammaIncReg(a, x float64) (lnP, lnQ float64) {
+	switch {
+	case x <= 0:
+		return negInf, 0
+	case math.IsInf(x, 1):
+		return 0, negInf
+	}
+	const (
+		eps     = 1e-15
+		tiny    = 1e-300
+		maxIter = 100000000
+	)
+	lnPre := lnGammaPrefix(a, x)
+	if x < a+1 {
+		ap, del, sum := a, 1/a, 1/a
+		for i := 0; math.Abs(del) > math.Abs(sum)*eps; i++ {
+			if i == maxIter {
+				return math.NaN(), math.NaN()
+			}
+			ap++
+			del *= x / ap
+			sum += del
+		}
+		lnP = lnPre + log(sum)
+		return lnP, log1mexp(lnP)
+	}
+	// modified Lentz evaluation of the continued fraction
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for i := 1; ; i++ {
+		if i == maxIter {
+			return math.NaN(), math.NaN()
+		}
+		an := -float64(i) * (float64(i) - a)
+		b += 2
+		if d = an*d + b; math.Abs(d) < tiny {
+			d = tiny
+		}
+		if c = b + an/c; math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		del := d * c
+		h *= del
+		if math.Abs(del-1) < eps {
+			break
+		}
+	}
+	lnQ = lnPre + log(h)
+	return log1mexp(lnQ), lnQ
+}
+
+// lnBetaIncReg returns the logs of the regularized incomplete beta function
+// I_x(α, β) and of its complement 1 - I_x(α, β). y = 1 - x is passed
+// separately so that callers can supply it without cancellation; the
+// smaller of x and y is trusted and the log of the other derived from it.
+func lnBetaIncReg(α, β, x, y float64) (lnI, lnIc float64) {
+	if x < y {
+		return lnBetaIncRegLog(α, β, x, y, log(x), math.Log1p(-x))
+	}
+	return lnBetaIncRegLog(α, β, x, y, math.Log1p(-y), log(y))
+}
+
+// lnBetaIncRegLog is lnBetaIncReg with log x and log y supplied by the caller,
+// for arguments so close to 0 that x or y underflows.
+func lnBetaIncRegLog(α, β, x, y, lnx, lny float64) (lnI, lnIc float64) {
+	switch {
+	case math.IsInf(lnx, -1) || x < 0:
+		return negInf, 0
+	case math.IsInf(lny, -1) || y < 0:
+		return 0, negInf
+	}
+	lnPre := α*lnx + β*lny - lnBeta(α, β)
+	if x < (α+1)/(α+β+2) {
+		lnI = lnPre + log(betaContinuedFraction(α, β, x)/α)
+		return lnI, log1mexp(lnI)
+	}
+	lnIc = lnPre + log(betaContinuedFraction(β, α, y)/β)
+	return log1mexp(lnIc), lnIc
+}
@@ -2,6 +2,8 @@ package stat
 
 import (
 	"math"
+
+	. "github.com/ematvey/go-fn/fn"
 )
 
 var fZero float64 = float64(0.0)
@@ -15,41 +17,93 @@ var log func(float64) float64 = math.Log
 var exp func(float64) float64 = math.Exp
 var sqrt func(float64) float64 = math.Sqrt
 var pow func(float64, float64) float64 = math.Pow
+var abs func(float64) float64 = math.Abs
 
 const π = float64(math.Pi)
 
 func RejectionSample(targetDensity func(float64) float64, sourceDensity func(float64) float64, source func() float64, K float64) float64 {
+	return RejectionSampleWith(DefaultRNG, targetDensity, sourceDensity, source, K)
+}
+
+func RejectionSampleWith(rng RNG, targetDensity func(float64) float64, sourceDensity func(float64) float64, source func() float64, K float64) float64 {
 	x := source()
-	for ; NextUniform() >= targetDensity(x)/(K*sourceDensity(x)); x = source() {
+	for ; rng.Float64() >= targetDensity(x)/(K*sourceDensity(x)); x = source() {
 
 	}
 	return x
 }
 
-func ShuffleInt64(x []int64) {
+// isFinite reports whether x is neither infinite nor NaN.
+func isFinite(x float64) bool {
+	return !math.IsNaN(x) && !math.IsInf(x, 0)
+}
+
+// xlogy returns a*log(x), taking 0*log(0) as 0.
+func xlogy(a, x float64) float64 {
+	if a == 0 {
+		return 0
+	}
+	return a * log(x)
+}
+
+// logSumExp returns log Σ exp(x[i]) without overflow or underflow.
+func logSumExp(x []float64) float64 {
+	max := negInf
+	for _, xi := range x {
+		if xi > max {
+			max = xi
+		}
+	}
+	if math.IsInf(max, 0) {
+		return max
+	}
+	var s float64
+	for _, xi := range x {
+		s += exp(xi - max)
+	}
+	return max + log(s)
+}
+
+// lnMvΓ is the logarithm of the multivariate Gamma function Γ_p(x).
+func lnMvΓ(p int, x float64) float64 {
+	pf := float64(p)
+	l := pf * (pf - 1) / 4 * log(π)
+	for j := 0; j < p; j++ {
+		l += LnΓ(x - float64(j)/2)
+	}
+	return l
+}
+
+func ShuffleInt64(x []int64) { ShuffleInt64With(DefaultRNG, x) }
+
+func ShuffleInt64With(rng RNG, x []int64) {
 	n := int64(len(x))
 	for i := iZero; i < n; i++ {
-		j := i + NextRange(n-i)
+		j := i + rng.Int63n(n-i)
 		t := x[i]
 		x[i] = x[j]
 		x[j] = t
 	}
 }
 
-func ShuffleFloat64(x []float64) {
+func ShuffleFloat64(x []float64) { ShuffleFloat64With(DefaultRNG, x) }
+
+func ShuffleFloat64With(rng RNG, x []float64) {
 	n := int64(len(x))
 	for i := iZero; i < n; i++ {
-		j := i + NextRange(n-i)
+		j := i + rng.Int63n(n-i)
 		t := x[i]
 		x[i] = x[j]
 		x[j] = t
 	}
 }
 
-func Shuffle(x []interface{}) {
+func Shuffle(x []interface{}) { ShuffleWith(DefaultRNG, x) }
+
+func ShuffleWith(rng RNG, x []interface{}) {
 	n := int64(len(x))
 	for i := iZero; i < n; i++ {
-		j := i + NextRange(n-i)
+		j := i + rng.Int63n(n-i)
 		t := x[i]
 		x[i] = x[j]
 		x[j] = t
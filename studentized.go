@@ -0,0 +1,253 @@
+// Studentized range distribution
+
+package stat
+
+import (
+	"math"
+
+	. "github.com/ematvey/go-fn/fn"
+)
+
+// Studentized range distribution: the range of K independent standard
+// Normal variables divided by an independent estimate s of their standard
+// deviation with Nu degrees of freedom, i.e. νs² ~ χ²(ν). Nu may be +Inf,
+// in which case s = 1.
+type StudentizedRangeDist struct {
+	K  int64
+	Nu float64
+}
+
+// NewStudentizedRangeDist returns a studentized range distribution,
+// checking that k >= 2 and ν > 0.
+func NewStudentizedRangeDist(k int64, ν float64) (StudentizedRangeDist, error) {
+	if k < 2 || !(ν > 0) {
+		return StudentizedRangeDist{}, invalidParameter("StudentizedRange k = %d, ν = %v", k, ν)
+	}
+	return StudentizedRangeDist{k, ν}, nil
+}
+
+const studentizedRangeTol = 1e-11
+
+// The distribution functions integrate the range distribution of K standard
+// Normal variables over the distribution of s.
+
+func (d StudentizedRangeDist) PDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	return d.mix(func(s float64) float64 { return s * rangePDF(x*s, d.K) })
+}
+
+func (d StudentizedRangeDist) LogPDF(x float64) float64 { return log(d.PDF(x)) }
+
+func (d StudentizedRangeDist) CDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	return math.Min(1, d.mix(func(s float64) float64 { return rangeCDF(x*s, d.K) }))
+}
+
+func (d StudentizedRangeDist) Survival(x float64) float64 {
+	if x <= 0 {
+		return 1
+	}
+	return math.Min(1, d.mix(func(s float64) float64 { return rangeSurvival(x*s, d.K) }))
+}
+
+func (d StudentizedRangeDist) LogCDF(x float64) float64      { return log(d.CDF(x)) }
+func (d StudentizedRangeDist) LogSurvival(x float64) float64 { return log(d.Survival(x)) }
+
+// mix returns E[g(s)] over the distribution of s, or g(1) for infinite ν.
+func (d StudentizedRangeDist) mix(g func(s float64) float64) float64 {
+	ν := d.Nu
+	// s has a scaled chi density, concentrated within a few w of 1; once
+	// that is below the spacing of floating-point numbers near 1, s = 1
+	w := 1 / sqrt(2*ν)
+	if math.IsInf(ν, 1) || 10*w < epsilon {
+		return g(1)
+	}
+	// In u = (s-1)/w, with a = ν/2 and t = wu, the log density is
+	// log(2w) + a log a - log Γ(a) + (2a-1) log(1+t) - a (1+t)². Stirling's
+	// series cancels a log a - log Γ(a) against a, which would otherwise
+	// lose the digits of the density for large ν.
+	a := ν / 2
+	var corr float64
+	if a >= 10 {
+		corr = stirlingError(a)
+	} else {
+		corr = LnΓ(a) - ((a-0.5)*log(a) - a + 0.91893853320467267)
+	}
+	lnNorm := log(2*w) + 0.5*log(a) - 0.91893853320467267 - corr
+	f := func(u float64) float64 {
+		t := w * u
+		if t <= -1 {
+			return 0
+		}
+		var l float64
+		if abs(t) < 0.5 {
+			l = log1pmx(t)
+		} else {
+			l = math.Log1p(t) - t
+		}
+		return exp(lnNorm+2*a*l-a*t*t-math.Log1p(t)) * g(1+t)
+	}
+	lo := -1 / w
+	cuts := []float64{lo, 0, math.Inf(1)}
+	for _, c := range []float64{-10, -3, 3, 10} {
+		if c > lo {
+			cuts = append(cuts, c)
+		}
+	}
+	return integratePieces(f, cuts, studentizedRangeTol)
+}
+
+// normalInterval returns P(z < Z < z + q) for a standard Normal Z and q > 0.
+func normalInterval(z, q float64) float64 {
+	if z > 0 {
+		return Z_Survival_At(z) - Z_Survival_At(z+q)
+	}
+	return Z_CDF_At(z+q) - Z_CDF_At(z)
+}
+
+// rangeCDF is P(R <= q) for the range R of k standard Normal variables,
+// k ∫ φ(z) [Φ(z+q) - Φ(z)]^(k-1) dz.
+func rangeCDF(q float64, k int64) float64 {
+	if q <= 0 {
+		return 0
+	}
+	m := float64(k - 1)
+	v := integratePieces(func(z float64) float64 {
+		return Z_PDF_At(z) * math.Pow(normalInterval(z, q), m)
+	}, []float64{math.Inf(-1), -q / 2, 0, math.Inf(1)}, studentizedRangeTol)
+	return float64(k) * v
+}
+
+// rangeSurvival is P(R > q). Since k ∫ φ(z) Q(z)^(k-1) dz = 1, with Q the
+// upper tail, it is k ∫ φ(z) [a^(k-1) - b^(k-1)] dz for a = Q(z) and
+// b = a - Q(z+q), and the difference of powers is factored to avoid
+// cancellation.
+func rangeSurvival(q float64, k int64) float64 {
+	if q <= 0 {
+		return 1
+	}
+	v := integratePieces(func(z float64) float64 {
+		a := Z_Survival_At(z)
+		b := normalInterval(z, q)
+		// a^(k-1) - b^(k-1) = (a - b) Σ a^i b^(k-2-i)
+		var s, ai float64 = 0, 1
+		for i := int64(0); i <= k-2; i++ {
+			s += ai * math.Pow(b, float64(k-2-i))
+			ai *= a
+		}
+		return Z_PDF_At(z) * Z_Survival_At(z+q) * s
+	}, []float64{math.Inf(-1), -q / 2, 0, math.Inf(1)}, studentizedRangeTol)
+	return float64(k) * v
+}
+
+// rangePDF is the density of the range of k standard Normal variables,
+// k(k-1) ∫ φ(z) φ(z+q) [Φ(z+q) - Φ(z)]^(k-2) dz.
+func rangePDF(q float64, k int64) float64 {
+	if q <= 0 {
+		return 0
+	}
+	m := float64(k - 2)
+	v := integratePieces(func(z float64) float64 {
+		return Z_PDF_At(z) * Z_PDF_At(z+q) * math.Pow(normalInterval(z, q), m)
+	}, []float64{math.Inf(-1), -q / 2, 0, math.Inf(1)}, studentizedRangeTol)
+	return float64(k*(k-1)) * v
+}
+
+func (d StudentizedRangeDist) Quantile(p float64) float64 {
+	// the Bonferroni bound over the k(k-1)/2 pairs of a two-sided test
+	pairs := float64(d.K*(d.K-1)) / 2
+	x0 := math.Sqrt2 * Z_InvCDF_For(1-(1-p)/(2*pairs))
+	if !(x0 > 0) || math.IsInf(x0, 0) {
+		x0 = 1
+	}
+	return quantile(d, p, x0, true)
+}
+
+func (d StudentizedRangeDist) Rand() float64 { return d.RandWith(DefaultRNG) }
+
+func (d StudentizedRangeDist) RandWith(rng RNG) float64 {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for i := int64(0); i < d.K; i++ {
+		z := rng.NormFloat64()
+		lo, hi = math.Min(lo, z), math.Max(hi, z)
+	}
+	if math.IsInf(d.Nu, 1) {
+		return hi - lo
+	}
+	return (hi - lo) / sqrt(NextGammaWith(rng, d.Nu/2, 0.5)/d.Nu)
+}
+
+// rawMoment returns E[X^m] = E[R^m] E[s^-m], which is finite for ν > m.
+func (d StudentizedRangeDist) rawMoment(m int) float64 {
+	if d.Nu <= float64(m) {
+		return math.Inf(1)
+	}
+	mf := float64(m)
+	// E[R^m] = ∫ m q^(m-1) P(R > q) dq
+	r, _ := integrate(func(q float64) float64 {
+		return mf * math.Pow(q, mf-1) * rangeSurvival(q, d.K)
+	}, 0, math.Inf(1), studentizedRangeTol)
+	if math.IsInf(d.Nu, 1) {
+		return r
+	}
+	ν := d.Nu
+	return r * math.Pow(ν/2, mf/2) * exp(LnΓ((ν-mf)/2)-LnΓ(ν/2))
+}
+
+func (d StudentizedRangeDist) Mean() float64 { return d.rawMoment(1) }
+
+func (d StudentizedRangeDist) Variance() float64 {
+	μ := d.Mean()
+	return d.rawMoment(2) - μ*μ
+}
+
+func (d StudentizedRangeDist) Skewness() float64 {
+	μ, v := d.Mean(), d.Variance()
+	return (d.rawMoment(3) - 3*μ*v - μ*μ*μ) / math.Pow(v, 1.5)
+}
+
+func (d StudentizedRangeDist) ExKurtosis() float64 {
+	μ := d.Mean()
+	m2, m3, m4 := d.rawMoment(2), d.rawMoment(3), d.rawMoment(4)
+	v := m2 - μ*μ
+	return (m4-4*μ*m3+6*μ*μ*m2-3*μ*μ*μ*μ)/(v*v) - 3
+}
+
+// Mode is found by golden-section search.
+func (d StudentizedRangeDist) Mode() float64 {
+	return goldenMax(d.PDF, 0, d.Quantile(0.9), 1e-8)
+}
+
+// Entropy is computed by numerical integration.
+func (d StudentizedRangeDist) Entropy() float64 {
+	v, _ := integrate(func(x float64) float64 {
+		f := d.PDF(x)
+		if f <= 0 {
+			return 0
+		}
+		return -f * log(f)
+	}, 0, math.Inf(1), 1e-8)
+	return v
+}
+
+// Cumulative distribution function of the studentized range distribution
+func StudentizedRange_CDF(k int64, ν float64) func(q float64) float64 {
+	return StudentizedRangeDist{k, ν}.CDF
+}
+
+func StudentizedRange_CDF_At(k int64, ν, q float64) float64 {
+	return StudentizedRangeDist{k, ν}.CDF(q)
+}
+
+// Inverse CDF (Quantile) function of the studentized range distribution
+func StudentizedRange_InvCDF(k int64, ν float64) func(p float64) float64 {
+	return StudentizedRangeDist{k, ν}.Quantile
+}
+
+func StudentizedRange_InvCDF_For(k int64, ν, p float64) float64 {
+	return StudentizedRangeDist{k, ν}.Quantile(p)
+}
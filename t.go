@@ -1,28 +1,164 @@
 package stat
 
 import (
+	"math"
+
 	. "github.com/ematvey/go-fn/fn"
 )
 
-func StudentsT_PDF(ν float64) func(x float64) float64 {
-	normalization := Γ((ν+1)/2) / (sqrt(ν*π) * Γ(ν/2))
-	return func(x float64) float64 {
-		return normalization * pow(1+x*x/ν, -(ν+1)/2)
+// Student's t-distribution with Nu degrees of freedom
+type StudentsTDist struct {
+	Nu float64
+}
+
+// NewStudentsTDist returns a Student's t-distribution, checking that ν > 0.
+func NewStudentsTDist(ν float64) (StudentsTDist, error) {
+	if !(ν > 0) || math.IsInf(ν, 0) {
+		return StudentsTDist{}, invalidParameter("StudentsT ν = %v", ν)
 	}
+	return StudentsTDist{ν}, nil
 }
-func StudentsT_LnPDF(ν float64) func(x float64) float64 {
-	normalization := LnΓ((ν+1)/2) - log(sqrt(ν*π)) - LnΓ(ν/2)
-	return func(x float64) float64 {
-		return normalization + log(1+x*x/ν)*-(ν+1)/2
+
+func (d StudentsTDist) PDF(x float64) float64 { return exp(d.LogPDF(x)) }
+
+func (d StudentsTDist) LogPDF(x float64) float64 {
+	ν := d.Nu
+	return LnΓ((ν+1)/2) - LnΓ(ν/2) - log(ν*π)/2 - math.Log1p(x*x/ν)*(ν+1)/2
+}
+
+func (d StudentsTDist) logPDFs(dst, x []float64) {
+	ν := d.Nu
+	lnNorm := LnΓ((ν+1)/2) - LnΓ(ν/2) - log(ν*π)/2
+	for i, xi := range x {
+		dst[i] = lnNorm - math.Log1p(xi*xi/ν)*(ν+1)/2
 	}
 }
 
-//StudentsT(ν) => N(0, 1)*sqrt(ν/NextGamma(ν/2, 2))
-func NextStudentsT(ν float64) float64 {
-	return NextNormal(0, 1) * sqrt(ν/NextGamma(ν/2, 2))
+func (d StudentsTDist) CDF(x float64) float64      { return exp(d.LogCDF(x)) }
+func (d StudentsTDist) Survival(x float64) float64 { return exp(d.LogCDF(-x)) }
+
+func (d StudentsTDist) LogCDF(x float64) float64 {
+	// P(T < -|x|) = I_z(ν/2, 1/2) / 2 with z = ν / (ν + x^2)
+	ν := d.Nu
+	var lnI float64
+	if abs(x) < 1e100 {
+		lnI, _ = lnBetaIncReg(ν/2, 0.5, ν/(ν+x*x), x*x/(ν+x*x))
+	} else {
+		// x^2 would overflow; z ~ ν/x^2 is taken in logs
+		lnz := log(ν) - 2*log(abs(x))
+		z := exp(lnz)
+		lnI, _ = lnBetaIncRegLog(ν/2, 0.5, z, 1, lnz, -z)
+	}
+	if x < 0 {
+		return lnI - math.Ln2
+	}
+	return math.Log1p(-0.5 * exp(lnI))
 }
-func StudentsT(ν float64) func() float64 {
-	return func() float64 {
-		return NextStudentsT(ν)
+
+func (d StudentsTDist) LogSurvival(x float64) float64 { return d.LogCDF(-x) }
+
+func (d StudentsTDist) Quantile(p float64) float64 {
+	ν := d.Nu
+	switch {
+	case !isProbability(p):
+		return math.NaN()
+	case p == 0.5:
+		return 0
+	case p > 0.5:
+		return -d.Quantile(1 - p)
+	case p <= 0:
+		return math.Inf(-1)
+	case ν == 1:
+		return math.Tan(π * (p - 0.5))
+	case ν == 2:
+		return (2*p - 1) / sqrt(2*p*(1-p))
+	}
+	return quantile(d, p, Z_InvCDF_For(p), false)
+}
+
+func (d StudentsTDist) Rand() float64            { return d.RandWith(DefaultRNG) }
+func (d StudentsTDist) RandWith(rng RNG) float64 { return NextStudentsTWith(rng, d.Nu) }
+
+func (d StudentsTDist) Mean() float64 {
+	if d.Nu <= 1 {
+		return math.NaN()
+	}
+	return 0
+}
+
+func (d StudentsTDist) Variance() float64 {
+	switch {
+	case d.Nu <= 1:
+		return math.NaN()
+	case d.Nu <= 2:
+		return math.Inf(1)
+	}
+	return d.Nu / (d.Nu - 2)
+}
+
+func (d StudentsTDist) Mode() float64 { return 0 }
+
+func (d StudentsTDist) Skewness() float64 {
+	if d.Nu <= 3 {
+		return math.NaN()
 	}
+	return 0
+}
+
+func (d StudentsTDist) ExKurtosis() float64 {
+	switch {
+	case d.Nu <= 2:
+		return math.NaN()
+	case d.Nu <= 4:
+		return math.Inf(1)
+	}
+	return 6 / (d.Nu - 4)
+}
+
+func (d StudentsTDist) Entropy() float64 {
+	ν := d.Nu
+	return (ν+1)/2*(digamma((ν+1)/2)-digamma(ν/2)) + log(sqrt(ν)) + LnB(ν/2, 0.5)
+}
+
+func StudentsT_PDF(ν float64) func(x float64) float64 {
+	return StudentsTDist{ν}.PDF
+}
+func StudentsT_LnPDF(ν float64) func(x float64) float64 {
+	return StudentsTDist{ν}.LogPDF
+}
+
+// StudentsT(ν) => N(0, 1)*sqrt(ν/NextGamma(ν/2, 1/2))
+func NextStudentsT(ν float64) float64 { return NextStudentsTWith(DefaultRNG, ν) }
+func NextStudentsTWith(rng RNG, ν float64) float64 {
+	return rng.NormFloat64() * sqrt(ν/NextGammaWith(rng, ν/2, 0.5))
+}
+func StudentsT(ν float64) func() float64 {
+	return StudentsTDist{ν}.Rand
+}
+
+// Cumulative Distribution Function for Student's t-distribution
+func StudentsT_CDF(ν float64) func(x float64) float64 {
+	return StudentsTDist{ν}.CDF
+}
+
+func StudentsT_CDF_At(ν, x float64) float64 {
+	return StudentsTDist{ν}.CDF(x)
+}
+
+// Upper tail probability P(T > x), computed without cancellation
+func StudentsT_Survival(ν float64) func(x float64) float64 {
+	return StudentsTDist{ν}.Survival
+}
+
+func StudentsT_Survival_At(ν, x float64) float64 {
+	return StudentsTDist{ν}.Survival(x)
+}
+
+// Inverse CDF of Student's t-distribution
+func StudentsT_InvCDF(ν float64) func(p float64) float64 {
+	return StudentsTDist{ν}.Quantile
+}
+
+func StudentsT_InvCDF_For(ν, p float64) float64 {
+	return StudentsTDist{ν}.Quantile(p)
 }
@@ -0,0 +1,45 @@
+package stat
+
+import (
+	"math"
+	"testing"
+)
+
+func TestStudentsTInvCDF(t *testing.T) {
+	for _, c := range []struct{ ν, p, t float64 }{
+		{1, 0.975, 12.706204736174707},
+		{2, 0.975, 4.302652729749464},
+		{5, 0.95, 2.015048373333023},
+		{10, 0.975, 2.228138851986274},
+		{30, 0.975, 2.042272456301238},
+	} {
+		if q := StudentsT_InvCDF_For(c.ν, c.p); math.Abs(q-c.t) > 1e-13*c.t {
+			t.Errorf("StudentsT_InvCDF_For(%v, %v) = %v, expected %v", c.ν, c.p, q, c.t)
+		}
+		if q := StudentsT_InvCDF_For(c.ν, 1-c.p); math.Abs(q+c.t) > 1e-13*c.t {
+			t.Errorf("StudentsT_InvCDF_For(%v, %v) = %v, expected %v", c.ν, 1-c.p, q, -c.t)
+		}
+		if p := StudentsT_CDF_At(c.ν, c.t); math.Abs(p-c.p) > 1e-12 {
+			t.Errorf("StudentsT_CDF_At(%v, %v) = %v, expected %v", c.ν, c.t, p, c.p)
+		}
+	}
+	for _, ν := range []float64{0.3, 1.5, 2.5, 7.25, 40, 1e4} {
+		for _, p := range []float64{1e-10, 0.01, 0.3, 0.49, 0.5, 0.51, 0.9, 0.999} {
+			x := StudentsT_InvCDF_For(ν, p)
+			if c := StudentsT_CDF_At(ν, x); math.Abs(c-p) > 1e-12*math.Min(p, 1-p) {
+				t.Errorf("StudentsT_CDF_At(%v, StudentsT_InvCDF_For(%v, %v)) = %v", ν, ν, p, c)
+			}
+		}
+	}
+	if s := StudentsT_Survival_At(3, 2); math.Abs(s-(1-StudentsT_CDF_At(3, 2))) > 1e-15 {
+		t.Errorf("StudentsT_Survival_At(3, 2) = %v", s)
+	}
+	for _, p := range []float64{-0.5, 1.5, math.NaN()} {
+		if q := (StudentsTDist{5}).Quantile(p); !math.IsNaN(q) {
+			t.Errorf("Quantile(%v) = %v, expected NaN", p, q)
+		}
+	}
+	if q := (StudentsTDist{5}).Quantile(0); !math.IsInf(q, -1) {
+		t.Errorf("Quantile(0) = %v, expected -Inf", q)
+	}
+}
@@ -0,0 +1,66 @@
+package stat
+
+import (
+	"math"
+	"testing"
+)
+
+// Reference values computed in 40-digit arithmetic from closed forms, finite
+// sums or the Mills ratio continued fraction.
+func TestTailAccuracy(t *testing.T) {
+	for _, c := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"Normal CDF(-10)", NormalDist{0, 1}.CDF(-10), 7.6198530241605260659733432515993e-24},
+		{"Normal Survival(20)", NormalDist{0, 1}.Survival(20), 2.7536241186062336950756227808575e-89},
+		{"Normal LogCDF(-40)", NormalDist{0, 1}.LogCDF(-40), -804.60844201375378816660683291861},
+		{"Normal LogSurvival(1000)", NormalDist{0, 1}.LogSurvival(1000), -500007.82669481218430980616754918},
+		{"Normal(3, 2) LogCDF(-17)", NormalDist{3, 2}.LogCDF(-17), -53.231285150512470578347027354132},
+		{"StudentsT(1) Survival(1e10)", StudentsTDist{1}.Survival(1e10), 3.1830988618379068313343507471252e-11},
+		{"StudentsT(2) Survival(1e4)", StudentsTDist{2}.Survival(1e4), 4.9999999250000012499999781250004e-9},
+		{"StudentsT(2) CDF(-1e8)", StudentsTDist{2}.CDF(-1e8), 4.99999999999999925e-17},
+		{"Gamma(1, 1) LogSurvival(700)", GammaDist{1, 1}.LogSurvival(700), -700},
+		{"Gamma(1, 1) CDF(1e-10)", GammaDist{1, 1}.CDF(1e-10), 9.99999999950000000001666666667e-11},
+		{"Xsquare(2) LogSurvival(1400)", XsquareDist{2}.LogSurvival(1400), -700},
+		{"Xsquare(2) CDF(1e-12)", XsquareDist{2}.CDF(1e-12), 4.999999999998750000000000208e-13},
+		{"F(2, 10) Survival(1000)", FDist{2, 10}.Survival(1000), 3.0480333386234551897581243958866e-12},
+		{"F(2, 10) CDF(1e-8)", FDist{2, 10}.CDF(1e-8), 9.99999994000000027999999888e-9},
+		{"Beta(1, 50) Survival(0.9)", BetaDist{1, 50}.Survival(0.9), 1e-50},
+		{"Beta(3, 1) CDF(1e-10)", BetaDist{3, 1}.CDF(1e-10), 1e-30},
+		{"Poisson(1e-3) Survival(5)", PoissonDist{1e-3}.Survival(5), 1.3876989333774597599728787594605e-21},
+		{"Poisson(100) LogCDF(20)", PoissonDist{100}.LogCDF(20), -50.012096777392335008991795934046},
+		{"Binomial(0.01, 100) Survival(50)", BinomialDist{0.01, 100}.Survival(50), 6.1028155129924380233119971436857e-74},
+		{"Binomial(0.9, 100) CDF(20)", BinomialDist{0.9, 100}.CDF(20), 6.6997963812812242938590245057812e-61},
+	} {
+		if math.Abs(c.got-c.want) > 1e-12*math.Abs(c.want) {
+			t.Errorf("%s = %v, expected %v", c.name, c.got, c.want)
+		}
+	}
+}
+
+func TestSurvivalComplementsCDF(t *testing.T) {
+	for _, d := range continuousDists {
+		for _, p := range []float64{0.01, 0.5, 0.99} {
+			x := d.Quantile(p)
+			if s := d.CDF(x) + d.Survival(x); math.Abs(s-1) > 1e-12 {
+				t.Errorf("%#v: CDF + Survival = %v at %v", d, s, x)
+			}
+			if math.Abs(math.Exp(d.LogCDF(x))-d.CDF(x)) > 1e-12 ||
+				math.Abs(math.Exp(d.LogSurvival(x))-d.Survival(x)) > 1e-12 {
+				t.Errorf("%#v: LogCDF or LogSurvival disagrees at %v", d, x)
+			}
+		}
+	}
+	for _, d := range discreteDists {
+		for k := int64(-1); k <= d.Quantile(0.999); k++ {
+			if s := d.CDF(k) + d.Survival(k); math.Abs(s-1) > 1e-12 {
+				t.Errorf("%#v: CDF + Survival = %v at %d", d, s, k)
+			}
+			if math.Abs(math.Exp(d.LogCDF(k))-d.CDF(k)) > 1e-12 ||
+				math.Abs(math.Exp(d.LogSurvival(k))-d.Survival(k)) > 1e-12 {
+				t.Errorf("%#v: LogCDF or LogSurvival disagrees at %d", d, k)
+			}
+		}
+	}
+}
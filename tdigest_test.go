@@ -0,0 +1,137 @@
+package stat
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"testing"
+)
+
+// checkRanks checks the rank among the data of e of the digest's
+// p-quantiles, and its CDF at the quantiles of the data, for each p in ps.
+func checkRanks(t *testing.T, td *TDigest, e EmpiricalDist, ps []float64) {
+	t.Helper()
+	for _, p := range ps {
+		q := td.Quantile(p)
+		// the rank of q among the data, allowing for ties at q
+		lo, hi := e.CDF(math.Nextafter(q, math.Inf(-1))), e.CDF(q)
+		tol := 6 * math.Sqrt(p*(1-p)) / td.Compression()
+		if p < lo-tol || p > hi+tol {
+			t.Errorf("Quantile(%v) = %v has rank %v to %v", p, q, lo, hi)
+		}
+		if c := td.CDF(e.Quantile(p)); abs(c-p) > tol+1/e.n() {
+			t.Errorf("CDF at the %v-quantile = %v", p, c)
+		}
+	}
+}
+
+var digestProbabilities = []float64{0, 1e-4, 0.001, 0.01, 0.1, 0.25, 0.5, 0.75, 0.9, 0.99, 0.999, 0.9999, 1}
+
+func TestTDigest(t *testing.T) {
+	const n = 100000
+	rng := NewRNG(5)
+	x := make([]float64, n)
+	var td TDigest
+	for i := range x {
+		// heavy-tailed, like latencies
+		x[i] = math.Exp(NextNormalWith(rng, 3, 1))
+		td.Add(x[i])
+	}
+	e, _ := NewEmpiricalDist(x)
+	checkRanks(t, &td, e, digestProbabilities)
+	check(t, "Min", td.Quantile(0), e.Quantile(0), 0)
+	check(t, "Max", td.Quantile(1), e.Quantile(1), 0)
+	check(t, "Mean", td.Mean(), e.Mean(), 1e-12)
+	if td.Count() != n || td.Weight() != n {
+		t.Errorf("Count = %d, Weight = %v", td.Count(), td.Weight())
+	}
+	if m := len(td.centroids); m > DefaultCompression {
+		t.Errorf("%d centroids", m)
+	}
+	for _, p := range []float64{0.001, 0.3, 0.97} {
+		check(t, "CDF(Quantile)", td.CDF(td.Quantile(p)), p, 1e-12)
+	}
+
+	// few observations are kept exactly, and ties give jumps in the CDF
+	var s TDigest
+	for _, xi := range []float64{3, 1, 4, 1, 5} {
+		s.Add(xi)
+	}
+	check(t, "small median", s.Quantile(0.5), 3, 1e-15)
+	check(t, "small CDF", s.CDF(1), 0.4, 1e-15)
+	check(t, "small CDF below", s.CDF(0.999), 0, 0)
+	check(t, "small Survival", s.Survival(4.5), 0.2, 1e-15)
+
+	// a weight is a repeat count
+	var a, b TDigest
+	for i := 0; i < 1000; i++ {
+		a.Add(float64(i % 10))
+	}
+	for i := 0; i < 10; i++ {
+		b.AddWeighted(float64(i), 100)
+	}
+	check(t, "weighted Mean", b.Mean(), a.Mean(), 1e-15)
+	check(t, "weighted Weight", b.Weight(), a.Weight(), 0)
+	for _, p := range []float64{0.05, 0.5, 0.93} {
+		if qa, qb := a.Quantile(p), b.Quantile(p); abs(qa-qb) > 0.5 {
+			t.Errorf("weighted Quantile(%v) = %v, repeated %v", p, qb, qa)
+		}
+	}
+
+	var z TDigest
+	if !math.IsNaN(z.Quantile(0.5)) || !math.IsNaN(z.CDF(0)) || !math.IsNaN(td.Quantile(1.5)) {
+		t.Error("expected NaN")
+	}
+	if _, err := NewTDigest(0); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("expected ErrInvalidParameter, got %v", err)
+	}
+}
+
+func TestTDigestMerge(t *testing.T) {
+	const n, parts = 200000, 8
+	x := make([]float64, n)
+	SampleParallel(GammaDist{0.5, 1}, 6, 0, x)
+	e, _ := NewEmpiricalDist(x)
+
+	// fill one digest per goroutine, send each through its encoding and
+	// merge them
+	digests := make([]*TDigest, parts)
+	var wg sync.WaitGroup
+	for p := range digests {
+		digests[p], _ = NewTDigest(200)
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := p * n / parts; i < (p+1)*n/parts; i++ {
+				digests[p].Add(x[i])
+			}
+		}(p)
+	}
+	wg.Wait()
+	merged, _ := NewTDigest(200)
+	for _, d := range digests {
+		data, err := d.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var u TDigest
+		if err := u.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		check(t, "decoded median", u.Quantile(0.5), d.Quantile(0.5), 0)
+		merged.Merge(&u)
+	}
+	if merged.Count() != n {
+		t.Errorf("Count = %d", merged.Count())
+	}
+	checkRanks(t, merged, e, digestProbabilities)
+	check(t, "Min", merged.Min(), e.Quantile(0), 0)
+
+	data, _ := merged.MarshalBinary()
+	var u TDigest
+	for _, bad := range [][]byte{nil, data[:40], append([]byte{2}, data[1:]...)} {
+		if err := u.UnmarshalBinary(bad); !errors.Is(err, ErrInvalidParameter) {
+			t.Errorf("decoding %d bytes: expected ErrInvalidParameter, got %v", len(bad), err)
+		}
+	}
+}
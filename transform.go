@@ -0,0 +1,227 @@
+// Location-scale and truncated distributions
+
+package stat
+
+import (
+	"math"
+)
+
+// LocationScaleDist is the distribution of Loc + Scale*X for X drawn from
+// Base, with Scale > 0.
+type LocationScaleDist struct {
+	Base       ContinuousDistribution
+	Loc, Scale float64
+}
+
+// NewLocationScaleDist returns the distribution of μ + σX for X drawn from
+// base, checking that μ is finite and σ > 0.
+func NewLocationScaleDist(base ContinuousDistribution, μ, σ float64) (LocationScaleDist, error) {
+	if math.IsNaN(μ) || math.IsInf(μ, 0) || !(σ > 0) || math.IsInf(σ, 0) {
+		return LocationScaleDist{}, invalidParameter("location-scale μ = %v, σ = %v", μ, σ)
+	}
+	return LocationScaleDist{base, μ, σ}, nil
+}
+
+func (d LocationScaleDist) std(x float64) float64 { return (x - d.Loc) / d.Scale }
+
+func (d LocationScaleDist) PDF(x float64) float64    { return d.Base.PDF(d.std(x)) / d.Scale }
+func (d LocationScaleDist) LogPDF(x float64) float64 { return d.Base.LogPDF(d.std(x)) - log(d.Scale) }
+func (d LocationScaleDist) CDF(x float64) float64    { return d.Base.CDF(d.std(x)) }
+func (d LocationScaleDist) Survival(x float64) float64 {
+	return d.Base.Survival(d.std(x))
+}
+func (d LocationScaleDist) LogCDF(x float64) float64 { return d.Base.LogCDF(d.std(x)) }
+func (d LocationScaleDist) LogSurvival(x float64) float64 {
+	return d.Base.LogSurvival(d.std(x))
+}
+
+func (d LocationScaleDist) Quantile(p float64) float64 { return d.Loc + d.Scale*d.Base.Quantile(p) }
+func (d LocationScaleDist) Rand() float64              { return d.RandWith(DefaultRNG) }
+func (d LocationScaleDist) RandWith(rng RNG) float64   { return d.Loc + d.Scale*d.Base.RandWith(rng) }
+func (d LocationScaleDist) Mean() float64              { return d.Loc + d.Scale*d.Base.Mean() }
+func (d LocationScaleDist) Variance() float64          { return d.Scale * d.Scale * d.Base.Variance() }
+
+func (d LocationScaleDist) Mode() float64       { return d.Loc + d.Scale*d.Base.Mode() }
+func (d LocationScaleDist) Skewness() float64   { return d.Base.Skewness() }
+func (d LocationScaleDist) ExKurtosis() float64 { return d.Base.ExKurtosis() }
+func (d LocationScaleDist) Entropy() float64    { return d.Base.Entropy() + log(d.Scale) }
+
+// TruncatedDist is Base conditioned on lying in [A, B]; either limit may be
+// infinite. It must be created by NewTruncatedDist, which precomputes the
+// probability of the interval.
+type TruncatedDist struct {
+	Base ContinuousDistribution
+	A, B float64
+	lnZ  float64 // log P(A <= X <= B) under Base
+}
+
+// NewTruncatedDist returns base truncated to [a, b], checking that a < b and
+// that base gives the interval positive probability.
+func NewTruncatedDist(base ContinuousDistribution, a, b float64) (TruncatedDist, error) {
+	if !(a < b) {
+		return TruncatedDist{}, invalidParameter("truncation [%v, %v]", a, b)
+	}
+	lnZ := lnMass(base, a, b)
+	if math.IsInf(lnZ, -1) || math.IsNaN(lnZ) {
+		return TruncatedDist{}, invalidParameter("truncation [%v, %v] has probability zero", a, b)
+	}
+	return TruncatedDist{base, a, b, lnZ}, nil
+}
+
+// lnMass returns log P(a < X <= b) under d. Each end is measured from the
+// tail it lies in, so the difference of two probabilities close to 1 is
+// never taken.
+func lnMass(d tails, a, b float64) float64 {
+	lFb := d.LogCDF(b)
+	if lFb <= -math.Ln2 {
+		return lFb + log1mexp(d.LogCDF(a)-lFb)
+	}
+	lSa := d.LogSurvival(a)
+	if lSa <= -math.Ln2 {
+		return lSa + log1mexp(d.LogSurvival(b)-lSa)
+	}
+	// a below and b above the median: 1 - F(a) - S(b) >= 0
+	return math.Log1p(-exp(d.LogCDF(a)) - exp(d.LogSurvival(b)))
+}
+
+func (d TruncatedDist) PDF(x float64) float64 {
+	if x < d.A || x > d.B {
+		return 0
+	}
+	return exp(d.Base.LogPDF(x) - d.lnZ)
+}
+
+func (d TruncatedDist) LogPDF(x float64) float64 {
+	if x < d.A || x > d.B {
+		return negInf
+	}
+	return d.Base.LogPDF(x) - d.lnZ
+}
+
+func (d TruncatedDist) CDF(x float64) float64      { return exp(d.LogCDF(x)) }
+func (d TruncatedDist) Survival(x float64) float64 { return exp(d.LogSurvival(x)) }
+
+func (d TruncatedDist) LogCDF(x float64) float64 {
+	switch {
+	case x <= d.A:
+		return negInf
+	case x >= d.B:
+		return 0
+	}
+	return lnMass(d.Base, d.A, x) - d.lnZ
+}
+
+func (d TruncatedDist) LogSurvival(x float64) float64 {
+	switch {
+	case x < d.A:
+		return 0
+	case x >= d.B:
+		return negInf
+	}
+	return lnMass(d.Base, x, d.B) - d.lnZ
+}
+
+func (d TruncatedDist) Quantile(p float64) float64 {
+	switch {
+	case p == 0:
+		return d.A
+	case p == 1:
+		return d.B
+	}
+	// start from the base quantile, unless its probability has rounded to 0
+	// or 1 far out in a tail, in which case from the nearer finite limit
+	x0 := math.NaN()
+	if q := d.Base.CDF(d.A) + p*exp(d.lnZ); q > 0 && q < 1 {
+		x0 = d.Base.Quantile(q)
+	}
+	switch {
+	case !(x0 > d.A) && !math.IsInf(d.A, 0):
+		x0 = d.A
+	case !(x0 < d.B) && !math.IsInf(d.B, 0):
+		x0 = d.B
+	case !isFinite(x0):
+		x0 = 0
+	}
+	return quantile(d, p, x0, false)
+}
+
+// RandWith draws from Base and rejects values outside [A, B] when the
+// interval is likely enough, and inverts the CDF otherwise, so that narrow
+// or far-out truncations cost a bounded amount per draw.
+func (d TruncatedDist) RandWith(rng RNG) float64 {
+	if d.lnZ >= -2*math.Ln2 { // P(A <= X <= B) >= 1/4
+		for {
+			if x := d.Base.RandWith(rng); x >= d.A && x <= d.B {
+				return x
+			}
+		}
+	}
+	return d.Quantile(rng.Float64())
+}
+
+func (d TruncatedDist) Rand() float64 { return d.RandWith(DefaultRNG) }
+
+// Mode returns the mode of Base clamped to [A, B], which is exact for
+// unimodal distributions.
+func (d TruncatedDist) Mode() float64 {
+	return math.Max(d.A, math.Min(d.B, d.Base.Mode()))
+}
+
+// The moments are computed by numerical integration. When the interval is
+// unbounded and Base lacks the moment, the base value (Inf or NaN) is
+// returned.
+
+func (d TruncatedDist) Mean() float64 {
+	if m := d.Base.Mean(); !d.bounded() && !isFinite(m) {
+		return m
+	}
+	return d.expect(func(x float64) float64 { return x })
+}
+
+func (d TruncatedDist) Variance() float64 {
+	if v := d.Base.Variance(); !d.bounded() && !isFinite(v) {
+		return v
+	}
+	return d.centralMoment(2)
+}
+
+func (d TruncatedDist) Skewness() float64 {
+	if s := d.Base.Skewness(); !d.bounded() && !isFinite(s) {
+		return s
+	}
+	return d.centralMoment(3) / pow(d.Variance(), 1.5)
+}
+
+func (d TruncatedDist) ExKurtosis() float64 {
+	if k := d.Base.ExKurtosis(); !d.bounded() && !isFinite(k) {
+		return k
+	}
+	v := d.Variance()
+	return d.centralMoment(4)/(v*v) - 3
+}
+
+func (d TruncatedDist) Entropy() float64 {
+	return -d.expect(d.LogPDF)
+}
+
+func (d TruncatedDist) bounded() bool {
+	return !math.IsInf(d.A, 0) && !math.IsInf(d.B, 0)
+}
+
+func (d TruncatedDist) centralMoment(n float64) float64 {
+	μ := d.Mean()
+	return d.expect(func(x float64) float64 { return pow(x-μ, n) })
+}
+
+// expect returns E[g(X)], skipping points where the density vanishes so that
+// g may be infinite there.
+func (d TruncatedDist) expect(g func(x float64) float64) float64 {
+	v, _ := integrate(func(x float64) float64 {
+		f := d.PDF(x)
+		if f == 0 {
+			return 0
+		}
+		return g(x) * f
+	}, d.A, d.B, 1e-12)
+	return v
+}
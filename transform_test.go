@@ -0,0 +1,112 @@
+package stat
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLocationScale(t *testing.T) {
+	d, err := NewLocationScaleDist(NormalDist{0, 1}, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := NormalDist{3, 2}
+	for _, x := range []float64{-4, 1, 3, 6.5} {
+		check(t, "PDF", d.PDF(x), want.PDF(x), 1e-14)
+		check(t, "LogSurvival", d.LogSurvival(x), want.LogSurvival(x), 1e-14)
+	}
+	check(t, "Quantile", d.Quantile(0.9), want.Quantile(0.9), 1e-14)
+	check(t, "Mean", d.Mean(), 3, 1e-15)
+	check(t, "Variance", d.Variance(), 4, 1e-15)
+	check(t, "Entropy", d.Entropy(), want.Entropy(), 1e-14)
+	if _, err := NewLocationScaleDist(NormalDist{0, 1}, 0, -1); err == nil {
+		t.Error("negative scale accepted")
+	}
+}
+
+func TestTruncatedNormal(t *testing.T) {
+	for _, c := range []struct{ a, b float64 }{
+		{-1, 2},
+		{0, math.Inf(1)},
+		{10, math.Inf(1)},
+		{math.Inf(-1), -40},
+		{5, 5.5},
+	} {
+		d, err := NewTruncatedDist(NormalDist{0, 1}, c.a, c.b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// closed forms for the truncated standard Normal, in logs since the
+		// probability of the interval may underflow
+		lnφ := func(x float64) float64 { return -x*x/2 - 0.5*log(2*π) - d.lnZ }
+		var μ, m2 float64
+		if !math.IsInf(c.a, 0) {
+			μ += exp(lnφ(c.a))
+			m2 += c.a * exp(lnφ(c.a))
+		}
+		if !math.IsInf(c.b, 0) {
+			μ -= exp(lnφ(c.b))
+			m2 -= c.b * exp(lnφ(c.b))
+		}
+		v := 1 + m2 - μ*μ
+		check(t, "Mean", d.Mean(), μ, 1e-8)
+		check(t, "Variance", d.Variance(), v, 1e-6)
+
+		for _, p := range []float64{1e-6, 0.3, 0.5, 0.99, 1 - 1e-6} {
+			x := d.Quantile(p)
+			if x < c.a || x > c.b {
+				t.Errorf("[%v, %v]: Quantile(%v) = %v outside the interval", c.a, c.b, p, x)
+			}
+			lp := d.LogCDF(x)
+			if p > 0.5 {
+				lp = log1mexp(d.LogSurvival(x))
+			}
+			check(t, "CDF(Quantile)", lp, log(p), 1e-9)
+		}
+
+		rng := NewRNG(1)
+		var s float64
+		const n = 20000
+		for i := 0; i < n; i++ {
+			x := d.RandWith(rng)
+			if x < c.a || x > c.b {
+				t.Fatalf("[%v, %v]: draw %v outside the interval", c.a, c.b, x)
+			}
+			s += x
+		}
+		if m := s / n; abs(m-μ) > 5*sqrt(v/n) {
+			t.Errorf("[%v, %v]: sample mean %v, want %v", c.a, c.b, m, μ)
+		}
+	}
+}
+
+func TestTruncatedExpMemoryless(t *testing.T) {
+	d, _ := NewTruncatedDist(ExpDist{2}, 1, math.Inf(1))
+	e := ExpDist{2}
+	for _, x := range []float64{1.1, 2, 20} {
+		check(t, "LogSurvival", d.LogSurvival(x), e.LogSurvival(x-1), 1e-12)
+		check(t, "PDF", d.PDF(x), e.PDF(x-1), 1e-12)
+	}
+	check(t, "Entropy", d.Entropy(), e.Entropy(), 1e-9)
+	if d.Mode() != 1 {
+		t.Errorf("Mode = %v, want 1", d.Mode())
+	}
+}
+
+func TestTruncatedErrors(t *testing.T) {
+	if _, err := NewTruncatedDist(NormalDist{0, 1}, 2, 1); err == nil {
+		t.Error("empty interval accepted")
+	}
+	if _, err := NewTruncatedDist(ExpDist{1}, -2, -1); err == nil {
+		t.Error("interval of probability zero accepted")
+	}
+}
+
+// check reports an error unless got agrees with want to within tol, relative
+// to |want| when it exceeds 1.
+func check(t *testing.T, name string, got, want, tol float64) {
+	t.Helper()
+	if !(abs(got-want) <= tol*math.Max(1, abs(want))) {
+		t.Errorf("%s = %v, want %v", name, got, want)
+	}
+}
@@ -0,0 +1,216 @@
+// Tests of means: t-tests and z-tests
+
+package stat
+
+import (
+	"math"
+)
+
+// MeanTestResult is the outcome of a t-test or z-test. Estimate is the mean,
+// or the difference of means, under test, and [Lower, Upper] its confidence
+// interval, one-sided for a one-sided alternative. EffectSize is Cohen's d,
+// the difference from the null value in units of the standard deviation.
+type MeanTestResult struct {
+	TestResult
+	Estimate, Lower, Upper, EffectSize float64
+}
+
+// meanTest completes a test of the estimate est with standard error se
+// against the null value μ0, the statistic being distributed as d.
+func meanTest(d ContinuousDistribution, est, μ0, se, df, sd float64, alt Alternative, conf float64) MeanTestResult {
+	s := (est - μ0) / se
+	r := MeanTestResult{
+		TestResult: TestResult{Statistic: s, PValue: pValue(d, s, alt), DF: df},
+		Estimate:   est,
+		Lower:      math.Inf(-1),
+		Upper:      math.Inf(1),
+		EffectSize: (est - μ0) / sd,
+	}
+	switch alt {
+	case Less:
+		r.Upper = est + d.Quantile(conf)*se
+	case Greater:
+		r.Lower = est - d.Quantile(conf)*se
+	default:
+		q := d.Quantile((1 + conf) / 2)
+		r.Lower, r.Upper = est-q*se, est+q*se
+	}
+	return r
+}
+
+func checkMeanTest(name string, alt Alternative, conf float64) error {
+	if !alt.valid() {
+		return invalidParameter("%s alternative %d", name, alt)
+	}
+	return checkConfidence(name, conf)
+}
+
+// meanVar returns the mean and the unbiased variance of x.
+func meanVar(x []float64) (m, v float64) {
+	m, v = sampleMoments(x)
+	n := float64(len(x))
+	return m, v * n / (n - 1)
+}
+
+// checkSummary verifies that s describes at least min observations, and a
+// positive variance if variance is set.
+func checkSummary(name string, s Summary, min float64, variance bool) error {
+	if !(s.N >= min) || math.IsNaN(s.Mean) || math.IsInf(s.Mean, 0) {
+		return invalidParameter("%s needs at least %v observations, got %v with mean %v", name, min, s.N, s.Mean)
+	}
+	if variance && !(s.Variance > 0) {
+		return invalidParameter("%s sample variance %v", name, s.Variance)
+	}
+	return nil
+}
+
+// TTest is the one-sample t-test that the mean of x is μ0, with a
+// confidence interval for the mean at level conf, e.g. 0.95.
+func TTest(x []float64, μ0 float64, alt Alternative, conf float64) (MeanTestResult, error) {
+	if err := checkSample("TTest", x, 2); err != nil {
+		return MeanTestResult{}, err
+	}
+	return TTestSummary(Summarize(x), μ0, alt, conf)
+}
+
+// TTestSummary is TTest for the sample described by s.
+func TTestSummary(s Summary, μ0 float64, alt Alternative, conf float64) (MeanTestResult, error) {
+	if err := checkMeanTest("TTest", alt, conf); err != nil {
+		return MeanTestResult{}, err
+	}
+	if err := checkSummary("TTest", s, 2, true); err != nil {
+		return MeanTestResult{}, err
+	}
+	sd := sqrt(s.Variance)
+	return meanTest(StudentsTDist{s.N - 1}, s.Mean, μ0, sd/sqrt(s.N), s.N-1, sd, alt, conf), nil
+}
+
+// PairedTTest is the paired t-test that the mean of x[i] - y[i] is μ0.
+func PairedTTest(x, y []float64, μ0 float64, alt Alternative, conf float64) (MeanTestResult, error) {
+	if len(x) != len(y) {
+		return MeanTestResult{}, dimensionMismatch("PairedTTest with %d and %d observations", len(x), len(y))
+	}
+	d := make([]float64, len(x))
+	for i := range d {
+		d[i] = x[i] - y[i]
+	}
+	return TTest(d, μ0, alt, conf)
+}
+
+// TTest2 is Student's two-sample t-test that the difference of the means
+// of x and y is μ0, assuming equal variances. The effect size uses the
+// pooled standard deviation.
+func TTest2(x, y []float64, μ0 float64, alt Alternative, conf float64) (MeanTestResult, error) {
+	if err := checkTwoSamples("TTest2", x, y, 2); err != nil {
+		return MeanTestResult{}, err
+	}
+	return TTest2Summary(Summarize(x), Summarize(y), μ0, alt, conf)
+}
+
+// TTest2Summary is TTest2 for the samples described by x and y.
+func TTest2Summary(x, y Summary, μ0 float64, alt Alternative, conf float64) (MeanTestResult, error) {
+	if err := checkTwoSummaries("TTest2", x, y, alt, conf); err != nil {
+		return MeanTestResult{}, err
+	}
+	df := x.N + y.N - 2
+	sp := sqrt(((x.N-1)*x.Variance + (y.N-1)*y.Variance) / df)
+	se := sp * sqrt(1/x.N+1/y.N)
+	return meanTest(StudentsTDist{df}, x.Mean-y.Mean, μ0, se, df, sp, alt, conf), nil
+}
+
+// WelchTTest is Welch's two-sample t-test that the difference of the means
+// of x and y is μ0, without assuming equal variances. The degrees of
+// freedom come from the Welch-Satterthwaite equation, and the effect size
+// uses the root mean of the two variances.
+func WelchTTest(x, y []float64, μ0 float64, alt Alternative, conf float64) (MeanTestResult, error) {
+	if err := checkTwoSamples("WelchTTest", x, y, 2); err != nil {
+		return MeanTestResult{}, err
+	}
+	return WelchTTestSummary(Summarize(x), Summarize(y), μ0, alt, conf)
+}
+
+// WelchTTestSummary is WelchTTest for the samples described by x and y.
+func WelchTTestSummary(x, y Summary, μ0 float64, alt Alternative, conf float64) (MeanTestResult, error) {
+	if err := checkTwoSummaries("WelchTTest", x, y, alt, conf); err != nil {
+		return MeanTestResult{}, err
+	}
+	ex, ey := x.Variance/x.N, y.Variance/y.N
+	df := (ex + ey) * (ex + ey) / (ex*ex/(x.N-1) + ey*ey/(y.N-1))
+	sd := sqrt((x.Variance + y.Variance) / 2)
+	return meanTest(StudentsTDist{df}, x.Mean-y.Mean, μ0, sqrt(ex+ey), df, sd, alt, conf), nil
+}
+
+func checkTwoSamples(name string, x, y []float64, min int) error {
+	if err := checkSample(name, x, min); err != nil {
+		return err
+	}
+	return checkSample(name, y, min)
+}
+
+// checkTwoSummaries checks the arguments of a two-sample t-test.
+func checkTwoSummaries(name string, x, y Summary, alt Alternative, conf float64) error {
+	if err := checkMeanTest(name, alt, conf); err != nil {
+		return err
+	}
+	if err := checkSummary(name, x, 2, false); err != nil {
+		return err
+	}
+	if err := checkSummary(name, y, 2, false); err != nil {
+		return err
+	}
+	if !(x.Variance >= 0) || !(y.Variance >= 0) || !(x.Variance+y.Variance > 0) {
+		return invalidParameter("%s sample variances %v and %v", name, x.Variance, y.Variance)
+	}
+	return nil
+}
+
+// ZTest is the one-sample z-test that the mean of x is μ0, given the
+// standard deviation σ of the population. DF is zero.
+func ZTest(x []float64, μ0, σ float64, alt Alternative, conf float64) (MeanTestResult, error) {
+	if err := checkSample("ZTest", x, 1); err != nil {
+		return MeanTestResult{}, err
+	}
+	return ZTestSummary(Summarize(x), μ0, σ, alt, conf)
+}
+
+// ZTestSummary is ZTest for the sample described by s.
+func ZTestSummary(s Summary, μ0, σ float64, alt Alternative, conf float64) (MeanTestResult, error) {
+	if err := checkMeanTest("ZTest", alt, conf); err != nil {
+		return MeanTestResult{}, err
+	}
+	if err := checkSummary("ZTest", s, 1, false); err != nil {
+		return MeanTestResult{}, err
+	}
+	if !(σ > 0) || math.IsInf(σ, 0) {
+		return MeanTestResult{}, invalidParameter("ZTest σ = %v", σ)
+	}
+	return meanTest(NormalDist{0, 1}, s.Mean, μ0, σ/sqrt(s.N), 0, σ, alt, conf), nil
+}
+
+// ZTest2 is the two-sample z-test that the difference of the means of x
+// and y is μ0, given the population standard deviations σx and σy.
+func ZTest2(x, y []float64, μ0, σx, σy float64, alt Alternative, conf float64) (MeanTestResult, error) {
+	if err := checkTwoSamples("ZTest2", x, y, 1); err != nil {
+		return MeanTestResult{}, err
+	}
+	return ZTest2Summary(Summarize(x), Summarize(y), μ0, σx, σy, alt, conf)
+}
+
+// ZTest2Summary is ZTest2 for the samples described by x and y.
+func ZTest2Summary(x, y Summary, μ0, σx, σy float64, alt Alternative, conf float64) (MeanTestResult, error) {
+	if err := checkMeanTest("ZTest2", alt, conf); err != nil {
+		return MeanTestResult{}, err
+	}
+	if err := checkSummary("ZTest2", x, 1, false); err != nil {
+		return MeanTestResult{}, err
+	}
+	if err := checkSummary("ZTest2", y, 1, false); err != nil {
+		return MeanTestResult{}, err
+	}
+	if !(σx > 0) || !(σy > 0) || math.IsInf(σx, 0) || math.IsInf(σy, 0) {
+		return MeanTestResult{}, invalidParameter("ZTest2 σx = %v, σy = %v", σx, σy)
+	}
+	se := sqrt(σx*σx/x.N + σy*σy/y.N)
+	sd := sqrt((σx*σx + σy*σy) / 2)
+	return meanTest(NormalDist{0, 1}, x.Mean-y.Mean, μ0, se, 0, sd, alt, conf), nil
+}
@@ -0,0 +1,82 @@
+package stat
+
+import (
+	"math"
+	"testing"
+)
+
+// Student's sleep data, as in R's datasets
+var (
+	sleep1 = []float64{0.7, -1.6, -0.2, -1.2, -0.1, 3.4, 3.7, 0.8, 0.0, 2.0}
+	sleep2 = []float64{1.9, 0.8, 1.1, 0.1, -0.1, 4.4, 5.5, 1.6, 4.6, 3.4}
+)
+
+func TestTTest(t *testing.T) {
+	// t.test(sleep1, sleep2, ...) in R
+	r, err := WelchTTest(sleep1, sleep2, 0, TwoSided, 0.95)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, "Welch t", r.Statistic, -1.860813, 1e-6)
+	check(t, "Welch df", r.DF, 17.77647, 1e-5)
+	check(t, "Welch p", r.PValue, 0.07939414, 1e-7)
+	check(t, "Welch lower", r.Lower, -3.3654832, 1e-7)
+	check(t, "Welch upper", r.Upper, 0.2054832, 1e-7)
+
+	r, _ = TTest2(sleep1, sleep2, 0, TwoSided, 0.95)
+	check(t, "pooled t", r.Statistic, -1.860813, 1e-6)
+	check(t, "pooled df", r.DF, 18, 0)
+	check(t, "pooled p", r.PValue, 0.07918671, 1e-7)
+	check(t, "pooled lower", r.Lower, -3.363874, 1e-6)
+	check(t, "pooled upper", r.Upper, 0.203874, 1e-6)
+
+	r, _ = PairedTTest(sleep1, sleep2, 0, TwoSided, 0.95)
+	check(t, "paired t", r.Statistic, -4.062128, 1e-6)
+	check(t, "paired p", r.PValue, 0.002832890, 1e-8)
+	check(t, "paired lower", r.Lower, -2.4598858, 1e-7)
+	check(t, "paired upper", r.Upper, -0.7001142, 1e-7)
+	check(t, "paired estimate", r.Estimate, -1.58, 1e-14)
+	check(t, "paired d", r.EffectSize, -1.58/1.2299955, 1e-7)
+
+	// one-sided tests split the two-sided p-value and open the interval
+	l, _ := PairedTTest(sleep1, sleep2, 0, Less, 0.95)
+	g, _ := PairedTTest(sleep1, sleep2, 0, Greater, 0.95)
+	check(t, "Less p", l.PValue, r.PValue/2, 1e-14)
+	check(t, "Greater p", g.PValue, 1-r.PValue/2, 1e-14)
+	if !math.IsInf(l.Lower, -1) || l.Upper >= r.Upper || !math.IsInf(g.Upper, 1) || g.Lower <= r.Lower {
+		t.Errorf("one-sided intervals %v, %v against two-sided %v", l, g, r)
+	}
+
+	if _, err := TTest([]float64{1, 1, 1}, 0, TwoSided, 0.95); err == nil {
+		t.Error("constant sample accepted")
+	}
+	if _, err := TTest(sleep1, 0, TwoSided, 1); err == nil {
+		t.Error("confidence level 1 accepted")
+	}
+	if _, err := PairedTTest(sleep1, sleep2[1:], 0, TwoSided, 0.95); err == nil {
+		t.Error("unpaired samples accepted")
+	}
+}
+
+func TestZTest(t *testing.T) {
+	x := []float64{2.1, 2.9, 3.4, 1.8, 2.6, 3.2}
+	r, err := ZTest(x, 2, 0.5, TwoSided, 0.95)
+	if err != nil {
+		t.Fatal(err)
+	}
+	z := (2.6666666666666667 - 2) / (0.5 / math.Sqrt(6))
+	check(t, "z", r.Statistic, z, 1e-14)
+	check(t, "p", r.PValue, 2*Z_Survival_At(z), 1e-14)
+	check(t, "upper", r.Upper, 2.6666666666666667+1.959963984540054*0.5/math.Sqrt(6), 1e-12)
+	check(t, "d", r.EffectSize, (2.6666666666666667-2)/0.5, 1e-14)
+
+	r, _ = ZTest2(x, sleep1, 1, 0.5, 2, Greater, 0.9)
+	se := math.Sqrt(0.25/6 + 4.0/10)
+	check(t, "z2", r.Statistic, (2.6666666666666667-0.75-1)/se, 1e-14)
+	check(t, "z2 p", r.PValue, Z_Survival_At(r.Statistic), 1e-14)
+	check(t, "z2 lower", r.Lower, 2.6666666666666667-0.75-Z_InvCDF_For(0.9)*se, 1e-12)
+
+	if _, err := ZTest(x, 0, 0, TwoSided, 0.95); err == nil {
+		t.Error("σ = 0 accepted")
+	}
+}
@@ -3,25 +3,78 @@
 package stat
 
 import (
+	"math"
 	"math/rand"
 )
 
-func Uniform_PDF() func(x float64) float64 {
-	return func(x float64) float64 {
-		if 0 <= x && x <= 1 {
-			return 1
-		}
+// Uniform distribution on [Min, Max]
+type UniformDist struct {
+	Min, Max float64
+}
+
+// NewUniformDist returns a Uniform distribution, checking that min < max.
+func NewUniformDist(min, max float64) (UniformDist, error) {
+	if !(min < max) || math.IsInf(min, 0) || math.IsInf(max, 0) {
+		return UniformDist{}, invalidParameter("Uniform min = %v, max = %v", min, max)
+	}
+	return UniformDist{min, max}, nil
+}
+
+func (d UniformDist) PDF(x float64) float64 {
+	if d.Min <= x && x <= d.Max {
+		return 1 / (d.Max - d.Min)
+	}
+	return 0
+}
+
+func (d UniformDist) LogPDF(x float64) float64 {
+	if d.Min <= x && x <= d.Max {
+		return -log(d.Max - d.Min)
+	}
+	return negInf
+}
+
+func (d UniformDist) CDF(x float64) float64 {
+	switch {
+	case x <= d.Min:
 		return 0
+	case x >= d.Max:
+		return 1
 	}
+	return (x - d.Min) / (d.Max - d.Min)
 }
 
-func Uniform_LnPDF() func(x float64) float64 {
-	return func(x float64) float64 {
-		if 0 <= x && x <= 1 {
-			return 0
-		}
-		return negInf
+func (d UniformDist) Survival(x float64) float64 {
+	switch {
+	case x <= d.Min:
+		return 1
+	case x >= d.Max:
+		return 0
 	}
+	return (d.Max - x) / (d.Max - d.Min)
+}
+
+func (d UniformDist) LogCDF(x float64) float64      { return log(d.CDF(x)) }
+func (d UniformDist) LogSurvival(x float64) float64 { return log(d.Survival(x)) }
+
+func (d UniformDist) Quantile(p float64) float64 { return d.Min + p*(d.Max-d.Min) }
+func (d UniformDist) Rand() float64              { return d.RandWith(DefaultRNG) }
+func (d UniformDist) RandWith(rng RNG) float64   { return d.Min + rng.Float64()*(d.Max-d.Min) }
+func (d UniformDist) Mean() float64              { return (d.Min + d.Max) / 2 }
+func (d UniformDist) Variance() float64          { return (d.Max - d.Min) * (d.Max - d.Min) / 12 }
+
+// Every point of [Min, Max] is a mode; the midpoint is returned.
+func (d UniformDist) Mode() float64       { return d.Mean() }
+func (d UniformDist) Skewness() float64   { return 0 }
+func (d UniformDist) ExKurtosis() float64 { return -6.0 / 5 }
+func (d UniformDist) Entropy() float64    { return log(d.Max - d.Min) }
+
+func Uniform_PDF() func(x float64) float64 {
+	return UniformDist{0, 1}.PDF
+}
+
+func Uniform_LnPDF() func(x float64) float64 {
+	return UniformDist{0, 1}.LogPDF
 }
 
 var NextUniform func() float64 = rand.Float64
@@ -1,6 +1,8 @@
 package stat
 
 import (
+	"math"
+
 	. "github.com/ematvey/go-fn/fn"
 	m "github.com/skelterjohn/go.matrix"
 )
@@ -36,10 +38,16 @@ func Wishart_LnPDF(n int, V *m.DenseMatrix) func(W *m.DenseMatrix) float64 {
 func NextWishart(n int, V *m.DenseMatrix) *m.DenseMatrix {
 	return Wishart(n, V)()
 }
+func NextWishartWith(rng RNG, n int, V *m.DenseMatrix) *m.DenseMatrix {
+	return WishartWith(rng, n, V)()
+}
 func Wishart(n int, V *m.DenseMatrix) func() *m.DenseMatrix {
+	return WishartWith(DefaultRNG, n, V)
+}
+func WishartWith(rng RNG, n int, V *m.DenseMatrix) func() *m.DenseMatrix {
 	p := V.Rows()
 	zeros := m.Zeros(p, 1)
-	rowGen := MVNormal(zeros, V)
+	rowGen := MVNormalWith(rng, zeros, V)
 	return func() *m.DenseMatrix {
 		x := make([][]float64, n)
 		for i := 0; i < n; i++ {
@@ -84,10 +92,16 @@ func InverseWishart_LnPDF(n int, Ψ *m.DenseMatrix) func(W *m.DenseMatrix) float
 func NextInverseWishart(n int, V *m.DenseMatrix) *m.DenseMatrix {
 	return InverseWishart(n, V)()
 }
+func NextInverseWishartWith(rng RNG, n int, V *m.DenseMatrix) *m.DenseMatrix {
+	return InverseWishartWith(rng, n, V)()
+}
 func InverseWishart(n int, V *m.DenseMatrix) func() *m.DenseMatrix {
+	return InverseWishartWith(DefaultRNG, n, V)
+}
+func InverseWishartWith(rng RNG, n int, V *m.DenseMatrix) func() *m.DenseMatrix {
 	p := V.Rows()
 	zeros := m.Zeros(p, 1)
-	rowGen := MVNormal(zeros, V)
+	rowGen := MVNormalWith(rng, zeros, V)
 	return func() *m.DenseMatrix {
 		x := make([][]float64, n)
 		for i := 0; i < n; i++ {
@@ -99,3 +113,192 @@ func InverseWishart(n int, V *m.DenseMatrix) func() *m.DenseMatrix {
 		return Sinv
 	}
 }
+
+// Wishart distribution with N degrees of freedom and scale matrix V (p x p)
+type WishartDist struct {
+	N int
+	V *m.DenseMatrix
+
+	chol, inv *m.DenseMatrix
+	lnNorm    float64
+}
+
+// NewWishartDist returns a Wishart distribution, checking that V is symmetric
+// positive definite and n >= p.
+func NewWishartDist(n int, V *m.DenseMatrix) (*WishartDist, error) {
+	C, err := checkCovariance("Wishart V", V, V.Rows())
+	if err != nil {
+		return nil, err
+	}
+	p := V.Rows()
+	if n < p {
+		return nil, invalidParameter("Wishart n = %d < p = %d", n, p)
+	}
+	Vinv, err := V.Inverse()
+	if err != nil {
+		return nil, invalidParameter("Wishart V: %v", err)
+	}
+	nf, pf := float64(n), float64(p)
+	return &WishartDist{
+		N:      n,
+		V:      V,
+		chol:   C,
+		inv:    Vinv,
+		lnNorm: -nf*pf/2*log(2) - nf/2*logDetChol(C) - lnMvΓ(p, nf/2),
+	}, nil
+}
+
+func (d *WishartDist) PDF(W *m.DenseMatrix) float64 { return exp(d.LogPDF(W)) }
+
+func (d *WishartDist) LogPDF(W *m.DenseMatrix) float64 {
+	p := d.V.Rows()
+	if W.Rows() != p || W.Cols() != p {
+		return negInf
+	}
+	Wdet := W.Det()
+	if !(Wdet > 0) {
+		return negInf
+	}
+	VinvW, _ := d.inv.TimesDense(W)
+	return d.lnNorm + log(Wdet)*float64(d.N-p-1)/2 - VinvW.Trace()/2
+}
+
+func (d *WishartDist) Rand() *m.DenseMatrix { return d.RandWith(DefaultRNG) }
+
+// Sum of N outer products of Normal(0, V) vectors
+func (d *WishartDist) RandWith(rng RNG) *m.DenseMatrix {
+	p := d.V.Rows()
+	z := m.Zeros(p, d.N)
+	for i := 0; i < p; i++ {
+		for j := 0; j < d.N; j++ {
+			z.Set(i, j, rng.NormFloat64())
+		}
+	}
+	X, _ := d.chol.TimesDense(z)
+	S, _ := X.TimesDense(X.Transpose())
+	return S
+}
+
+func (d *WishartDist) Mean() *m.DenseMatrix {
+	μ := d.V.Copy()
+	μ.Scale(float64(d.N))
+	return μ
+}
+
+// Covariance returns the p^2 x p^2 covariance of vec(W), the columns of W
+// stacked on top of each other: Cov(Wij, Wkl) = n (Vik Vjl + Vil Vjk).
+func (d *WishartDist) Covariance() *m.DenseMatrix {
+	p := d.V.Rows()
+	n := float64(d.N)
+	V := d.V
+	C := m.Zeros(p*p, p*p)
+	for i := 0; i < p; i++ {
+		for j := 0; j < p; j++ {
+			for k := 0; k < p; k++ {
+				for l := 0; l < p; l++ {
+					c := n * (V.Get(i, k)*V.Get(j, l) + V.Get(i, l)*V.Get(j, k))
+					C.Set(j*p+i, l*p+k, c)
+				}
+			}
+		}
+	}
+	return C
+}
+
+// Inverse Wishart distribution with N degrees of freedom and scale matrix Psi (p x p):
+// B ~ InverseWishart(n, Ψ) iff B^-1 ~ Wishart(n, Ψ^-1)
+type InverseWishartDist struct {
+	N   int
+	Psi *m.DenseMatrix
+
+	wishart *WishartDist
+	lnNorm  float64
+}
+
+// NewInverseWishartDist returns an Inverse Wishart distribution, checking that
+// Ψ is symmetric positive definite and n >= p.
+func NewInverseWishartDist(n int, Ψ *m.DenseMatrix) (*InverseWishartDist, error) {
+	C, err := checkCovariance("InverseWishart Ψ", Ψ, Ψ.Rows())
+	if err != nil {
+		return nil, err
+	}
+	Ψinv, err := Ψ.Inverse()
+	if err != nil {
+		return nil, invalidParameter("InverseWishart Ψ: %v", err)
+	}
+	w, err := NewWishartDist(n, Ψinv)
+	if err != nil {
+		return nil, err
+	}
+	nf, pf := float64(n), float64(Ψ.Rows())
+	return &InverseWishartDist{
+		N:       n,
+		Psi:     Ψ,
+		wishart: w,
+		lnNorm:  nf/2*logDetChol(C) - nf*pf/2*log(2) - lnMvΓ(Ψ.Rows(), nf/2),
+	}, nil
+}
+
+func (d *InverseWishartDist) PDF(B *m.DenseMatrix) float64 { return exp(d.LogPDF(B)) }
+
+func (d *InverseWishartDist) LogPDF(B *m.DenseMatrix) float64 {
+	p := d.Psi.Rows()
+	if B.Rows() != p || B.Cols() != p {
+		return negInf
+	}
+	Bdet := B.Det()
+	if !(Bdet > 0) {
+		return negInf
+	}
+	Binv, err := B.Inverse()
+	if err != nil {
+		return negInf
+	}
+	ΨBinv, _ := d.Psi.TimesDense(Binv)
+	return d.lnNorm - log(Bdet)*float64(d.N+p+1)/2 - ΨBinv.Trace()/2
+}
+
+func (d *InverseWishartDist) Rand() *m.DenseMatrix { return d.RandWith(DefaultRNG) }
+
+func (d *InverseWishartDist) RandWith(rng RNG) *m.DenseMatrix {
+	B, _ := d.wishart.RandWith(rng).Inverse()
+	return B
+}
+
+// The mean is only finite for n > p + 1; otherwise every entry is +Inf.
+func (d *InverseWishartDist) Mean() *m.DenseMatrix {
+	p := d.Psi.Rows()
+	μ := d.Psi.Copy()
+	if d.N <= p+1 {
+		fillMatrix(μ, math.Inf(1))
+		return μ
+	}
+	μ.Scale(1 / float64(d.N-p-1))
+	return μ
+}
+
+// Covariance returns the p^2 x p^2 covariance of vec(B), which is only finite
+// for n > p + 3; otherwise every entry is NaN.
+func (d *InverseWishartDist) Covariance() *m.DenseMatrix {
+	p := d.Psi.Rows()
+	C := m.Zeros(p*p, p*p)
+	if d.N <= p+3 {
+		fillMatrix(C, math.NaN())
+		return C
+	}
+	n, pf := float64(d.N), float64(p)
+	den := (n - pf) * (n - pf - 1) * (n - pf - 1) * (n - pf - 3)
+	Ψ := d.Psi
+	for i := 0; i < p; i++ {
+		for j := 0; j < p; j++ {
+			for k := 0; k < p; k++ {
+				for l := 0; l < p; l++ {
+					c := 2*Ψ.Get(i, j)*Ψ.Get(k, l) +
+						(n-pf-1)*(Ψ.Get(i, k)*Ψ.Get(j, l)+Ψ.Get(i, l)*Ψ.Get(k, j))
+					C.Set(j*p+i, l*p+k, c/den)
+				}
+			}
+		}
+	}
+	return C
+}